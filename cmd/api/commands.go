@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"api/internal/auth"
+	"api/internal/storage"
+	"github.com/pkg/errors"
+)
+
+// runCommand runs a maintenance command instead of starting the server.
+func runCommand(name string, args []string) {
+	switch name {
+	case "reencrypt":
+		reencrypt()
+	case "reindex":
+		reindex()
+	case "migrate":
+		migrate(args)
+	default:
+		log.Fatalf("unknown command %q", name)
+	}
+}
+
+// reencrypt moves all encrypted values to ENCRYPTION_ACTIVE_KEY. Run it after
+// adding a new key and making it active; the old key can be removed from
+// ENCRYPTION_KEYS once it has finished.
+func reencrypt() {
+	store, err := storage.New()
+	if err != nil {
+		log.Fatal(errors.Wrap(err, "creating storage service"))
+	}
+
+	n, err := store.Reencrypt(auth.NewContext(context.Background(), auth.System))
+	if err != nil {
+		log.Fatal(errors.Wrapf(err, "re-encrypting after %d rows", n))
+	}
+
+	fmt.Printf("Re-encrypted %d rows.\n", n)
+}
+
+// reindex recomputes the search keys of employees. Run it once after the
+// migration adding them, and whenever the way names are folded changes.
+func reindex() {
+	store, err := storage.New()
+	if err != nil {
+		log.Fatal(errors.Wrap(err, "creating storage service"))
+	}
+
+	n, err := store.Reindex(auth.NewContext(context.Background(), auth.System))
+	if err != nil {
+		log.Fatal(errors.Wrapf(err, "reindexing after %d rows", n))
+	}
+
+	fmt.Printf("Reindexed %d employees.\n", n)
+}
+
+// migrate manages the database schema:
+//
+//	migrate up          applies all pending migrations
+//	migrate down [n]    reverts the last n applied migrations, 1 by default
+//	migrate status      lists the migrations and when they were applied
+func migrate(args []string) {
+	if len(args) == 0 {
+		log.Fatal("usage: migrate up|down [n]|status")
+	}
+
+	store, err := storage.Open()
+	if err != nil {
+		log.Fatal(errors.Wrap(err, "creating storage service"))
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := store.MigrateUp(ctx)
+		for _, m := range applied {
+			fmt.Printf("Applied %s\n", m)
+		}
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if len(applied) == 0 {
+			fmt.Println("The database is up to date.")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Fatalf("invalid number of migrations %q", args[1])
+			}
+		}
+
+		reverted, err := store.MigrateDown(ctx, steps)
+		for _, m := range reverted {
+			fmt.Printf("Reverted %s\n", m)
+		}
+
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "status":
+		statuses, err := store.MigrationStatus(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = "applied " + status.AppliedAt.Format(time.RFC3339)
+			}
+
+			fmt.Printf("%s\t%s\n", status.Migration, applied)
+		}
+	default:
+		log.Fatalf("unknown migrate command %q", args[0])
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,17 +12,31 @@ import (
 	"time"
 
 	"api/internal/server"
+	"api/internal/storage"
 	"github.com/pkg/errors"
 )
 
 func main() {
+	demo := flag.Bool("demo", false, "serve seeded in-memory data instead of connecting to the database")
+	flag.Parse()
+
+	if flag.NArg() > 0 {
+		runCommand(flag.Arg(0), flag.Args()[1:])
+		return
+	}
+
 	port := os.Getenv("PORT")
 
 	if port == "" {
 		log.Fatal("$PORT must be set")
 	}
 
-	srv, err := server.NewService()
+	repo, err := newRepository(*demo)
+	if err != nil {
+		log.Fatal(errors.Wrap(err, "creating storage"))
+	}
+
+	srv, err := server.NewService(repo)
 	if err != nil {
 		log.Fatal(errors.Wrap(err, "creating new service"))
 	}
@@ -39,7 +54,8 @@ func main() {
 		<-sig
 
 		// Shutdown signal with grace period of 30 seconds
-		shutdownCtx, _ := context.WithTimeout(serverCtx, 30*time.Second)
+		shutdownCtx, cancel := context.WithTimeout(serverCtx, 30*time.Second)
+		defer cancel()
 
 		go func() {
 			<-shutdownCtx.Done()
@@ -65,3 +81,20 @@ func main() {
 	// Wait for server context to be stopped
 	<-serverCtx.Done()
 }
+
+// newRepository connects to the database, or in demo mode returns in-memory
+// storage filled with sample data.
+func newRepository(demo bool) (storage.Repository, error) {
+	if !demo {
+		return storage.New()
+	}
+
+	repo, err := storage.NewDemo()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Demo mode: data is kept in memory, log in as %q with password %q.\n", storage.DemoLogin, storage.DemoPassword)
+
+	return repo, nil
+}
@@ -4,54 +4,169 @@ import (
 	"api/internal/models"
 	"context"
 	"github.com/pkg/errors"
+	"time"
 )
 
-func (s *Service) Accommodations(ctx context.Context) ([]models.Accommodation, error) {
-	accommodations, err := s.storage.Accommodations(ctx)
+func (s *Service) Accommodations(ctx context.Context, filter models.AccommodationFilter) (models.Page[models.Accommodation], error) {
+	accommodations, total, err := s.storage.Accommodations(ctx, filter)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to retrieve accommodations")
+		return models.Page[models.Accommodation]{}, errors.Wrap(err, "failed to retrieve accommodations")
 	}
 
-	return accommodations, nil
+	for i := range accommodations {
+		accommodations[i] = maskAccommodation(ctx, accommodations[i])
+	}
+
+	return models.NewPage(accommodations, total, filter.ListFilter), nil
 }
 
 func (s *Service) GetAccommodation(ctx context.Context, id int) (models.Accommodation, error) {
 	accommodation, err := s.storage.GetAccommodation(ctx, id)
 
-	return accommodation, errors.Wrap(err, "failed to retrieve accommodation")
+	return maskAccommodation(ctx, accommodation), errors.Wrap(err, "failed to retrieve accommodation")
+}
+
+func (s *Service) AddAccommodation(ctx context.Context, newAccommodation models.NewAccommodation) (accommodation models.Accommodation, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		accommodation, err = s.addAccommodation(ctx, newAccommodation)
+		return err
+	})
+
+	return accommodation, err
 }
 
-func (s *Service) AddAccommodation(ctx context.Context, newAccommodation models.NewAccommodation) (models.Accommodation, error) {
+func (s *Service) addAccommodation(ctx context.Context, newAccommodation models.NewAccommodation) (models.Accommodation, error) {
+	err := validateAccommodation(models.UpdateAccommodation{
+		ProjectID:            newAccommodation.ProjectID,
+		City:                 newAccommodation.City,
+		AccommodationAddress: newAccommodation.Address,
+		NumberOfPlaces:       newAccommodation.NumberOfPlaces,
+		Contact:              newAccommodation.Contact,
+		Payment:              newAccommodation.Payment,
+	}).err()
+	if err != nil {
+		return models.Accommodation{}, err
+	}
+
 	id, err := s.storage.AddAccommodation(ctx, newAccommodation)
 
 	if err != nil {
-		errors.Wrap(err, "failed to add accommodations")
+		return models.Accommodation{}, errors.Wrap(err, "failed to add accommodations")
 	}
 
-	accommodation, err := s.GetAccommodation(ctx, id)
+	accommodation, err := s.storage.GetAccommodation(ctx, id)
+	if err != nil {
+		return models.Accommodation{}, errors.Wrap(err, "failed to add accommodation")
+	}
 
-	return accommodation, errors.Wrap(err, "failed to add accommodation")
+	err = s.audit(ctx, models.EntityAccommodation, id, models.AuditCreate, nil, accommodation)
+
+	return maskAccommodation(ctx, accommodation), errors.Wrap(err, "failed to add accommodation")
 }
 
-func (s *Service) RemoveAccommodation(ctx context.Context, id int) error {
-	err := s.storage.RemoveAccommodation(ctx, id)
+// RemoveAccommodation archives the accommodation, which can be brought back with RestoreAccommodation.
+func (s *Service) RemoveAccommodation(ctx context.Context, id, version int) error {
+	return s.storage.InTx(ctx, func(ctx context.Context) error {
+		return s.removeAccommodation(ctx, id, version)
+	})
+}
+
+func (s *Service) removeAccommodation(ctx context.Context, id, version int) error {
+	before, err := s.GetAccommodation(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove accommodation")
+	}
+
+	err = s.storage.ArchiveAccommodation(ctx, id, version)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove accommodation")
+	}
+
+	after, err := s.GetAccommodation(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve removed accommodation")
+	}
+
+	err = s.audit(ctx, models.EntityAccommodation, id, models.AuditArchive, before, after)
 
 	return errors.Wrap(err, "failed to remove accommodation")
 }
 
-func (s *Service) UpdateAccommodation(ctx context.Context, id int, updateAccommodation models.UpdateAccommodation) (models.Accommodation, error) {
-	err := s.storage.UpdateAccommodation(ctx, id, updateAccommodation)
+func (s *Service) RestoreAccommodation(ctx context.Context, id, version int) (accommodation models.Accommodation, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		accommodation, err = s.restoreAccommodation(ctx, id, version)
+		return err
+	})
+
+	return accommodation, err
+}
+
+func (s *Service) restoreAccommodation(ctx context.Context, id, version int) (models.Accommodation, error) {
+	before, err := s.GetAccommodation(ctx, id)
 	if err != nil {
-		return models.Accommodation{}, errors.Wrap(err, "failed to update accommodation")
+		return models.Accommodation{}, errors.Wrap(err, "failed to restore accommodation")
+	}
+
+	err = s.storage.RestoreAccommodation(ctx, id, version)
+	if err != nil {
+		return models.Accommodation{}, errors.Wrap(err, "failed to restore accommodation")
 	}
 
 	accommodation, err := s.GetAccommodation(ctx, id)
+	if err != nil {
+		return models.Accommodation{}, errors.Wrap(err, "failed to retrieve restored accommodation")
+	}
+
+	err = s.audit(ctx, models.EntityAccommodation, id, models.AuditRestore, before, accommodation)
+
+	return accommodation, errors.Wrap(err, "failed to restore accommodation")
+}
+
+func (s *Service) UpdateAccommodation(ctx context.Context, id, version int, updateAccommodation models.UpdateAccommodation) (accommodation models.Accommodation, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		accommodation, err = s.updateAccommodation(ctx, id, version, updateAccommodation, nil)
+		return err
+	})
+
+	return accommodation, err
+}
+
+// updateAccommodation saves the given fields of the update, or all of them if
+// fields is nil.
+func (s *Service) updateAccommodation(ctx context.Context, id, version int, updateAccommodation models.UpdateAccommodation, fields models.Fields) (models.Accommodation, error) {
+	before, err := s.storage.GetAccommodation(ctx, id)
+	if err != nil {
+		return models.Accommodation{}, errors.Wrap(err, "failed to update accommodation")
+	}
 
-	return accommodation, errors.Wrap(err, "failed to retrieve updated accommodation")
+	err = validateAccommodation(updateAccommodation).introducedBy(validateAccommodation(before.Update())).err()
+	if err != nil {
+		return models.Accommodation{}, err
+	}
+
+	err = s.storage.PatchAccommodation(ctx, id, version, updateAccommodation, fields)
+	if err != nil {
+		return models.Accommodation{}, errors.Wrap(err, "failed to update accommodation")
+	}
+
+	accommodation, err := s.storage.GetAccommodation(ctx, id)
+	if err != nil {
+		return models.Accommodation{}, errors.Wrap(err, "failed to retrieve updated accommodation")
+	}
+
+	err = s.audit(ctx, models.EntityAccommodation, id, models.AuditUpdate, before, accommodation)
+
+	return maskAccommodation(ctx, accommodation), errors.Wrap(err, "failed to update accommodation")
 }
 
-func (s *Service) GetAccommodationAddresses(ctx context.Context) ([]models.AccommodationAddresses, error) {
-	accommodationAddresses, err := s.storage.GetAccommodationAddresses(ctx)
+// GetAccommodationAddresses lists the accommodations with free places on
+// asOf, or today if it is zero.
+func (s *Service) GetAccommodationAddresses(ctx context.Context, asOf models.Date) ([]models.AccommodationAddresses, error) {
+	if time.Time(asOf).IsZero() {
+		asOf = models.Today()
+	}
+
+	accommodationAddresses, err := s.storage.GetAccommodationAddresses(ctx, asOf)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to retrieve accommodation addresses")
 	}
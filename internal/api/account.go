@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"api/internal/models"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// CreateAccount provisions a login for an existing employee. It returns
+// ErrConflict if the employee already has one: replacing it would leave the
+// tokens issued to the old login valid.
+func (s *Service) CreateAccount(ctx context.Context, employeeID int, newAccount models.NewAccount) (models.Account, error) {
+	if newAccount.Login == "" {
+		return models.Account{}, errors.Wrap(ErrInvalidAccount, "login is required")
+	}
+
+	if !newAccount.Role.Valid() {
+		return models.Account{}, errors.Wrap(ErrInvalidAccount, "unknown role")
+	}
+
+	_, err := s.storage.GetUser(ctx, newAccount.Login)
+	if err == nil {
+		return models.Account{}, ErrLoginTaken
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return models.Account{}, errors.Wrap(err, "failed to check login")
+	}
+
+	hash, err := s.hashPassword(newAccount.Password)
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	projectIDs := newAccount.ProjectIDs
+	if projectIDs == nil {
+		projectIDs = []int{}
+	}
+
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		employee, err := s.storage.GetEmployee(ctx, employeeID)
+		if err != nil {
+			return errors.Wrap(err, "failed to retrieve employee")
+		}
+
+		if employee.Login != nil {
+			return errors.Wrap(ErrConflict, "employee already has an account")
+		}
+
+		err = s.storage.SetEmployeeAccount(ctx, employeeID, newAccount.Login, hash, newAccount.Role)
+		if err != nil {
+			return errors.Wrap(err, "failed to create account")
+		}
+
+		err = s.storage.SetUserProjects(ctx, employeeID, projectIDs)
+
+		return errors.Wrap(err, "failed to set account projects")
+	})
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	return models.Account{
+		EmployeeID: employeeID,
+		Login:      newAccount.Login,
+		Role:       newAccount.Role,
+		ProjectIDs: projectIDs,
+	}, nil
+}
+
+// SetUserProjects binds the user to the given projects. All sessions of the
+// user are ended, so that no token carries the old scope.
+func (s *Service) SetUserProjects(ctx context.Context, login string, projectIDs []int) error {
+	user, err := s.storage.GetUser(ctx, login)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve user")
+	}
+
+	return s.storage.InTx(ctx, func(ctx context.Context) error {
+		err := s.storage.SetUserProjects(ctx, user.EmployeeID, projectIDs)
+		if err != nil {
+			return errors.Wrap(err, "failed to set user projects")
+		}
+
+		err = s.storage.RevokeUserTokens(ctx, login, time.Now())
+
+		return errors.Wrap(err, "failed to revoke user tokens")
+	})
+}
+
+// ChangePassword replaces the password of the given user after verifying the
+// current one. All sessions of the user are ended.
+func (s *Service) ChangePassword(ctx context.Context, login string, changePassword models.ChangePassword) error {
+	user, err := s.storage.GetUser(ctx, login)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve user")
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(changePassword.CurrentPassword))
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	return s.setPassword(ctx, login, changePassword.NewPassword)
+}
+
+// IssuePasswordReset creates a one-time token an administrator hands over to
+// the user, who can then choose a new password with ResetPassword.
+func (s *Service) IssuePasswordReset(ctx context.Context, login string) (models.PasswordResetResponse, error) {
+	_, err := s.storage.GetUser(ctx, login)
+	if err != nil {
+		return models.PasswordResetResponse{}, errors.Wrap(err, "failed to retrieve user")
+	}
+
+	token, err := newRefreshToken()
+	if err != nil {
+		return models.PasswordResetResponse{}, errors.Wrap(err, "failed to generate reset token")
+	}
+
+	expiresAt := time.Now().Add(s.Config.PasswordResetTTL)
+
+	err = s.storage.AddPasswordReset(ctx, models.PasswordReset{
+		Hash:      hashToken(token),
+		Login:     login,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return models.PasswordResetResponse{}, errors.Wrap(err, "failed to store reset token")
+	}
+
+	return models.PasswordResetResponse{
+		Token: token,
+		Exp:   expiresAt.Unix(),
+	}, nil
+}
+
+// ResetPassword sets a new password using a token from IssuePasswordReset.
+// The token can be used only once.
+func (s *Service) ResetPassword(ctx context.Context, resetPassword models.ResetPassword) error {
+	if err := s.checkPasswordPolicy(resetPassword.NewPassword); err != nil {
+		return err
+	}
+
+	return s.storage.InTx(ctx, func(ctx context.Context) error {
+		login, err := s.storage.UsePasswordReset(ctx, hashToken(resetPassword.Token), time.Now())
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return ErrUnauthorized
+			}
+			return errors.Wrap(err, "failed to use reset token")
+		}
+
+		return s.setPassword(ctx, login, resetPassword.NewPassword)
+	})
+}
+
+func (s *Service) setPassword(ctx context.Context, login, password string) error {
+	hash, err := s.hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return s.storage.InTx(ctx, func(ctx context.Context) error {
+		err := s.storage.UpdatePassword(ctx, login, hash)
+		if err != nil {
+			return errors.Wrap(err, "failed to update password")
+		}
+
+		err = s.storage.RevokeUserTokens(ctx, login, time.Now())
+
+		return errors.Wrap(err, "failed to revoke user tokens")
+	})
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"api/internal/models"
+	"github.com/pkg/errors"
+)
+
+// EmployeeAssignments returns the projects the employee has been assigned to,
+// most recent first.
+func (s *Service) EmployeeAssignments(ctx context.Context, id int) ([]models.ProjectAssignment, error) {
+	assignments, err := s.storage.EmployeeAssignments(ctx, id)
+
+	return assignments, errors.Wrap(err, "failed to retrieve assignments")
+}
+
+// TransferEmployee moves the employee to another project on transfer.Date,
+// which defaults to today. The date may neither be in the future nor before
+// the current assignment started.
+func (s *Service) TransferEmployee(ctx context.Context, id, version int, transfer models.Transfer) (employee models.Employee, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		employee, err = s.transferEmployee(ctx, id, version, transfer)
+		return err
+	})
+
+	return employee, err
+}
+
+func (s *Service) transferEmployee(ctx context.Context, id, version int, transfer models.Transfer) (models.Employee, error) {
+	before, err := s.storage.GetEmployee(ctx, id)
+	if err != nil {
+		return models.Employee{}, errors.Wrap(err, "failed to transfer employee")
+	}
+
+	assignments, err := s.storage.EmployeeAssignments(ctx, id)
+	if err != nil {
+		return models.Employee{}, errors.Wrap(err, "failed to transfer employee")
+	}
+
+	from := models.Today()
+	if transfer.Date != nil {
+		from = *transfer.Date
+	}
+
+	if err = checkTransfer(assignments, transfer.ProjectID, from); err != nil {
+		return models.Employee{}, err
+	}
+
+	err = s.storage.TransferEmployee(ctx, id, version, transfer.ProjectID, from)
+	if err != nil {
+		return models.Employee{}, errors.Wrap(err, "failed to transfer employee")
+	}
+
+	employee, err := s.storage.GetEmployee(ctx, id)
+	if err != nil {
+		return models.Employee{}, errors.Wrap(err, "failed to retrieve transferred employee")
+	}
+
+	err = s.audit(ctx, models.EntityEmployee, id, models.AuditTransfer, before, employee)
+
+	return maskEmployee(ctx, employee), errors.Wrap(err, "failed to transfer employee")
+}
+
+// checkTransfer returns ErrInvalidTransfer if the employee cannot be moved to
+// the project on the given day.
+func checkTransfer(assignments []models.ProjectAssignment, projectID int, from models.Date) error {
+	if projectID == 0 {
+		return errors.Wrap(ErrInvalidTransfer, "project is required")
+	}
+
+	if time.Time(from).After(time.Time(models.Today())) {
+		return errors.Wrap(ErrInvalidTransfer, "transfer date is in the future")
+	}
+
+	for _, a := range assignments {
+		if !a.Current() {
+			continue
+		}
+
+		if a.ProjectID == projectID {
+			return errors.Wrap(ErrInvalidTransfer, "employee is already assigned to the project")
+		}
+
+		if time.Time(from).Before(time.Time(a.ValidFrom)) {
+			return errors.Wrap(ErrInvalidTransfer, "transfer date precedes the current assignment")
+		}
+	}
+
+	return nil
+}
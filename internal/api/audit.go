@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"api/internal/auth"
+	"api/internal/models"
+	"github.com/pkg/errors"
+)
+
+// redactedFields are recorded as changed without their values, so the audit
+// log does not become a plain text copy of data encrypted at rest.
+var redactedFields = map[string]bool{
+	"pesel":           true,
+	"passport_number": true,
+	"bank_account":    true,
+	"account_number":  true,
+}
+
+const redacted = "[redacted]"
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+func (s *Service) AuditLog(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	filter.Limit = min(filter.Limit, maxAuditLimit)
+
+	entries, err := s.storage.AuditEntries(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve audit log")
+	}
+
+	return entries, nil
+}
+
+// audit records a change of an entity made by the user of the request.
+// before is nil for created entities and after is nil for deleted ones.
+func (s *Service) audit(ctx context.Context, entityType models.EntityType, entityID int, action models.AuditAction, before, after any) error {
+	changes, err := diff(before, after)
+	if err != nil {
+		return errors.Wrap(err, "failed to compute audit diff")
+	}
+
+	if action == models.AuditUpdate && len(changes) == 0 {
+		return nil
+	}
+
+	raw, err := json.Marshal(changes)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode audit diff")
+	}
+
+	principal, _ := auth.FromContext(ctx)
+
+	err = s.storage.AddAuditEntry(ctx, models.AuditEntry{
+		Username:   principal.Username,
+		OccurredAt: time.Now().UTC(),
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Changes:    raw,
+	})
+
+	return errors.Wrap(err, "failed to write audit entry")
+}
+
+// diff returns the fields that differ between the JSON representations of
+// before and after, keyed by their dotted JSON path.
+func diff(before, after any) (map[string]models.AuditChange, error) {
+	b, err := flatten(before)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := flatten(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]models.AuditChange)
+
+	for key, value := range b {
+		if other, ok := a[key]; !ok || !reflect.DeepEqual(value, other) {
+			changes[key] = models.AuditChange{Before: value, After: a[key]}
+		}
+	}
+
+	for key, value := range a {
+		if _, ok := b[key]; !ok {
+			changes[key] = models.AuditChange{After: value}
+		}
+	}
+
+	for key, change := range changes {
+		if redactedFields[lastSegment(key)] {
+			if change.Before != nil {
+				change.Before = redacted
+			}
+			if change.After != nil {
+				change.After = redacted
+			}
+			changes[key] = change
+		}
+	}
+
+	return changes, nil
+}
+
+func flatten(v any) (map[string]any, error) {
+	flat := make(map[string]any)
+	if v == nil {
+		return flat, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree any
+	if err = json.Unmarshal(raw, &tree); err != nil {
+		return nil, err
+	}
+
+	var walk func(prefix string, node any)
+	walk = func(prefix string, node any) {
+		object, ok := node.(map[string]any)
+		if !ok {
+			flat[prefix] = node
+			return
+		}
+
+		for key, child := range object {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			walk(key, child)
+		}
+	}
+	walk("", tree)
+
+	return flat, nil
+}
+
+func lastSegment(path string) string {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == '.' {
+			return path[i+1:]
+		}
+	}
+
+	return path
+}
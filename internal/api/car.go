@@ -7,13 +7,13 @@ import (
 	"github.com/pkg/errors"
 )
 
-func (s *Service) Cars(ctx context.Context) ([]models.Car, error) {
-	cars, err := s.storage.Cars(ctx)
+func (s *Service) Cars(ctx context.Context, filter models.CarFilter) (models.Page[models.Car], error) {
+	cars, total, err := s.storage.Cars(ctx, filter)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to retrieve cars")
+		return models.Page[models.Car]{}, errors.Wrap(err, "failed to retrieve cars")
 	}
 
-	return cars, nil
+	return models.NewPage(cars, total, filter.ListFilter), nil
 }
 
 func (s *Service) GetCar(ctx context.Context, id int) (models.Car, error) {
@@ -22,7 +22,21 @@ func (s *Service) GetCar(ctx context.Context, id int) (models.Car, error) {
 	return car, errors.Wrap(err, "failed to retrieve car")
 }
 
-func (s *Service) AddCar(ctx context.Context, newCar models.NewCar) (models.Car, error) {
+func (s *Service) AddCar(ctx context.Context, newCar models.NewCar) (car models.Car, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		car, err = s.addCar(ctx, newCar)
+		return err
+	})
+
+	return car, err
+}
+
+func (s *Service) addCar(ctx context.Context, newCar models.NewCar) (models.Car, error) {
+	err := validateCar(models.UpdateCar(newCar)).err()
+	if err != nil {
+		return models.Car{}, err
+	}
+
 	id, err := s.storage.AddCar(ctx, newCar)
 
 	if err != nil {
@@ -30,25 +44,108 @@ func (s *Service) AddCar(ctx context.Context, newCar models.NewCar) (models.Car,
 	}
 
 	car, err := s.GetCar(ctx, id)
+	if err != nil {
+		return models.Car{}, errors.Wrap(err, "failed to add car")
+	}
+
+	err = s.audit(ctx, models.EntityCar, id, models.AuditCreate, nil, car)
 
 	return car, errors.Wrap(err, "failed to add car")
 }
 
-func (s *Service) RemoveCar(ctx context.Context, id int) error {
-	err := s.storage.RemoveCar(ctx, id)
+// RemoveCar archives the car, which can be brought back with RestoreCar.
+func (s *Service) RemoveCar(ctx context.Context, id, version int) error {
+	return s.storage.InTx(ctx, func(ctx context.Context) error {
+		return s.removeCar(ctx, id, version)
+	})
+}
+
+func (s *Service) removeCar(ctx context.Context, id, version int) error {
+	before, err := s.GetCar(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove car")
+	}
+
+	err = s.storage.ArchiveCar(ctx, id, version)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove car")
+	}
+
+	after, err := s.GetCar(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve removed car")
+	}
+
+	err = s.audit(ctx, models.EntityCar, id, models.AuditArchive, before, after)
 
 	return errors.Wrap(err, "failed to remove car")
 }
 
-func (s *Service) UpdateCar(ctx context.Context, id int, updateCar models.UpdateCar) (models.Car, error) {
-	err := s.storage.UpdateCar(ctx, id, updateCar)
+func (s *Service) RestoreCar(ctx context.Context, id, version int) (car models.Car, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		car, err = s.restoreCar(ctx, id, version)
+		return err
+	})
+
+	return car, err
+}
+
+func (s *Service) restoreCar(ctx context.Context, id, version int) (models.Car, error) {
+	before, err := s.GetCar(ctx, id)
+	if err != nil {
+		return models.Car{}, errors.Wrap(err, "failed to restore car")
+	}
+
+	err = s.storage.RestoreCar(ctx, id, version)
+	if err != nil {
+		return models.Car{}, errors.Wrap(err, "failed to restore car")
+	}
+
+	car, err := s.GetCar(ctx, id)
+	if err != nil {
+		return models.Car{}, errors.Wrap(err, "failed to retrieve restored car")
+	}
+
+	err = s.audit(ctx, models.EntityCar, id, models.AuditRestore, before, car)
+
+	return car, errors.Wrap(err, "failed to restore car")
+}
+
+func (s *Service) UpdateCar(ctx context.Context, id, version int, updateCar models.UpdateCar) (car models.Car, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		car, err = s.updateCar(ctx, id, version, updateCar, nil)
+		return err
+	})
+
+	return car, err
+}
+
+// updateCar saves the given fields of the update, or all of them if
+// fields is nil.
+func (s *Service) updateCar(ctx context.Context, id, version int, updateCar models.UpdateCar, fields models.Fields) (models.Car, error) {
+	before, err := s.GetCar(ctx, id)
+	if err != nil {
+		return models.Car{}, errors.Wrap(err, "failed to update car")
+	}
+
+	err = validateCar(updateCar).introducedBy(validateCar(before.Update())).err()
+	if err != nil {
+		return models.Car{}, err
+	}
+
+	err = s.storage.PatchCar(ctx, id, version, updateCar, fields)
 	if err != nil {
 		return models.Car{}, errors.Wrap(err, "failed to update car")
 	}
 
 	car, err := s.GetCar(ctx, id)
+	if err != nil {
+		return models.Car{}, errors.Wrap(err, "failed to retrieve updated car")
+	}
+
+	err = s.audit(ctx, models.EntityCar, id, models.AuditUpdate, before, car)
 
-	return car, errors.Wrap(err, "failed to retrieve updated car")
+	return car, errors.Wrap(err, "failed to update car")
 }
 
 func (s *Service) GetCarNumbers(ctx context.Context) ([]models.CarNumbers, error) {
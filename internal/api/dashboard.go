@@ -2,18 +2,25 @@ package api
 
 import (
 	"context"
+	"time"
 
 	"api/internal/models"
 	"github.com/pkg/errors"
 )
 
-func (s *Service) Dashboard(ctx context.Context) (models.Dashboard, error) {
-	projects, err := s.storage.DashboardEmployeeProjects(ctx)
+// Dashboard summarises the data, counting the employees and the taken places
+// of each project on asOf, or today if it is zero.
+func (s *Service) Dashboard(ctx context.Context, asOf models.Date) (models.Dashboard, error) {
+	if time.Time(asOf).IsZero() {
+		asOf = models.Today()
+	}
+
+	projects, err := s.storage.DashboardEmployeeProjects(ctx, asOf)
 	if err != nil {
 		return models.Dashboard{}, errors.Wrap(err, "failed to retrieve dashboard data")
 	}
 
-	accommodations, err := s.storage.Accommodation(ctx)
+	accommodations, err := s.storage.Accommodation(ctx, asOf)
 	if err != nil {
 		return models.Dashboard{}, errors.Wrap(err, "failed to retrieve dashboard data")
 	}
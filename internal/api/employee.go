@@ -1,51 +1,184 @@
 package api
 
 import (
+	"api/internal/auth"
 	"api/internal/models"
 	"context"
 	"github.com/pkg/errors"
+	"time"
 )
 
-func (s *Service) Employees(ctx context.Context) ([]models.Employee, error) {
-	employees, err := s.storage.Employees(ctx)
+func (s *Service) Employees(ctx context.Context, filter models.EmployeeFilter) (models.Page[models.Employee], error) {
+	// Finding employees by a full identifier would confirm it to users who
+	// only get to see it masked.
+	if (filter.Pesel != "" || filter.PassportNumber != "") && !auth.Can(ctx, auth.PermViewSensitiveData) {
+		return models.Page[models.Employee]{}, errors.Wrap(ErrForbidden, "filtering by PESEL or passport number is not allowed")
+	}
+
+	employees, total, err := s.storage.Employees(ctx, filter)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to retrieve employees")
+		return models.Page[models.Employee]{}, errors.Wrap(err, "failed to retrieve employees")
 	}
 
-	return employees, nil
+	for i := range employees {
+		employees[i] = maskEmployee(ctx, employees[i])
+	}
+
+	return models.NewPage(employees, total, filter.ListFilter), nil
+}
+
+// SearchEmployees returns the employees matching the query, most relevant
+// first. Identifiers are only matched for users who may see them unmasked.
+func (s *Service) SearchEmployees(ctx context.Context, search models.EmployeeSearch) (models.Page[models.Employee], error) {
+	search.Identifiers = auth.Can(ctx, auth.PermViewSensitiveData)
+
+	employees, total, err := s.storage.SearchEmployees(ctx, search)
+	if err != nil {
+		return models.Page[models.Employee]{}, errors.Wrap(err, "failed to search employees")
+	}
+
+	for i := range employees {
+		employees[i] = maskEmployee(ctx, employees[i])
+	}
+
+	return models.NewPage(employees, total, search.ListFilter), nil
 }
 
 func (s *Service) GetEmployee(ctx context.Context, id int) (models.Employee, error) {
 	employee, err := s.storage.GetEmployee(ctx, id)
 
-	return employee, errors.Wrap(err, "failed to retrieve employee")
+	return maskEmployee(ctx, employee), errors.Wrap(err, "failed to retrieve employee")
+}
+
+func (s *Service) AddEmployee(ctx context.Context, newEmployee models.NewEmployee) (employee models.Employee, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		employee, err = s.addEmployee(ctx, newEmployee)
+		return err
+	})
+
+	return employee, err
 }
 
-func (s *Service) AddEmployee(ctx context.Context, newEmployee models.NewEmployee) (models.Employee, error) {
+func (s *Service) addEmployee(ctx context.Context, newEmployee models.NewEmployee) (models.Employee, error) {
+	err := validateEmployee(models.UpdateEmployee(newEmployee)).err()
+	if err != nil {
+		return models.Employee{}, err
+	}
+
 	id, err := s.storage.AddEmployee(ctx, newEmployee)
 
 	if err != nil {
 		return models.Employee{}, errors.Wrap(err, "failed to add employee")
 	}
 
-	employee, err := s.GetEmployee(ctx, id)
+	employee, err := s.storage.GetEmployee(ctx, id)
+	if err != nil {
+		return models.Employee{}, errors.Wrap(err, "failed to add employee")
+	}
+
+	err = s.audit(ctx, models.EntityEmployee, id, models.AuditCreate, nil, employee)
+
+	return maskEmployee(ctx, employee), errors.Wrap(err, "failed to add employee")
+}
+
+func (s *Service) UpdateEmployee(ctx context.Context, id, version int, updateEmployee models.UpdateEmployee) (employee models.Employee, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		employee, err = s.updateEmployee(ctx, id, version, updateEmployee, nil)
+		return err
+	})
 
-	return employee, errors.Wrap(err, "failed to add employee")
+	return employee, err
 }
 
-func (s *Service) UpdateEmployee(ctx context.Context, id int, updateEmployee models.UpdateEmployee) (models.Employee, error) {
-	err := s.storage.UpdateEmployee(ctx, id, updateEmployee)
+// updateEmployee saves the given fields of the update, or all of them if
+// fields is nil.
+func (s *Service) updateEmployee(ctx context.Context, id, version int, updateEmployee models.UpdateEmployee, fields models.Fields) (models.Employee, error) {
+	before, err := s.storage.GetEmployee(ctx, id)
+	if err != nil {
+		return models.Employee{}, errors.Wrap(err, "failed to update employee")
+	}
+
+	err = validateEmployee(updateEmployee).introducedBy(validateEmployee(before.Update())).err()
+	if err != nil {
+		return models.Employee{}, err
+	}
+
+	err = s.storage.PatchEmployee(ctx, id, version, updateEmployee, fields)
 	if err != nil {
 		return models.Employee{}, errors.Wrap(err, "failed to update employee")
 	}
 
-	employee, err := s.GetEmployee(ctx, id)
+	employee, err := s.storage.GetEmployee(ctx, id)
+	if err != nil {
+		return models.Employee{}, errors.Wrap(err, "failed to retrieve updated employee")
+	}
 
-	return employee, errors.Wrap(err, "failed to retrieve updated employee")
+	err = s.audit(ctx, models.EntityEmployee, id, models.AuditUpdate, before, employee)
+
+	return maskEmployee(ctx, employee), errors.Wrap(err, "failed to update employee")
 }
 
-func (s *Service) RemoveEmployee(ctx context.Context, id int) error {
-	err := s.storage.RemoveEmployee(ctx, id)
+// RemoveEmployee archives the employee, which can be brought back with RestoreEmployee.
+func (s *Service) RemoveEmployee(ctx context.Context, id, version int) error {
+	return s.storage.InTx(ctx, func(ctx context.Context) error {
+		return s.removeEmployee(ctx, id, version)
+	})
+}
+
+func (s *Service) removeEmployee(ctx context.Context, id, version int) error {
+	before, err := s.storage.GetEmployee(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove employee")
+	}
+
+	err = s.storage.ArchiveEmployee(ctx, id, version)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove employee")
+	}
+
+	if before.Login != nil {
+		err = s.storage.RevokeUserTokens(ctx, *before.Login, time.Now())
+		if err != nil {
+			return errors.Wrap(err, "failed to remove employee")
+		}
+	}
+
+	after, err := s.storage.GetEmployee(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve removed employee")
+	}
+
+	err = s.audit(ctx, models.EntityEmployee, id, models.AuditArchive, before, after)
+
+	return errors.Wrap(err, "failed to remove employee")
+}
+
+func (s *Service) RestoreEmployee(ctx context.Context, id, version int) (employee models.Employee, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		employee, err = s.restoreEmployee(ctx, id, version)
+		return err
+	})
+
+	return employee, err
+}
+
+func (s *Service) restoreEmployee(ctx context.Context, id, version int) (models.Employee, error) {
+	before, err := s.storage.GetEmployee(ctx, id)
+	if err != nil {
+		return models.Employee{}, errors.Wrap(err, "failed to restore employee")
+	}
+
+	err = s.storage.RestoreEmployee(ctx, id, version)
+	if err != nil {
+		return models.Employee{}, errors.Wrap(err, "failed to restore employee")
+	}
+
+	employee, err := s.storage.GetEmployee(ctx, id)
+	if err != nil {
+		return models.Employee{}, errors.Wrap(err, "failed to retrieve restored employee")
+	}
+
+	err = s.audit(ctx, models.EntityEmployee, id, models.AuditRestore, before, employee)
 
-	return errors.Wrap(err, "failed to remove accommodation")
+	return maskEmployee(ctx, employee), errors.Wrap(err, "failed to restore employee")
 }
@@ -1,7 +1,69 @@
 package api
 
-import "github.com/pkg/errors"
+import (
+	"fmt"
+	"time"
+
+	"api/internal/storage"
+	"github.com/pkg/errors"
+)
+
+// Errors of the storage layer, re-exported for callers of the API.
+var (
+	ErrNotFound   = storage.ErrNotFound
+	ErrConflict   = storage.ErrConflict
+	ErrReferenced = storage.ErrReferenced
+	ErrForbidden  = storage.ErrForbidden
+
+	ErrVersionMismatch = storage.ErrVersionMismatch
+	ErrArchived        = storage.ErrArchived
+	ErrNoVacancy       = storage.ErrNoVacancy
+	ErrOverlap         = storage.ErrOverlap
+	ErrDriverMismatch  = storage.ErrDriverMismatch
+	ErrCarHandover     = storage.ErrCarHandover
+	ErrInvalidSort     = storage.ErrInvalidSort
+)
 
 var (
-	ErrUnauthorized = errors.New("unauthorized")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrWeakPassword    = errors.New("password does not meet the password policy")
+	ErrInvalidAccount  = errors.New("invalid account")
+	ErrInvalidTransfer = errors.New("invalid transfer")
+	ErrInvalidStay     = errors.New("invalid stay")
+	ErrInvalidHandover = errors.New("invalid handover")
+	ErrInvalidPatch    = errors.New("invalid patch")
+	ErrNotNullable     = errors.New("field cannot be null")
+	ErrInvalid         = errors.New("invalid record")
+	ErrLoginTaken      = errors.New("login is already taken")
+	ErrTooManyAttempts = errors.New("too many failed login attempts")
 )
+
+// LockoutError is returned by Login while the client IP is locked out after
+// too many failed attempts. Locked out users get ErrUnauthorized instead, so
+// that it does not tell which logins exist.
+type LockoutError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrTooManyAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LockoutError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
+// NullError is returned when a merge patch sets a field that cannot be empty
+// to null. Field is named as in the patch, with the names of the objects
+// containing it.
+type NullError struct {
+	Field string
+}
+
+func (e *NullError) Error() string {
+	return e.Field + " cannot be null"
+}
+
+func (e *NullError) Unwrap() error {
+	return ErrNotNullable
+}
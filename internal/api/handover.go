@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"api/internal/models"
+	"github.com/pkg/errors"
+)
+
+// CarDrivers returns the periods in which employees had the car, most recent
+// first. The filter narrows them down to who was driving at a given time.
+func (s *Service) CarDrivers(ctx context.Context, id int, filter models.DriverFilter) ([]models.CarDriver, error) {
+	drivers, err := s.storage.CarDrivers(ctx, id, filter)
+
+	return drivers, errors.Wrap(err, "failed to retrieve drivers")
+}
+
+// CarHandovers returns the handover protocols of the car, most recent first.
+func (s *Service) CarHandovers(ctx context.Context, id int) ([]models.CarHandover, error) {
+	handovers, err := s.storage.CarHandovers(ctx, id)
+
+	return handovers, errors.Wrap(err, "failed to retrieve handovers")
+}
+
+// AddHandover records the protocol of the car changing hands at
+// newHandover.HandedAt, which defaults to now, and updates its drivers
+// accordingly.
+func (s *Service) AddHandover(ctx context.Context, carID int, newHandover models.NewHandover) (handover models.CarHandover, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		handover, err = s.addHandover(ctx, carID, newHandover)
+		return err
+	})
+
+	return handover, err
+}
+
+func (s *Service) addHandover(ctx context.Context, carID int, newHandover models.NewHandover) (models.CarHandover, error) {
+	handovers, err := s.storage.CarHandovers(ctx, carID)
+	if err != nil {
+		return models.CarHandover{}, errors.Wrap(err, "failed to add handover")
+	}
+
+	handedAt := time.Now()
+	if newHandover.HandedAt != nil {
+		handedAt = *newHandover.HandedAt
+	}
+
+	handedAt = handedAt.UTC().Truncate(time.Second)
+	newHandover.HandedAt = &handedAt
+
+	if err = checkHandover(handovers, newHandover); err != nil {
+		return models.CarHandover{}, err
+	}
+
+	id, err := s.storage.AddHandover(ctx, carID, newHandover)
+	if err != nil {
+		return models.CarHandover{}, errors.Wrap(err, "failed to add handover")
+	}
+
+	handover, err := s.storage.GetHandover(ctx, id)
+	if err != nil {
+		return models.CarHandover{}, errors.Wrap(err, "failed to retrieve added handover")
+	}
+
+	err = s.audit(ctx, models.EntityHandover, id, models.AuditCreate, nil, handover)
+
+	return handover, errors.Wrap(err, "failed to add handover")
+}
+
+// checkHandover returns ErrInvalidHandover if the protocol is incomplete or
+// does not follow the earlier handovers of the car, given most recent first.
+func checkHandover(handovers []models.CarHandover, h models.NewHandover) error {
+	if h.FromEmployeeID == nil && h.ToEmployeeID == nil {
+		return errors.Wrap(ErrInvalidHandover, "employee handing over or receiving the car is required")
+	}
+
+	if h.FromEmployeeID != nil && h.ToEmployeeID != nil && *h.FromEmployeeID == *h.ToEmployeeID {
+		return errors.Wrap(ErrInvalidHandover, "car cannot be handed over to the same employee")
+	}
+
+	if h.Odometer < 0 {
+		return errors.Wrap(ErrInvalidHandover, "odometer reading cannot be negative")
+	}
+
+	if h.FuelLevel < 0 || h.FuelLevel > 100 {
+		return errors.Wrap(ErrInvalidHandover, "fuel level must be between 0 and 100")
+	}
+
+	if h.HandedAt.After(time.Now()) {
+		return errors.Wrap(ErrInvalidHandover, "handover time is in the future")
+	}
+
+	if len(handovers) > 0 {
+		last := handovers[0]
+
+		if h.HandedAt.Before(last.HandedAt) {
+			return errors.Wrap(ErrInvalidHandover, "handover time precedes the last handover of the car")
+		}
+
+		if h.Odometer < last.Odometer {
+			return errors.Wrap(ErrInvalidHandover, "odometer reading is lower than at the last handover")
+		}
+	}
+
+	return nil
+}
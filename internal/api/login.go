@@ -2,44 +2,114 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
 	"api/internal/models"
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func (s *Service) Login(ctx context.Context, username, password string) (models.LoginResponse, error) {
-	dbPass, err := s.storage.GetUserPassword(ctx, username)
-	if err != nil {
-		return models.LoginResponse{}, fmt.Errorf("failed to retrieve user password: %w", err)
+func (s *Service) Login(ctx context.Context, username, password string, client models.ClientInfo) (models.LoginResponse, error) {
+	now := time.Now()
+
+	if d := s.ipThrottle.lockedFor(client.IP, now); d > 0 {
+		s.recordLoginAttempt(ctx, username, client, false, "ip_locked")
+		return models.LoginResponse{}, &LockoutError{RetryAfter: d}
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(dbPass), []byte(password))
-	if err != nil {
-		log.Println(err)
+	user, err := s.storage.GetUser(ctx, username)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return models.LoginResponse{}, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+	known := err == nil
+
+	// Unknown and locked out users have a password checked all the same and
+	// get the response of a wrong password, so that neither the response nor
+	// its timing tells which logins exist.
+	hash := s.dummyHash
+	if known {
+		hash = []byte(user.Password)
+	}
+	passwordErr := bcrypt.CompareHashAndPassword(hash, []byte(password))
+
+	switch {
+	case !known:
+		s.ipThrottle.fail(client.IP, now)
+		s.recordLoginAttempt(ctx, username, client, false, "unknown_user")
+		return models.LoginResponse{}, ErrUnauthorized
+	case user.LockedUntil != nil && now.Before(*user.LockedUntil):
+		s.ipThrottle.fail(client.IP, now)
+		s.recordLoginAttempt(ctx, username, client, false, "user_locked")
+		return models.LoginResponse{}, ErrUnauthorized
+	case passwordErr != nil:
+		s.ipThrottle.fail(client.IP, now)
+
+		err = s.recordLoginFailure(ctx, user.Login, now)
+		if err != nil {
+			return models.LoginResponse{}, err
+		}
+
+		s.recordLoginAttempt(ctx, username, client, false, "bad_password")
 		return models.LoginResponse{}, ErrUnauthorized
 	}
 
-	t := jwt.NewWithClaims(jwt.SigningMethodHS256, models.JWTCustomClaims{
-		Username: username,
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    "api",
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24)),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
-	})
+	s.ipThrottle.reset(client.IP)
+
+	if user.FailedAttempts > 0 {
+		err = s.storage.SetLoginFailures(ctx, user.Login, 0, nil)
+		if err != nil {
+			return models.LoginResponse{}, fmt.Errorf("failed to reset login failures: %w", err)
+		}
+	}
+
+	s.recordLoginAttempt(ctx, username, client, true, "")
 
-	jwtString, err := t.SignedString([]byte(s.Config.JWTSecret))
+	return s.issueTokens(ctx, user)
+}
+
+// recordLoginFailure counts a failed login of the user and locks them out
+// once the limit is reached. The database increments the count, so that
+// concurrent failures are all counted.
+func (s *Service) recordLoginFailure(ctx context.Context, login string, now time.Time) error {
+	failures, err := s.storage.AddLoginFailure(ctx, login)
+	if err != nil {
+		return fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	if d := lockoutDuration(failures, s.Config.LoginMaxAttempts, s.Config.LoginLockout, s.Config.LoginLockoutMax); d > 0 {
+		err = s.storage.LockUser(ctx, login, now.Add(d))
+		if err != nil {
+			return fmt.Errorf("failed to lock user: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UnlockUser clears the failed login counter and lockout of the given user.
+func (s *Service) UnlockUser(ctx context.Context, login string) error {
+	err := s.storage.SetLoginFailures(ctx, login, 0, nil)
 	if err != nil {
-		return models.LoginResponse{}, fmt.Errorf("failed to sign JWT: %w", err)
+		return fmt.Errorf("failed to unlock user: %w", err)
 	}
 
-	return models.LoginResponse{
-		JWT: jwtString,
-		Exp: time.Now().Add(time.Hour * 24).Unix(),
-	}, nil
+	return nil
+}
+
+// recordLoginAttempt persists the outcome of a login attempt. A failure to
+// write the record is logged but does not fail the login itself.
+func (s *Service) recordLoginAttempt(ctx context.Context, username string, client models.ClientInfo, success bool, reason string) {
+	err := s.storage.AddLoginAttempt(ctx, models.LoginAttempt{
+		Login:       username,
+		IP:          client.IP,
+		UserAgent:   client.UserAgent,
+		Success:     success,
+		Reason:      reason,
+		AttemptedAt: time.Now(),
+	})
+	if err != nil {
+		log.Println(fmt.Errorf("failed to record login attempt: %w", err))
+	}
 }
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"api/internal/auth"
+	"api/internal/models"
+)
+
+// maskVisible is the number of trailing characters left readable in masked
+// values.
+const maskVisible = 4
+
+// mask replaces all but the last few characters of value with asterisks,
+// ignoring the spaces bank account numbers are usually formatted with.
+func mask(value string) string {
+	value = strings.ReplaceAll(value, " ", "")
+
+	n := utf8.RuneCountInString(value)
+	if n <= maskVisible {
+		return strings.Repeat("*", n)
+	}
+
+	runes := []rune(value)
+
+	return strings.Repeat("*", n-maskVisible) + string(runes[n-maskVisible:])
+}
+
+func maskEmployee(ctx context.Context, employee models.Employee) models.Employee {
+	if auth.Can(ctx, auth.PermViewSensitiveData) {
+		return employee
+	}
+
+	employee.Pesel = mask(employee.Pesel)
+	employee.PassportNumber = mask(employee.PassportNumber)
+	employee.BankAccount = mask(employee.BankAccount)
+
+	return employee
+}
+
+func maskAccommodation(ctx context.Context, accommodation models.Accommodation) models.Accommodation {
+	if auth.Can(ctx, auth.PermViewSensitiveData) || accommodation.Payment.AccountNumber == nil {
+		return accommodation
+	}
+
+	accountNumber := mask(*accommodation.Payment.AccountNumber)
+	accommodation.Payment.AccountNumber = &accountNumber
+
+	return accommodation
+}
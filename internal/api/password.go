@@ -0,0 +1,61 @@
+package api
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// checkPasswordPolicy returns ErrWeakPassword annotated with the first rule
+// of the configured policy the password breaks.
+func (s *Service) checkPasswordPolicy(password string) error {
+	policy := s.Config.PasswordPolicy
+
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		return errors.Wrap(ErrWeakPassword, fmt.Sprintf("password must be at least %d characters long", policy.MinLength))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	if policy.RequireMixedCase && !(upper && lower) {
+		return errors.Wrap(ErrWeakPassword, "password must contain both upper and lower case letters")
+	}
+
+	if policy.RequireDigit && !digit {
+		return errors.Wrap(ErrWeakPassword, "password must contain a digit")
+	}
+
+	if policy.RequireSymbol && !symbol {
+		return errors.Wrap(ErrWeakPassword, "password must contain a symbol")
+	}
+
+	return nil
+}
+
+func (s *Service) hashPassword(password string) (string, error) {
+	if err := s.checkPasswordPolicy(password); err != nil {
+		return "", err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), s.Config.BcryptCost)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to hash password")
+	}
+
+	return string(hash), nil
+}
@@ -0,0 +1,331 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"api/internal/models"
+	"github.com/pkg/errors"
+)
+
+// PatchEmployee applies an RFC 7396 merge patch to the body of UpdateEmployee
+// built from the employee's current state, so that only the fields present in
+// the patch change and a null clears a field that may be empty.
+func (s *Service) PatchEmployee(ctx context.Context, id, version int, patch []byte) (models.Employee, error) {
+	return s.patchEmployee(ctx, id, version, func(current models.Employee) (models.UpdateEmployee, error) {
+		return applyPatch(current.Update(), patch)
+	})
+}
+
+// PatchEmployeeResource applies a merge patch to the employee as it is
+// returned by GetEmployee.
+func (s *Service) PatchEmployeeResource(ctx context.Context, id, version int, patch []byte) (models.Employee, error) {
+	return s.patchEmployee(ctx, id, version, func(current models.Employee) (models.UpdateEmployee, error) {
+		patched, err := applyPatch(current, patch)
+		return patched.Update(), err
+	})
+}
+
+// patchEmployee saves only the fields the patch changes, so that the details
+// and assignments it leaves alone are not written.
+func (s *Service) patchEmployee(ctx context.Context, id, version int, apply func(models.Employee) (models.UpdateEmployee, error)) (employee models.Employee, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		current, err := s.storage.GetEmployee(ctx, id)
+		if err != nil {
+			return errors.Wrap(err, "failed to update employee")
+		}
+
+		updateEmployee, err := apply(current)
+		if err != nil {
+			return err
+		}
+
+		employee, err = s.updateEmployee(ctx, id, version, updateEmployee, changedFields(current.Update(), updateEmployee))
+		return err
+	})
+
+	return employee, err
+}
+
+// PatchCar applies a merge patch to the body of UpdateCar, as PatchEmployee.
+func (s *Service) PatchCar(ctx context.Context, id, version int, patch []byte) (models.Car, error) {
+	return s.patchCar(ctx, id, version, func(current models.Car) (models.UpdateCar, error) {
+		return applyPatch(current.Update(), patch)
+	})
+}
+
+// PatchCarResource applies a merge patch to the car as a CarResource.
+func (s *Service) PatchCarResource(ctx context.Context, id, version int, patch []byte) (models.Car, error) {
+	return s.patchCar(ctx, id, version, func(current models.Car) (models.UpdateCar, error) {
+		patched, err := applyPatch(models.NewCarResource(current), patch)
+		return patched.Update(), err
+	})
+}
+
+func (s *Service) patchCar(ctx context.Context, id, version int, apply func(models.Car) (models.UpdateCar, error)) (car models.Car, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		current, err := s.storage.GetCar(ctx, id)
+		if err != nil {
+			return errors.Wrap(err, "failed to update car")
+		}
+
+		updateCar, err := apply(current)
+		if err != nil {
+			return err
+		}
+
+		car, err = s.updateCar(ctx, id, version, updateCar, changedFields(current.Update(), updateCar))
+		return err
+	})
+
+	return car, err
+}
+
+// PatchProject applies a merge patch to the body of UpdateProject, as
+// PatchEmployee.
+func (s *Service) PatchProject(ctx context.Context, id, version int, patch []byte) (models.Project, error) {
+	return s.patchProject(ctx, id, version, func(current models.Project) (models.UpdateProject, error) {
+		return applyPatch(current.Update(), patch)
+	})
+}
+
+// PatchProjectResource applies a merge patch to the project as it is
+// returned by GetProject.
+func (s *Service) PatchProjectResource(ctx context.Context, id, version int, patch []byte) (models.Project, error) {
+	return s.patchProject(ctx, id, version, func(current models.Project) (models.UpdateProject, error) {
+		patched, err := applyPatch(current, patch)
+		return patched.Update(), err
+	})
+}
+
+func (s *Service) patchProject(ctx context.Context, id, version int, apply func(models.Project) (models.UpdateProject, error)) (project models.Project, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		current, err := s.storage.GetProject(ctx, id)
+		if err != nil {
+			return errors.Wrap(err, "failed to update project")
+		}
+
+		updateProject, err := apply(current)
+		if err != nil {
+			return err
+		}
+
+		project, err = s.updateProject(ctx, id, version, updateProject, changedFields(current.Update(), updateProject))
+		return err
+	})
+
+	return project, err
+}
+
+// PatchAccommodation applies a merge patch to the body of
+// UpdateAccommodation, as PatchEmployee.
+func (s *Service) PatchAccommodation(ctx context.Context, id, version int, patch []byte) (models.Accommodation, error) {
+	return s.patchAccommodation(ctx, id, version, func(current models.Accommodation) (models.UpdateAccommodation, error) {
+		return applyPatch(current.Update(), patch)
+	})
+}
+
+// PatchAccommodationResource applies a merge patch to the accommodation as
+// it is returned by GetAccommodation.
+func (s *Service) PatchAccommodationResource(ctx context.Context, id, version int, patch []byte) (models.Accommodation, error) {
+	return s.patchAccommodation(ctx, id, version, func(current models.Accommodation) (models.UpdateAccommodation, error) {
+		patched, err := applyPatch(current, patch)
+		return patched.Update(), err
+	})
+}
+
+func (s *Service) patchAccommodation(ctx context.Context, id, version int, apply func(models.Accommodation) (models.UpdateAccommodation, error)) (accommodation models.Accommodation, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		current, err := s.storage.GetAccommodation(ctx, id)
+		if err != nil {
+			return errors.Wrap(err, "failed to update accommodation")
+		}
+
+		updateAccommodation, err := apply(current)
+		if err != nil {
+			return err
+		}
+
+		accommodation, err = s.updateAccommodation(ctx, id, version, updateAccommodation, changedFields(current.Update(), updateAccommodation))
+		return err
+	})
+
+	return accommodation, err
+}
+
+// applyPatch returns current with the merge patch applied. It returns
+// ErrInvalidPatch if the patch is not a JSON object or sets fields that do
+// not exist or to values of the wrong type, and a NullError if it sets a
+// field that cannot be empty to null.
+func applyPatch[T any](current T, patch []byte) (T, error) {
+	var patched T
+
+	var p any
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return patched, errors.Wrap(ErrInvalidPatch, "patch is not valid JSON")
+	}
+
+	fields, ok := p.(map[string]any)
+	if !ok {
+		return patched, errors.Wrap(ErrInvalidPatch, "patch must be a JSON object")
+	}
+
+	// Merging drops the fields set to null, so that decoding the result
+	// neither rejects unknown ones nor tells clearing a field from leaving
+	// it out.
+	if err := checkPatch(reflect.TypeOf(current), fields, ""); err != nil {
+		return patched, err
+	}
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return patched, errors.Wrap(err, "failed to encode current state")
+	}
+
+	var target any
+	if err = json.Unmarshal(doc, &target); err != nil {
+		return patched, errors.Wrap(err, "failed to decode current state")
+	}
+
+	merged, err := json.Marshal(mergePatch(target, p))
+	if err != nil {
+		return patched, errors.Wrap(err, "failed to encode patched state")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(merged))
+	dec.DisallowUnknownFields()
+
+	if err = dec.Decode(&patched); err != nil {
+		return patched, errors.Wrap(ErrInvalidPatch, err.Error())
+	}
+
+	return patched, nil
+}
+
+// changedFields returns the fields of the update after that differ from
+// before, comparing them as they are sent in JSON.
+func changedFields[T any](before, after T) models.Fields {
+	fields := models.Fields{}
+
+	b, a := reflect.ValueOf(before), reflect.ValueOf(after)
+	for i := range b.NumField() {
+		// The fields of updates are plain values, which always encode.
+		old, _ := json.Marshal(b.Field(i).Interface())
+		updated, _ := json.Marshal(a.Field(i).Interface())
+
+		if !bytes.Equal(old, updated) {
+			fields[b.Type().Field(i).Name] = true
+		}
+	}
+
+	return fields
+}
+
+// checkPatch checks the fields of a merge patch of a value of type typ: it
+// returns ErrInvalidPatch for a field typ does not have and a NullError for
+// null set on a field that cannot be null. Fields are matched by their JSON
+// names as encoding/json does; path prefixes the names in errors.
+func checkPatch(typ reflect.Type, patch map[string]any, path string) error {
+	for name, value := range patch {
+		field, ok := jsonField(typ, name)
+		if !ok {
+			return errors.Wrapf(ErrInvalidPatch, "unknown field %q", path+name)
+		}
+
+		switch value := value.(type) {
+		case nil:
+			if !nullable(field.Type) {
+				return &NullError{Field: path + name}
+			}
+		case map[string]any:
+			t := field.Type
+			for t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+
+			if t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(unmarshalerType) {
+				if err := checkPatch(t, value, path+name+"."); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+var (
+	unmarshalerType  = reflect.TypeFor[json.Unmarshaler]()
+	nullableDateType = reflect.TypeFor[models.NullableDate]()
+)
+
+// nullable reports whether a field of type t may be set to null.
+func nullable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+
+	return t == nullableDateType
+}
+
+// jsonField returns the field of the struct type typ that encoding/json
+// decodes the JSON member name into, preferring an exact match of its name
+// to one differing in case.
+func jsonField(typ reflect.Type, name string) (reflect.StructField, bool) {
+	var (
+		folded reflect.StructField
+		found  bool
+	)
+
+	for _, field := range reflect.VisibleFields(typ) {
+		if !field.IsExported() || field.Anonymous && field.Type.Kind() == reflect.Struct {
+			continue
+		}
+
+		tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch tag {
+		case "-":
+			continue
+		case "":
+			tag = field.Name
+		}
+
+		if tag == name {
+			return field, true
+		}
+
+		if !found && strings.EqualFold(tag, name) {
+			folded, found = field, true
+		}
+	}
+
+	return folded, found
+}
+
+// mergePatch implements the MergePatch function of RFC 7396.
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any)
+	}
+
+	for name, value := range p {
+		if value == nil {
+			delete(t, name)
+			continue
+		}
+
+		t[name] = mergePatch(t[name], value)
+	}
+
+	return t
+}
@@ -2,18 +2,25 @@ package api
 
 import (
 	"context"
+	"time"
 
 	"api/internal/models"
 	"github.com/pkg/errors"
 )
 
-func (s *Service) Projects(ctx context.Context) ([]models.Project, error) {
-	projects, err := s.storage.Projects(ctx)
+// Projects lists the projects with their headcount on filter.AsOf, or today
+// if it is not set.
+func (s *Service) Projects(ctx context.Context, filter models.ProjectFilter) (models.Page[models.Project], error) {
+	if time.Time(filter.AsOf).IsZero() {
+		filter.AsOf = models.Today()
+	}
+
+	projects, total, err := s.storage.Projects(ctx, filter)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to retrieve projects")
+		return models.Page[models.Project]{}, errors.Wrap(err, "failed to retrieve projects")
 	}
 
-	return projects, nil
+	return models.NewPage(projects, total, filter.ListFilter), nil
 }
 
 func (s *Service) GetProject(ctx context.Context, id int) (models.Project, error) {
@@ -22,7 +29,21 @@ func (s *Service) GetProject(ctx context.Context, id int) (models.Project, error
 	return project, errors.Wrap(err, "failed to retrieve project")
 }
 
-func (s *Service) AddProject(ctx context.Context, newProject models.NewProject) (models.Project, error) {
+func (s *Service) AddProject(ctx context.Context, newProject models.NewProject) (project models.Project, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		project, err = s.addProject(ctx, newProject)
+		return err
+	})
+
+	return project, err
+}
+
+func (s *Service) addProject(ctx context.Context, newProject models.NewProject) (models.Project, error) {
+	err := validateProject(models.UpdateProject(newProject)).err()
+	if err != nil {
+		return models.Project{}, err
+	}
+
 	id, err := s.storage.AddProject(ctx, newProject)
 
 	if err != nil {
@@ -30,25 +51,108 @@ func (s *Service) AddProject(ctx context.Context, newProject models.NewProject)
 	}
 
 	project, err := s.GetProject(ctx, id)
+	if err != nil {
+		return models.Project{}, errors.Wrap(err, "failed to add project")
+	}
+
+	err = s.audit(ctx, models.EntityProject, id, models.AuditCreate, nil, project)
 
 	return project, errors.Wrap(err, "failed to add project")
 }
 
-func (s *Service) RemoveProject(ctx context.Context, id int) error {
-	err := s.storage.RemoveProject(ctx, id)
+// RemoveProject archives the project, which can be brought back with RestoreProject.
+func (s *Service) RemoveProject(ctx context.Context, id, version int) error {
+	return s.storage.InTx(ctx, func(ctx context.Context) error {
+		return s.removeProject(ctx, id, version)
+	})
+}
+
+func (s *Service) removeProject(ctx context.Context, id, version int) error {
+	before, err := s.GetProject(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove project")
+	}
+
+	err = s.storage.ArchiveProject(ctx, id, version)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove project")
+	}
+
+	after, err := s.GetProject(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve removed project")
+	}
+
+	err = s.audit(ctx, models.EntityProject, id, models.AuditArchive, before, after)
 
 	return errors.Wrap(err, "failed to remove project")
 }
 
-func (s *Service) UpdateProject(ctx context.Context, id int, updateProject models.UpdateProject) (models.Project, error) {
-	err := s.storage.UpdateProject(ctx, id, updateProject)
+func (s *Service) RestoreProject(ctx context.Context, id, version int) (project models.Project, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		project, err = s.restoreProject(ctx, id, version)
+		return err
+	})
+
+	return project, err
+}
+
+func (s *Service) restoreProject(ctx context.Context, id, version int) (models.Project, error) {
+	before, err := s.GetProject(ctx, id)
+	if err != nil {
+		return models.Project{}, errors.Wrap(err, "failed to restore project")
+	}
+
+	err = s.storage.RestoreProject(ctx, id, version)
+	if err != nil {
+		return models.Project{}, errors.Wrap(err, "failed to restore project")
+	}
+
+	project, err := s.GetProject(ctx, id)
+	if err != nil {
+		return models.Project{}, errors.Wrap(err, "failed to retrieve restored project")
+	}
+
+	err = s.audit(ctx, models.EntityProject, id, models.AuditRestore, before, project)
+
+	return project, errors.Wrap(err, "failed to restore project")
+}
+
+func (s *Service) UpdateProject(ctx context.Context, id, version int, updateProject models.UpdateProject) (project models.Project, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		project, err = s.updateProject(ctx, id, version, updateProject, nil)
+		return err
+	})
+
+	return project, err
+}
+
+// updateProject saves the given fields of the update, or all of them if
+// fields is nil.
+func (s *Service) updateProject(ctx context.Context, id, version int, updateProject models.UpdateProject, fields models.Fields) (models.Project, error) {
+	before, err := s.GetProject(ctx, id)
+	if err != nil {
+		return models.Project{}, errors.Wrap(err, "failed to update project")
+	}
+
+	err = validateProject(updateProject).introducedBy(validateProject(before.Update())).err()
+	if err != nil {
+		return models.Project{}, err
+	}
+
+	err = s.storage.PatchProject(ctx, id, version, updateProject, fields)
 	if err != nil {
 		return models.Project{}, errors.Wrap(err, "failed to update project")
 	}
 
 	project, err := s.GetProject(ctx, id)
+	if err != nil {
+		return models.Project{}, errors.Wrap(err, "failed to retrieve updated project")
+	}
+
+	err = s.audit(ctx, models.EntityProject, id, models.AuditUpdate, before, project)
 
-	return project, errors.Wrap(err, "failed to retrieve updated project")
+	return project, errors.Wrap(err, "failed to update project")
 }
 
 func (s *Service) GetProjectNames(ctx context.Context) ([]models.ProjectNames, error) {
@@ -2,52 +2,95 @@ package api
 
 import (
 	"context"
+	"time"
 
 	"api/internal/models"
 	"api/internal/storage"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type ServiceInterface interface {
-	Login(ctx context.Context, username, password string) (models.LoginResponse, error)
+	Login(ctx context.Context, username, password string, client models.ClientInfo) (models.LoginResponse, error)
+	UnlockUser(ctx context.Context, login string) error
+	RefreshToken(ctx context.Context, refreshToken string) (models.LoginResponse, error)
+	Logout(ctx context.Context, jti string, expiresAt time.Time) error
+	RevokeUserTokens(ctx context.Context, login string) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
 
-	Dashboard(ctx context.Context) (models.Dashboard, error)
+	CreateAccount(ctx context.Context, employeeID int, newAccount models.NewAccount) (models.Account, error)
+	ChangePassword(ctx context.Context, login string, changePassword models.ChangePassword) error
+	IssuePasswordReset(ctx context.Context, login string) (models.PasswordResetResponse, error)
+	ResetPassword(ctx context.Context, resetPassword models.ResetPassword) error
+	SetUserProjects(ctx context.Context, login string, projectIDs []int) error
 
-	Cars(ctx context.Context) ([]models.Car, error)
+	Dashboard(ctx context.Context, asOf models.Date) (models.Dashboard, error)
+
+	AuditLog(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+
+	Cars(ctx context.Context, filter models.CarFilter) (models.Page[models.Car], error)
 	GetCar(ctx context.Context, id int) (models.Car, error)
 	AddCar(ctx context.Context, newCar models.NewCar) (models.Car, error)
-	UpdateCar(ctx context.Context, id int, updateCar models.UpdateCar) (models.Car, error)
-	RemoveCar(ctx context.Context, id int) error
+	UpdateCar(ctx context.Context, id, version int, updateCar models.UpdateCar) (models.Car, error)
+	PatchCar(ctx context.Context, id, version int, patch []byte) (models.Car, error)
+	PatchCarResource(ctx context.Context, id, version int, patch []byte) (models.Car, error)
+	RemoveCar(ctx context.Context, id, version int) error
+	RestoreCar(ctx context.Context, id, version int) (models.Car, error)
 	GetCarNumbers(ctx context.Context) ([]models.CarNumbers, error)
+	CarDrivers(ctx context.Context, id int, filter models.DriverFilter) ([]models.CarDriver, error)
+	CarHandovers(ctx context.Context, id int) ([]models.CarHandover, error)
+	AddHandover(ctx context.Context, carID int, newHandover models.NewHandover) (models.CarHandover, error)
 
-	Projects(ctx context.Context) ([]models.Project, error)
+	Projects(ctx context.Context, filter models.ProjectFilter) (models.Page[models.Project], error)
 	GetProject(ctx context.Context, id int) (models.Project, error)
 	AddProject(ctx context.Context, newProject models.NewProject) (models.Project, error)
-	UpdateProject(ctx context.Context, id int, updateProject models.UpdateProject) (models.Project, error)
-	RemoveProject(ctx context.Context, id int) error
+	UpdateProject(ctx context.Context, id, version int, updateProject models.UpdateProject) (models.Project, error)
+	PatchProject(ctx context.Context, id, version int, patch []byte) (models.Project, error)
+	PatchProjectResource(ctx context.Context, id, version int, patch []byte) (models.Project, error)
+	RemoveProject(ctx context.Context, id, version int) error
+	RestoreProject(ctx context.Context, id, version int) (models.Project, error)
 	GetProjectNames(ctx context.Context) ([]models.ProjectNames, error)
 
-	Accommodations(ctx context.Context) ([]models.Accommodation, error)
+	Accommodations(ctx context.Context, filter models.AccommodationFilter) (models.Page[models.Accommodation], error)
 	GetAccommodation(ctx context.Context, id int) (models.Accommodation, error)
 	AddAccommodation(ctx context.Context, newAccommodation models.NewAccommodation) (models.Accommodation, error)
-	UpdateAccommodation(ctx context.Context, id int, updateAccommodation models.UpdateAccommodation) (models.Accommodation, error)
-	RemoveAccommodation(ctx context.Context, id int) error
-	GetAccommodationAddresses(ctx context.Context) ([]models.AccommodationAddresses, error)
+	UpdateAccommodation(ctx context.Context, id, version int, updateAccommodation models.UpdateAccommodation) (models.Accommodation, error)
+	PatchAccommodation(ctx context.Context, id, version int, patch []byte) (models.Accommodation, error)
+	PatchAccommodationResource(ctx context.Context, id, version int, patch []byte) (models.Accommodation, error)
+	RemoveAccommodation(ctx context.Context, id, version int) error
+	RestoreAccommodation(ctx context.Context, id, version int) (models.Accommodation, error)
+	GetAccommodationAddresses(ctx context.Context, asOf models.Date) ([]models.AccommodationAddresses, error)
+	AccommodationStays(ctx context.Context, id int) ([]models.Stay, error)
+	AddStay(ctx context.Context, accommodationID int, newStay models.NewStay) (models.Stay, error)
+	CheckOutStay(ctx context.Context, id int, checkOut models.CheckOut) (models.Stay, error)
+	CancelStay(ctx context.Context, id int) error
 
-	Employees(ctx context.Context) ([]models.Employee, error)
+	Employees(ctx context.Context, filter models.EmployeeFilter) (models.Page[models.Employee], error)
+	SearchEmployees(ctx context.Context, search models.EmployeeSearch) (models.Page[models.Employee], error)
 	GetEmployee(ctx context.Context, id int) (models.Employee, error)
 	AddEmployee(ctx context.Context, newEmployee models.NewEmployee) (models.Employee, error)
-	UpdateEmployee(ctx context.Context, id int, updateEmployee models.UpdateEmployee) (models.Employee, error)
-	RemoveEmployee(ctx context.Context, id int) error
+	UpdateEmployee(ctx context.Context, id, version int, updateEmployee models.UpdateEmployee) (models.Employee, error)
+	PatchEmployee(ctx context.Context, id, version int, patch []byte) (models.Employee, error)
+	PatchEmployeeResource(ctx context.Context, id, version int, patch []byte) (models.Employee, error)
+	RemoveEmployee(ctx context.Context, id, version int) error
+	RestoreEmployee(ctx context.Context, id, version int) (models.Employee, error)
+	EmployeeAssignments(ctx context.Context, id int) ([]models.ProjectAssignment, error)
+	TransferEmployee(ctx context.Context, id, version int, transfer models.Transfer) (models.Employee, error)
+	EmployeeStays(ctx context.Context, id int) ([]models.Stay, error)
 }
 
 type Service struct {
 	Config  Config
-	storage storage.Service
+	storage storage.Repository
+
+	ipThrottle *loginThrottle
+	// dummyHash is compared against for unknown logins, so that they take as
+	// long to reject as a wrong password. It never logs anyone in.
+	dummyHash []byte
 }
 
-func New() (*Service, error) {
-	svc := &Service{}
+func New(repo storage.Repository) (*Service, error) {
+	svc := &Service{storage: repo}
 
 	cfg, err := readConfig()
 	if err != nil {
@@ -55,10 +98,11 @@ func New() (*Service, error) {
 	}
 
 	svc.Config = cfg
+	svc.ipThrottle = newLoginThrottle(cfg.LoginIPMaxAttempts, cfg.LoginLockout, cfg.LoginLockoutMax)
 
-	svc.storage, err = storage.New()
+	svc.dummyHash, err = bcrypt.GenerateFromPassword([]byte("dummy password"), cfg.BcryptCost)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create storage service")
+		return svc, errors.Wrap(err, "failed to hash dummy password")
 	}
 
 	return svc, nil
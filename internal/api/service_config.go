@@ -1,12 +1,33 @@
 package api
 
 import (
+	"time"
+
 	"github.com/kelseyhightower/envconfig"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type Config struct {
-	JWTSecret string `envconfig:"JWT_SECRET" required:"true"`
+	JWTSecret       string        `envconfig:"JWT_SECRET" required:"true"`
+	AccessTokenTTL  time.Duration `envconfig:"ACCESS_TOKEN_TTL" default:"15m"`
+	RefreshTokenTTL time.Duration `envconfig:"REFRESH_TOKEN_TTL" default:"168h"`
+
+	BcryptCost       int           `envconfig:"BCRYPT_COST" default:"12"`
+	PasswordResetTTL time.Duration `envconfig:"PASSWORD_RESET_TTL" default:"24h"`
+	PasswordPolicy
+
+	LoginMaxAttempts   int           `envconfig:"LOGIN_MAX_ATTEMPTS" default:"5"`
+	LoginIPMaxAttempts int           `envconfig:"LOGIN_IP_MAX_ATTEMPTS" default:"20"`
+	LoginLockout       time.Duration `envconfig:"LOGIN_LOCKOUT" default:"1m"`
+	LoginLockoutMax    time.Duration `envconfig:"LOGIN_LOCKOUT_MAX" default:"1h"`
+}
+
+type PasswordPolicy struct {
+	MinLength        int  `envconfig:"PASSWORD_MIN_LENGTH" default:"12"`
+	RequireMixedCase bool `envconfig:"PASSWORD_REQUIRE_MIXED_CASE" default:"true"`
+	RequireDigit     bool `envconfig:"PASSWORD_REQUIRE_DIGIT" default:"true"`
+	RequireSymbol    bool `envconfig:"PASSWORD_REQUIRE_SYMBOL" default:"false"`
 }
 
 func readConfig() (Config, error) {
@@ -15,5 +36,9 @@ func readConfig() (Config, error) {
 		return Config{}, errors.Wrap(err, "failed to parse config")
 	}
 
+	if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+		return Config{}, errors.Errorf("BCRYPT_COST must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
 	return cfg, nil
 }
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"api/internal/models"
+	"github.com/pkg/errors"
+)
+
+// AccommodationStays returns the stays and bookings of the accommodation.
+func (s *Service) AccommodationStays(ctx context.Context, id int) ([]models.Stay, error) {
+	stays, err := s.storage.AccommodationStays(ctx, id)
+
+	return stays, errors.Wrap(err, "failed to retrieve stays")
+}
+
+// EmployeeStays returns the employee's stays and bookings.
+func (s *Service) EmployeeStays(ctx context.Context, id int) ([]models.Stay, error) {
+	stays, err := s.storage.EmployeeStays(ctx, id)
+
+	return stays, errors.Wrap(err, "failed to retrieve stays")
+}
+
+// AddStay books a bed in the accommodation for an employee. A stay starting
+// later than today reserves the bed until the employee arrives.
+func (s *Service) AddStay(ctx context.Context, accommodationID int, newStay models.NewStay) (stay models.Stay, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		stay, err = s.addStay(ctx, accommodationID, newStay)
+		return err
+	})
+
+	return stay, err
+}
+
+func (s *Service) addStay(ctx context.Context, accommodationID int, newStay models.NewStay) (models.Stay, error) {
+	if newStay.EmployeeID == 0 {
+		return models.Stay{}, errors.Wrap(ErrInvalidStay, "employee is required")
+	}
+
+	if time.Time(newStay.CheckIn).IsZero() {
+		return models.Stay{}, errors.Wrap(ErrInvalidStay, "check-in date is required")
+	}
+
+	if newStay.CheckOut != nil && !time.Time(*newStay.CheckOut).After(time.Time(newStay.CheckIn)) {
+		return models.Stay{}, errors.Wrap(ErrInvalidStay, "check-out date must be after the check-in date")
+	}
+
+	id, err := s.storage.AddStay(ctx, accommodationID, newStay)
+	if err != nil {
+		return models.Stay{}, errors.Wrap(err, "failed to add stay")
+	}
+
+	stay, err := s.storage.GetStay(ctx, id)
+	if err != nil {
+		return models.Stay{}, errors.Wrap(err, "failed to retrieve added stay")
+	}
+
+	err = s.audit(ctx, models.EntityStay, id, models.AuditCreate, nil, stay)
+
+	return stay, errors.Wrap(err, "failed to add stay")
+}
+
+// CheckOutStay ends the stay on checkOut.Date, which defaults to today. A stay
+// can only be shortened this way.
+func (s *Service) CheckOutStay(ctx context.Context, id int, checkOut models.CheckOut) (stay models.Stay, err error) {
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		stay, err = s.checkOutStay(ctx, id, checkOut)
+		return err
+	})
+
+	return stay, err
+}
+
+func (s *Service) checkOutStay(ctx context.Context, id int, checkOut models.CheckOut) (models.Stay, error) {
+	before, err := s.storage.GetStay(ctx, id)
+	if err != nil {
+		return models.Stay{}, errors.Wrap(err, "failed to check out")
+	}
+
+	day := models.Today()
+	if checkOut.Date != nil {
+		day = *checkOut.Date
+	}
+
+	if !time.Time(day).After(time.Time(before.CheckIn)) {
+		return models.Stay{}, errors.Wrap(ErrInvalidStay, "check-out date must be after the check-in date")
+	}
+
+	if before.CheckOut != nil && !time.Time(day).Before(time.Time(*before.CheckOut)) {
+		return models.Stay{}, errors.Wrap(ErrInvalidStay, "stay already ends by then")
+	}
+
+	err = s.storage.CheckOutStay(ctx, id, day)
+	if err != nil {
+		return models.Stay{}, errors.Wrap(err, "failed to check out")
+	}
+
+	stay, err := s.storage.GetStay(ctx, id)
+	if err != nil {
+		return models.Stay{}, errors.Wrap(err, "failed to retrieve stay")
+	}
+
+	err = s.audit(ctx, models.EntityStay, id, models.AuditUpdate, before, stay)
+
+	return stay, errors.Wrap(err, "failed to check out")
+}
+
+// CancelStay removes a booking that has not started yet.
+func (s *Service) CancelStay(ctx context.Context, id int) error {
+	return s.storage.InTx(ctx, func(ctx context.Context) error {
+		return s.cancelStay(ctx, id)
+	})
+}
+
+func (s *Service) cancelStay(ctx context.Context, id int) error {
+	before, err := s.storage.GetStay(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to cancel stay")
+	}
+
+	if !time.Time(before.CheckIn).After(time.Time(models.Today())) {
+		return errors.Wrap(ErrInvalidStay, "stay has already started")
+	}
+
+	err = s.storage.CancelStay(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to cancel stay")
+	}
+
+	err = s.audit(ctx, models.EntityStay, id, models.AuditDelete, before, nil)
+
+	return errors.Wrap(err, "failed to cancel stay")
+}
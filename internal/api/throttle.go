@@ -0,0 +1,100 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// lockoutDuration returns for how long an identity stays locked after the
+// given number of consecutive failures. The lockout starts once maxAttempts
+// is reached and doubles with every further failure, up to maxLockout.
+func lockoutDuration(failures, maxAttempts int, lockout, maxLockout time.Duration) time.Duration {
+	if failures < maxAttempts {
+		return 0
+	}
+
+	d := lockout
+	for i := maxAttempts; i < failures && d < maxLockout; i++ {
+		d *= 2
+	}
+
+	return min(d, maxLockout)
+}
+
+type throttleEntry struct {
+	failures    int
+	lockedUntil time.Time
+	lastFailure time.Time
+}
+
+// loginThrottle counts failed logins per client IP in memory.
+type loginThrottle struct {
+	mu        sync.Mutex
+	entries   map[string]*throttleEntry
+	lastPrune time.Time
+
+	maxAttempts int
+	lockout     time.Duration
+	maxLockout  time.Duration
+}
+
+func newLoginThrottle(maxAttempts int, lockout, maxLockout time.Duration) *loginThrottle {
+	return &loginThrottle{
+		entries:     make(map[string]*throttleEntry),
+		maxAttempts: maxAttempts,
+		lockout:     lockout,
+		maxLockout:  maxLockout,
+	}
+}
+
+// lockedFor returns how long the key remains locked, or zero if it is not.
+func (t *loginThrottle) lockedFor(key string, now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.entries[key]
+	if !ok || !now.Before(e.lockedUntil) {
+		return 0
+	}
+
+	return e.lockedUntil.Sub(now)
+}
+
+func (t *loginThrottle) fail(key string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.prune(now)
+
+	e, ok := t.entries[key]
+	if !ok {
+		e = &throttleEntry{}
+		t.entries[key] = e
+	}
+
+	e.failures++
+	e.lastFailure = now
+	e.lockedUntil = now.Add(lockoutDuration(e.failures, t.maxAttempts, t.lockout, t.maxLockout))
+}
+
+func (t *loginThrottle) reset(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.entries, key)
+}
+
+// prune forgets keys that have not failed for longer than the maximum lockout.
+// It runs at most once a minute.
+func (t *loginThrottle) prune(now time.Time) {
+	if now.Sub(t.lastPrune) < time.Minute {
+		return
+	}
+	t.lastPrune = now
+
+	for key, e := range t.entries {
+		if now.Sub(e.lastFailure) > t.maxLockout && !now.Before(e.lockedUntil) {
+			delete(t.entries, key)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"api/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// RefreshToken exchanges a refresh token for a new access and refresh token
+// pair. The presented refresh token is revoked; presenting an already revoked
+// one is treated as token theft and ends every session of the user.
+func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (models.LoginResponse, error) {
+	hash := hashToken(refreshToken)
+
+	token, err := s.storage.GetRefreshToken(ctx, hash)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return models.LoginResponse{}, ErrUnauthorized
+		}
+		return models.LoginResponse{}, fmt.Errorf("failed to retrieve refresh token: %w", err)
+	}
+
+	if token.RevokedAt != nil {
+		err = s.storage.RevokeUserTokens(ctx, token.Login, time.Now())
+		if err != nil {
+			return models.LoginResponse{}, fmt.Errorf("failed to revoke user tokens: %w", err)
+		}
+		return models.LoginResponse{}, ErrUnauthorized
+	}
+
+	if time.Now().After(token.ExpiresAt) {
+		return models.LoginResponse{}, ErrUnauthorized
+	}
+
+	var response models.LoginResponse
+
+	err = s.storage.InTx(ctx, func(ctx context.Context) error {
+		err := s.storage.RevokeRefreshToken(ctx, hash, time.Now())
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return ErrUnauthorized
+			}
+			return fmt.Errorf("failed to revoke refresh token: %w", err)
+		}
+
+		user, err := s.storage.GetUser(ctx, token.Login)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return ErrUnauthorized
+			}
+			return fmt.Errorf("failed to retrieve user: %w", err)
+		}
+
+		response, err = s.issueTokens(ctx, user)
+
+		return err
+	})
+
+	return response, err
+}
+
+// Logout revokes the access token identified by jti together with the refresh
+// token it was issued with.
+func (s *Service) Logout(ctx context.Context, jti string, expiresAt time.Time) error {
+	err := s.storage.RevokeAccessToken(ctx, jti, expiresAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeUserTokens ends every active session of the given user.
+func (s *Service) RevokeUserTokens(ctx context.Context, login string) error {
+	err := s.storage.RevokeUserTokens(ctx, login, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	revoked, err := s.storage.IsTokenRevoked(ctx, jti)
+	if err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+
+	return revoked, nil
+}
+
+func (s *Service) issueTokens(ctx context.Context, user models.User) (models.LoginResponse, error) {
+	now := time.Now()
+	jti := uuid.NewString()
+	accessExp := now.Add(s.Config.AccessTokenTTL)
+	refreshExp := now.Add(s.Config.RefreshTokenTTL)
+
+	projectIDs, err := s.storage.GetUserProjects(ctx, user.EmployeeID)
+	if err != nil {
+		return models.LoginResponse{}, fmt.Errorf("failed to retrieve user projects: %w", err)
+	}
+
+	t := jwt.NewWithClaims(jwt.SigningMethodHS256, models.JWTCustomClaims{
+		Username: user.Login,
+		Role:     user.Role,
+		Projects: projectIDs,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Issuer:    "api",
+			ExpiresAt: jwt.NewNumericDate(accessExp),
+			// No nbf: the verifier rejects a token whose nbf is the current
+			// second, i.e. right after it was issued.
+			IssuedAt: jwt.NewNumericDate(now),
+		},
+	})
+
+	jwtString, err := t.SignedString([]byte(s.Config.JWTSecret))
+	if err != nil {
+		return models.LoginResponse{}, fmt.Errorf("failed to sign JWT: %w", err)
+	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return models.LoginResponse{}, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	err = s.storage.AddRefreshToken(ctx, models.RefreshToken{
+		Hash:            hashToken(refreshToken),
+		Login:           user.Login,
+		AccessJTI:       jti,
+		AccessExpiresAt: accessExp,
+		ExpiresAt:       refreshExp,
+	})
+	if err != nil {
+		return models.LoginResponse{}, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return models.LoginResponse{
+		JWT:          jwtString,
+		Exp:          accessExp.Unix(),
+		RefreshToken: refreshToken,
+		RefreshExp:   refreshExp.Unix(),
+	}, nil
+}
+
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the form in which opaque tokens are stored, so that a
+// database leak does not hand out usable tokens.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,369 @@
+package api
+
+import (
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"api/internal/models"
+)
+
+// ValidationError is returned when a record to be added or updated has
+// fields that are not valid. Fields are named as in the JSON of the record
+// returned to clients.
+type ValidationError struct {
+	Violations []Violation
+}
+
+// Violation is a field of a record that is not valid and the reason why.
+type Violation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+
+	// value holds the values the violation was found in, so that
+	// introducedBy can tell whether an update changed them.
+	value string
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Field + " " + v.Message
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalid
+}
+
+// validation collects the violations of a record.
+type validation []Violation
+
+// add records a violation of field found in values: the value of the field
+// and those of any other fields it was checked against.
+func (v *validation) add(field, message string, values ...string) {
+	*v = append(*v, Violation{Field: field, Message: message, value: strings.Join(values, " ")})
+}
+
+// err returns the violations as a ValidationError, or nil if there are none.
+func (v validation) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Violations: v}
+}
+
+// introducedBy returns the violations of an update that the record did not
+// have before it with the same values, so that records stored before they
+// were validated can still be edited as long as the fields that are not
+// valid are left unchanged.
+func (v validation) introducedBy(before validation) validation {
+	var introduced validation
+
+	for _, violation := range v {
+		found := false
+		for _, b := range before {
+			if b == violation {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			introduced = append(introduced, violation)
+		}
+	}
+
+	return introduced
+}
+
+// Fields that are empty are not validated; requiring them is left to the
+// clients. A zero PaymentDay means none was agreed.
+
+func validateEmployee(e models.UpdateEmployee) validation {
+	var v validation
+
+	if e.Pesel != "" {
+		birth, ok := peselBirthDate(e.Pesel)
+		switch {
+		case !ok:
+			v.add("pesel", "is not a valid PESEL", e.Pesel)
+		case !time.Time(e.DateOfBirth).IsZero() && !birth.Equal(time.Time(e.DateOfBirth)):
+			v.add("pesel", "does not match date_of_birth", e.Pesel, dateOnly(time.Time(e.DateOfBirth)))
+		}
+	}
+
+	if e.Email != "" && !validEmail(e.Email) {
+		v.add("email", "is not a valid e-mail address", e.Email)
+	}
+
+	if e.BankAccount != "" && !validBankAccount(e.BankAccount) {
+		v.add("bank_account", "is not a valid IBAN or NRB", e.BankAccount)
+	}
+
+	if end := e.Employment.EndDate.ConvertToTime(); end != nil && !ordered(e.Employment.StartDate, *end) {
+		v.add("employment.end_date", "must be after employment.start_date", dateOnly(time.Time(e.Employment.StartDate)), dateOnly(*end))
+	}
+
+	return v
+}
+
+func validateCar(c models.UpdateCar) validation {
+	var v validation
+
+	if c.VIN != "" && !validVIN(c.VIN) {
+		v.add("vin", "is not a valid VIN", c.VIN)
+	}
+
+	if c.RegistrationNumber != "" && !validRegistrationNumber(c.RegistrationNumber) {
+		v.add("registration_number", "is not a valid Polish registration number", c.RegistrationNumber)
+	}
+
+	if !time.Time(c.InspectionTo).IsZero() && !ordered(c.InspectionFrom, time.Time(c.InspectionTo)) {
+		v.add("inspection_to", "must be after inspection_from", dateOnly(time.Time(c.InspectionFrom)), dateOnly(time.Time(c.InspectionTo)))
+	}
+
+	if !time.Time(c.InsuranceTo).IsZero() && !ordered(c.InsuranceFrom, time.Time(c.InsuranceTo)) {
+		v.add("insurance_to", "must be after insurance_from", dateOnly(time.Time(c.InsuranceFrom)), dateOnly(time.Time(c.InsuranceTo)))
+	}
+
+	if !validPaymentDay(c.Leasing.PaymentDay) {
+		v.add("leasing.payment_day", "must be between 1 and 31", strconv.Itoa(c.Leasing.PaymentDay))
+	}
+
+	return v
+}
+
+func validateProject(p models.UpdateProject) validation {
+	var v validation
+
+	if p.ProjectNIP != "" && !validNIP(p.ProjectNIP) {
+		v.add("project_nip", "is not a valid NIP", p.ProjectNIP)
+	}
+
+	return v
+}
+
+func validateAccommodation(a models.UpdateAccommodation) validation {
+	var v validation
+
+	if a.Payment.AccountNumber != "" && !validBankAccount(a.Payment.AccountNumber) {
+		v.add("payment.account_number", "is not a valid IBAN or NRB", a.Payment.AccountNumber)
+	}
+
+	if !validPaymentDay(a.Payment.PaymentDay) {
+		v.add("payment.payment_day", "must be between 1 and 31", strconv.Itoa(a.Payment.PaymentDay))
+	}
+
+	return v
+}
+
+// ordered reports whether from is before to. A zero from is not checked.
+func ordered(from models.Date, to time.Time) bool {
+	return time.Time(from).IsZero() || time.Time(from).Before(to)
+}
+
+// dateOnly formats the date of t for comparing violations, ignoring its
+// location, which differs between dates parsed and read from the database.
+func dateOnly(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+func validPaymentDay(day int) bool {
+	return day >= 0 && day <= 31
+}
+
+func validEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+
+	return err == nil && address.Address == email
+}
+
+// peselBirthDate returns the date of birth encoded in a PESEL, checking its
+// length and check digit. The century is encoded by adding 80, 0, 20, 40 or
+// 60 to the month for the 1800s to the 2200s.
+func peselBirthDate(pesel string) (time.Time, bool) {
+	digits, ok := digitsOf(pesel, 11)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	weights := []int{1, 3, 7, 9, 1, 3, 7, 9, 1, 3}
+
+	sum := 0
+	for i, w := range weights {
+		sum += digits[i] * w
+	}
+
+	if (10-sum%10)%10 != digits[10] {
+		return time.Time{}, false
+	}
+
+	year := digits[0]*10 + digits[1]
+	month := digits[2]*10 + digits[3]
+	day := digits[4]*10 + digits[5]
+
+	centuries := map[int]int{80: 1800, 0: 1900, 20: 2000, 40: 2100, 60: 2200}
+	offset := month / 20 * 20
+
+	century, ok := centuries[offset]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	birth := time.Date(century+year, time.Month(month-offset), day, 0, 0, 0, 0, time.UTC)
+	if birth.Month() != time.Month(month-offset) || birth.Day() != day {
+		return time.Time{}, false
+	}
+
+	return birth, true
+}
+
+// validNIP checks the check digit of a tax identification number, which may
+// be written with dashes or spaces and prefixed with PL.
+func validNIP(nip string) bool {
+	nip = strings.TrimPrefix(strings.ToUpper(stripSeparators(nip)), "PL")
+
+	digits, ok := digitsOf(nip, 10)
+	if !ok {
+		return false
+	}
+
+	weights := []int{6, 5, 7, 2, 3, 4, 5, 6, 7}
+
+	sum := 0
+	for i, w := range weights {
+		sum += digits[i] * w
+	}
+
+	return sum%11 == digits[9]
+}
+
+// ibanLengths holds the lengths of IBANs of the countries whose accounts
+// employees and landlords are expected to have; others are only checked
+// against the overall limits.
+var ibanLengths = map[string]int{
+	"PL": 28, "DE": 22, "CZ": 24, "SK": 24, "LT": 20, "UA": 29, "GE": 22,
+}
+
+// validBankAccount checks an IBAN or a Polish NRB, which is an IBAN without
+// the PL prefix.
+func validBankAccount(account string) bool {
+	account = strings.ToUpper(stripSeparators(account))
+	if _, ok := digitsOf(account, 26); ok {
+		account = "PL" + account
+	}
+
+	if len(account) < 15 || len(account) > 34 {
+		return false
+	}
+
+	country := account[:2]
+	if n, ok := ibanLengths[country]; ok && len(account) != n {
+		return false
+	}
+
+	// Move the country and check digits to the end, read letters as numbers
+	// from 10 for A to 35 for Z and take the remainder modulo 97 digit by
+	// digit.
+	remainder := 0
+	for i, c := range account[4:] + account[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			remainder = (remainder*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			remainder = (remainder*100 + int(c-'A'+10)) % 97
+		default:
+			return false
+		}
+
+		if i >= len(account)-4 && i < len(account)-2 && (c < 'A' || c > 'Z') {
+			return false
+		}
+	}
+
+	return remainder == 1
+}
+
+// vinValues transliterates the characters of a VIN for its check digit. I, O
+// and Q are not used in VINs.
+var vinValues = map[rune]int{
+	'A': 1, 'B': 2, 'C': 3, 'D': 4, 'E': 5, 'F': 6, 'G': 7, 'H': 8,
+	'J': 1, 'K': 2, 'L': 3, 'M': 4, 'N': 5, 'P': 7, 'R': 9,
+	'S': 2, 'T': 3, 'U': 4, 'V': 5, 'W': 6, 'X': 7, 'Y': 8, 'Z': 9,
+}
+
+// validVIN checks the length and characters of a VIN, and its check digit
+// if it is North American: elsewhere, including in Europe, the ninth
+// character need not be a check digit.
+func validVIN(vin string) bool {
+	vin = strings.ToUpper(vin)
+	if len(vin) != 17 {
+		return false
+	}
+
+	weights := []int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+	sum := 0
+	for i, c := range vin {
+		value, ok := vinValues[c]
+		if c >= '0' && c <= '9' {
+			value, ok = int(c-'0'), true
+		}
+		if !ok {
+			return false
+		}
+
+		sum += value * weights[i]
+	}
+
+	// The first character of the manufacturer identifier gives the region,
+	// 1 to 5 being North America.
+	if vin[0] < '1' || vin[0] > '5' {
+		return true
+	}
+
+	check := byte('0' + sum%11)
+	if sum%11 == 10 {
+		check = 'X'
+	}
+
+	return vin[8] == check
+}
+
+// registrationNumber matches Polish registration numbers: the letter of the
+// voivodeship, or of a uniformed service, and one more letter followed by
+// five letters and digits, or two more letters followed by four or five, or
+// a custom number of the letter of the voivodeship and a digit followed by
+// three to five letters and digits.
+var registrationNumber = regexp.MustCompile(`^(?:[BCDEFGKLNOPRSTWZHU](?:[A-Z] ?[0-9A-Z]{5}|[A-Z]{2} ?[0-9A-Z]{4,5})|[BCDEFGKLNOPRSTWZ][0-9] ?[A-Z][0-9A-Z]{2,4})$`)
+
+func validRegistrationNumber(number string) bool {
+	return registrationNumber.MatchString(strings.ToUpper(strings.TrimSpace(number)))
+}
+
+// digitsOf returns the digits of s if it is made of n digits.
+func digitsOf(s string, n int) ([]int, bool) {
+	if len(s) != n {
+		return nil, false
+	}
+
+	digits := make([]int, n)
+	for i, c := range s {
+		if c < '0' || c > '9' {
+			return nil, false
+		}
+		digits[i] = int(c - '0')
+	}
+
+	return digits, true
+}
+
+func stripSeparators(s string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(s)
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"api/internal/models"
+)
+
+func TestPeselBirthDate(t *testing.T) {
+	tests := []struct {
+		pesel string
+		want  string
+	}{
+		{"85831412341", "1885-03-14"},
+		{"85010112345", "1985-01-01"},
+		{"04323112343", "2004-12-31"},
+		{"00222912349", "2000-02-29"},
+		{"04422912343", "2104-02-29"},
+		{"85010112346", ""}, // check digit
+		{"00022912345", ""}, // 1900 is not a leap year
+		{"85130112340", ""}, // month 13
+		{"8501011234", ""},
+		{"8501011234a", ""},
+	}
+
+	for _, tt := range tests {
+		birth, ok := peselBirthDate(tt.pesel)
+
+		got := ""
+		if ok {
+			got = birth.Format(time.DateOnly)
+		}
+
+		if got != tt.want {
+			t.Errorf("peselBirthDate(%s) = %q, want %q", tt.pesel, got, tt.want)
+		}
+	}
+}
+
+func TestValidNIP(t *testing.T) {
+	tests := []struct {
+		nip  string
+		want bool
+	}{
+		{"5260250274", true},
+		{"526-025-02-74", true},
+		{"PL 526 025 02 74", true},
+		{"5260250275", false},
+		{"3170669070", false}, // the weighted sum is 10 modulo 11, so no check digit fits
+		{"3170669071", false},
+		{"526025027", false},
+	}
+
+	for _, tt := range tests {
+		if got := validNIP(tt.nip); got != tt.want {
+			t.Errorf("validNIP(%s) = %t, want %t", tt.nip, got, tt.want)
+		}
+	}
+}
+
+func TestValidBankAccount(t *testing.T) {
+	tests := []struct {
+		account string
+		want    bool
+	}{
+		{"PL61109010140000071219812874", true},
+		{"61109010140000071219812874", true}, // NRB
+		{"61 1090 1014 0000 0712 1981 2874", true},
+		{"61109010140000071219812875", false},
+		{"DE89370400440532013000", true},
+		{"de89 3704 0044 0532 0130 00", true},
+		{"DE8937040044053201300", false}, // length for DE
+		{"GB82WEST12345698765432", true},
+		{"GB82WEST12345698765433", false},
+		{"PLAB109010140000071219812874", false},
+	}
+
+	for _, tt := range tests {
+		if got := validBankAccount(tt.account); got != tt.want {
+			t.Errorf("validBankAccount(%s) = %t, want %t", tt.account, got, tt.want)
+		}
+	}
+}
+
+func TestValidVIN(t *testing.T) {
+	tests := []struct {
+		vin  string
+		want bool
+	}{
+		{"1HGCM82633A004352", true},
+		{"1hgcm82633a004352", true},
+		{"1HGCM82643A004352", false}, // check digit
+		{"1M8GDM9AXKP042788", true},  // X check digit
+		{"1M8GDM9A1KP042788", false},
+		{"VF1RFB00956789012", true}, // no check digit outside North America
+		{"WVWZZZ1JZXW000001", true},
+		{"VF1RFB0095678901", false},
+		{"VF1RFB0O956789012", false}, // O is not used
+	}
+
+	for _, tt := range tests {
+		if got := validVIN(tt.vin); got != tt.want {
+			t.Errorf("validVIN(%s) = %t, want %t", tt.vin, got, tt.want)
+		}
+	}
+}
+
+func TestValidRegistrationNumber(t *testing.T) {
+	tests := []struct {
+		number string
+		want   bool
+	}{
+		{"PO1234A", true},
+		{"WX 12345", true},
+		{"WPI 1234A", true},
+		{"kr 3ab12", true},
+		{"W0 ABC", true}, // custom
+		{"HPA 1234", true},
+		{"XY 12345", false},
+		{"P 12345", false},
+		{"WX 1234567", false},
+	}
+
+	for _, tt := range tests {
+		if got := validRegistrationNumber(tt.number); got != tt.want {
+			t.Errorf("validRegistrationNumber(%s) = %t, want %t", tt.number, got, tt.want)
+		}
+	}
+}
+
+func TestIntroducedBy(t *testing.T) {
+	before := models.UpdateEmployee{Pesel: "85010112346", BankAccount: "61109010140000071219812875"}
+
+	unchanged := before
+	unchanged.LastName = "Nowak"
+	if v := validateEmployee(unchanged).introducedBy(validateEmployee(before)); len(v) != 0 {
+		t.Errorf("an update leaving invalid fields unchanged is rejected: %v", v)
+	}
+
+	changed := before
+	changed.Pesel = "85010112347"
+	v := validateEmployee(changed).introducedBy(validateEmployee(before))
+	if len(v) != 1 || v[0].Field != "pesel" {
+		t.Errorf("changing an invalid PESEL to another gives %v, want a violation of pesel", v)
+	}
+
+	fixed := before
+	fixed.Pesel = "85010112345"
+	fixed.DateOfBirth = models.Date(time.Date(1986, 1, 1, 0, 0, 0, 0, time.UTC))
+	v = validateEmployee(fixed).introducedBy(validateEmployee(before))
+	if len(v) != 1 || v[0].Message != "does not match date_of_birth" {
+		t.Errorf("a PESEL not matching date_of_birth gives %v, want it reported", v)
+	}
+}
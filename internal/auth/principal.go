@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"api/internal/models"
+)
+
+type contextKey struct{}
+
+// Principal describes the authenticated user a request is made on behalf of.
+type Principal struct {
+	Username string
+	Role     models.Role
+
+	// ProjectIDs lists the projects a non-admin principal is bound to.
+	ProjectIDs []int
+
+	// TokenID and ExpiresAt identify the access token the request was made
+	// with, so it can be revoked on logout.
+	TokenID   string
+	ExpiresAt time.Time
+}
+
+// System is the principal of work not done on behalf of a user, such as the
+// maintenance commands and seeding the demo data.
+var System = Principal{Username: "system", Role: models.RoleAdmin}
+
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(Principal)
+	return p, ok
+}
+
+// HasRole reports whether the principal has one of the given roles.
+func (p Principal) HasRole(roles ...models.Role) bool {
+	for _, role := range roles {
+		if p.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectScope returns the projects the principal in ctx is restricted to.
+// Admins are not restricted; without a principal no project is accessible.
+func ProjectScope(ctx context.Context) (projectIDs []int, restricted bool) {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return nil, true
+	}
+
+	if p.Role == models.RoleAdmin {
+		return nil, false
+	}
+
+	return p.ProjectIDs, true
+}
+
+// Permission names an action that only some roles may perform.
+type Permission string
+
+const (
+	// PermViewSensitiveData allows reading PESEL, passport and bank account
+	// numbers unmasked.
+	PermViewSensitiveData Permission = "view_sensitive_data"
+)
+
+var rolePermissions = map[models.Role][]Permission{
+	models.RoleAdmin:       {PermViewSensitiveData},
+	models.RoleCoordinator: {PermViewSensitiveData},
+	models.RoleReadOnly:    {},
+}
+
+func (p Principal) Can(permission Permission) bool {
+	return slices.Contains(rolePermissions[p.Role], permission)
+}
+
+// Can reports whether the principal in ctx has the permission. Without a
+// principal nothing is permitted.
+func Can(ctx context.Context, permission Permission) bool {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	return p.Can(permission)
+}
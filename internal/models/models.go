@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -33,11 +34,35 @@ func (d Date) ConvertToTime() time.Time {
 	return time.Time(d)
 }
 
+// Today returns the current local date at midnight UTC, the way dates are
+// decoded from JSON.
+func Today() Date {
+	year, month, day := time.Now().Date()
+
+	return Date(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// AddDays returns the date n days after d.
+func (d Date) AddDays(n int) Date {
+	return Date(time.Time(d).AddDate(0, 0, n))
+}
+
 type NullableDate struct {
 	isSet bool
 	date  *time.Time
 }
 
+// NewNullableDate returns d as a NullableDate, which is unset if d is nil.
+func NewNullableDate(d *Date) NullableDate {
+	if d == nil {
+		return NullableDate{}
+	}
+
+	t := time.Time(*d)
+
+	return NullableDate{isSet: true, date: &t}
+}
+
 func (d *NullableDate) UnmarshalJSON(b []byte) error {
 	str := string(b)
 
@@ -65,7 +90,7 @@ func (d NullableDate) MarshalJSON() ([]byte, error) {
 		return []byte(stamp), nil
 	}
 
-	return []byte{}, nil
+	return []byte("null"), nil
 }
 
 func (d NullableDate) ConvertToTime() *time.Time {
@@ -75,11 +100,124 @@ func (d NullableDate) ConvertToTime() *time.Time {
 	return nil
 }
 
+// Role determines which routes an authenticated user is allowed to call.
+type Role string
+
+const (
+	RoleAdmin       Role = "admin"
+	RoleCoordinator Role = "coordinator"
+	RoleReadOnly    Role = "read_only"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleCoordinator, RoleReadOnly:
+		return true
+	}
+	return false
+}
+
 type JWTCustomClaims struct {
 	Username string `json:"username"`
+	Role     Role   `json:"role"`
+	Projects []int  `json:"projects,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// User holds the credentials stored next to an employee record.
+type User struct {
+	EmployeeID     int
+	Login          string
+	Password       string
+	Role           Role
+	FailedAttempts int
+	LockedUntil    *time.Time
+}
+
+// ClientInfo identifies the client a request originates from.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+type LoginAttempt struct {
+	Login       string
+	IP          string
+	UserAgent   string
+	Success     bool
+	Reason      string
+	AttemptedAt time.Time
+}
+
+// RefreshToken is the stored form of an issued refresh token. AccessJTI
+// identifies the access token handed out together with it, so both can be
+// revoked at once.
+type RefreshToken struct {
+	Hash            string
+	Login           string
+	AccessJTI       string
+	AccessExpiresAt time.Time
+	ExpiresAt       time.Time
+	RevokedAt       *time.Time
+}
+
+// PasswordReset is the stored form of a one-time password reset token.
+type PasswordReset struct {
+	Hash      string
+	Login     string
+	ExpiresAt time.Time
+}
+
+type EntityType string
+
+const (
+	EntityEmployee      EntityType = "employee"
+	EntityCar           EntityType = "car"
+	EntityProject       EntityType = "project"
+	EntityAccommodation EntityType = "accommodation"
+	EntityStay          EntityType = "stay"
+	EntityHandover      EntityType = "handover"
+)
+
+type AuditAction string
+
+const (
+	AuditCreate AuditAction = "create"
+	AuditUpdate AuditAction = "update"
+	// AuditDelete records removed for good, such as cancelled bookings, and
+	// deletions from before records were archived.
+	AuditDelete  AuditAction = "delete"
+	AuditArchive AuditAction = "archive"
+	AuditRestore AuditAction = "restore"
+	// AuditTransfer records an employee moving to another project.
+	AuditTransfer AuditAction = "transfer"
+)
+
+type AuditEntry struct {
+	ID         int             `json:"id"`
+	Username   string          `json:"username"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	EntityType EntityType      `json:"entity_type"`
+	EntityID   int             `json:"entity_id"`
+	Action     AuditAction     `json:"action"`
+	Changes    json.RawMessage `json:"changes"`
+}
+
+// AuditChange holds the values of a single field before and after a change.
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+type AuditFilter struct {
+	EntityType EntityType
+	EntityID   int
+	Username   string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+}
+
 type DashboardEmployeesProject struct {
 	Name  string `json:"name"`
 	Count int    `json:"count"`
@@ -110,6 +248,70 @@ type Dashboard struct {
 	EmployeePermits  []DashboardEmployeePermits  `json:"employee_permits"`
 }
 
+// Archival records when and by whom a deleted record was archived. Archived
+// records are kept for reference and can be restored.
+type Archival struct {
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	ArchivedBy string     `json:"archived_by,omitempty"`
+}
+
+// Archived reports whether the record has been deleted.
+func (a Archival) Archived() bool {
+	return a.ArchivedAt != nil
+}
+
+// ListFilter holds the options shared by all list endpoints.
+type ListFilter struct {
+	// IncludeArchived lists archived records along with active ones.
+	IncludeArchived bool
+
+	// Sort orders the list by fields named as in the JSON of its records.
+	// Records that compare equal stay in the order of their IDs.
+	Sort []SortKey
+
+	// Limit and Offset select a page of the list. A Limit of 0 lists all
+	// records.
+	Limit  int
+	Offset int
+}
+
+// SortKey is a field a list is sorted by.
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+// Page is a page of a list together with the number of records on all of
+// its pages.
+type Page[T any] struct {
+	Items  []T `json:"items"`
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// NewPage returns the page of a list selected by filter.
+func NewPage[T any](items []T, total int, filter ListFilter) Page[T] {
+	return Page[T]{Items: items, Total: total, Limit: filter.Limit, Offset: filter.Offset}
+}
+
+// CarFilter narrows down the car list. ExpiringBefore matches cars whose
+// inspection or insurance ends before that day.
+type CarFilter struct {
+	ListFilter
+
+	ProjectID      int
+	ExpiringBefore *Date
+}
+
+// AccommodationFilter narrows down the accommodation list.
+type AccommodationFilter struct {
+	ListFilter
+
+	ProjectID int
+	City      string
+}
+
 type Car struct {
 	ID                 int     `json:"id"`
 	Model              string  `json:"model"`
@@ -125,6 +327,51 @@ type Car struct {
 	ProjectName        string  `json:"project_name"`
 	Service            Service `json:"service"`
 	Leasing            Leasing `json:"leasing"`
+
+	// Version is incremented on every change and sent as the ETag.
+	Version int `json:"-"`
+
+	Archival
+}
+
+// CarDriver is a period during which an employee had a car. ValidTo is the
+// moment the car was handed back and is nil while they still have it.
+type CarDriver struct {
+	ID         int        `json:"id"`
+	CarID      int        `json:"car_id"`
+	EmployeeID int        `json:"employee_id"`
+	FirstName  string     `json:"first_name"`
+	LastName   string     `json:"last_name"`
+	ValidFrom  time.Time  `json:"valid_from"`
+	ValidTo    *time.Time `json:"valid_to"`
+}
+
+// Drove reports whether the employee had the car at some moment from from up
+// to and including to.
+func (d CarDriver) Drove(from, to time.Time) bool {
+	return !d.ValidFrom.After(to) && (d.ValidTo == nil || d.ValidTo.After(from))
+}
+
+// DriverFilter narrows down the drivers of a car to those who had it at some
+// moment from From up to and including To.
+type DriverFilter struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// CarHandover is the protocol of a car changing hands, signed by both
+// parties. A handover from nobody takes the car out of the fleet's pool, one
+// to nobody returns it there. FuelLevel is in percent of a full tank.
+type CarHandover struct {
+	ID             int       `json:"id"`
+	CarID          int       `json:"car_id"`
+	HandedAt       time.Time `json:"handed_at"`
+	FromEmployeeID *int      `json:"from_employee_id"`
+	ToEmployeeID   *int      `json:"to_employee_id"`
+	Odometer       int       `json:"odometer"`
+	FuelLevel      int       `json:"fuel_level"`
+	DamageNotes    string    `json:"damage_notes"`
+	RecordedBy     string    `json:"recorded_by"`
 }
 
 type CarNumbers struct {
@@ -185,6 +432,19 @@ type Project struct {
 	LastName       string `json:"last_name"`
 	Phone          string `json:"phone"`
 	Position       string `json:"position"`
+
+	// Version is incremented on every change and sent as the ETag.
+	Version int `json:"-"`
+
+	Archival
+}
+
+// ProjectFilter narrows down the project list. EmployeeAmount counts the
+// employees assigned on AsOf.
+type ProjectFilter struct {
+	ListFilter
+
+	AsOf Date
 }
 
 type ProjectNames struct {
@@ -221,6 +481,11 @@ type Accommodation struct {
 	NumberOfPlaces       int            `json:"number_of_places"`
 	Contact              ContactDetails `json:"contact"`
 	Payment              PaymentDetails `json:"payment"`
+
+	// Version is incremented on every change and sent as the ETag.
+	Version int `json:"-"`
+
+	Archival
 }
 
 type AccommodationAddresses struct {
@@ -228,6 +493,24 @@ type AccommodationAddresses struct {
 	Address string `json:"address"`
 }
 
+// Stay is an employee's stay in an accommodation. The employee takes a bed
+// from CheckIn up to, but not including, CheckOut, which is nil while the
+// stay is open-ended. Stays that have not started yet are bookings.
+type Stay struct {
+	ID              int    `json:"id"`
+	EmployeeID      int    `json:"employee_id"`
+	FirstName       string `json:"first_name"`
+	LastName        string `json:"last_name"`
+	AccommodationID int    `json:"accommodation_id"`
+	CheckIn         Date   `json:"check_in"`
+	CheckOut        *Date  `json:"check_out"`
+}
+
+// Covers reports whether the employee takes a bed on the given day.
+func (s Stay) Covers(day Date) bool {
+	return !time.Time(s.CheckIn).After(time.Time(day)) && (s.CheckOut == nil || time.Time(*s.CheckOut).After(time.Time(day)))
+}
+
 type NewAccommodation struct {
 	ProjectID      int                  `json:"idProject"`
 	City           string               `json:"city"`
@@ -276,6 +559,8 @@ type UpdatePaymentDetails struct {
 	PaymentDay    int     `json:"paymentDay"`
 }
 
+// Employee is the read model returned to clients. Credentials are only ever
+// loaded into User and must not be added here.
 type Employee struct {
 	ID               int                  `json:"id"`
 	LastName         string               `json:"last_name"`
@@ -292,13 +577,57 @@ type Employee struct {
 	AddressPoland    string               `json:"address_poland"`
 	HomeAddress      *string              `json:"home_address"`
 	Login            *string              `json:"login,omitempty"`
-	Password         *string              `json:"password,omitempty"`
 	ResidenceCard    ResidenceCardDetails `json:"residence_card"`
 	Employment       EmploymentDetails    `json:"employment"`
 	Medicals         MedicalDetails       `json:"medicals"`
 	ProjectId        int                  `json:"project_id"`
 	AccommodationId  *int                 `json:"accommodation_id"`
 	CarId            *int                 `json:"car_id"`
+
+	// Version is incremented on every change and sent as the ETag.
+	Version int `json:"-"`
+
+	Archival
+}
+
+// ProjectAssignment is a period during which an employee worked on a project.
+// ValidTo is the last day of the assignment and is nil for the current one.
+type ProjectAssignment struct {
+	ID          int    `json:"id"`
+	ProjectID   int    `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	ValidFrom   Date   `json:"valid_from"`
+	ValidTo     *Date  `json:"valid_to"`
+}
+
+// Current reports whether the assignment is still open.
+func (a ProjectAssignment) Current() bool {
+	return a.ValidTo == nil
+}
+
+// EmployeeFilter narrows down the employee list. PESEL and passport number
+// are matched exactly. ExpiringBefore matches employees with a medical or a
+// residence document that expires before that day.
+type EmployeeFilter struct {
+	ListFilter
+
+	Pesel          string
+	PassportNumber string
+	ProjectID      int
+	ContractType   string
+	ExpiringBefore *Date
+}
+
+// EmployeeSearch is a free-text search for employees by name, email, PESEL
+// or passport number. Its results are ranked, so ListFilter.Sort is ignored.
+type EmployeeSearch struct {
+	ListFilter
+
+	Query string
+
+	// Identifiers matches the query against PESEL and passport numbers too.
+	// It is only set for users who may see them unmasked.
+	Identifiers bool
 }
 
 type ResidenceCardDetails struct {
@@ -334,7 +663,7 @@ type NewEmployee struct {
 	MotherMaidenName string                  `json:"motherMaidenName"`
 	BankAccount      string                  `json:"bankAccount"`
 	AddressPoland    string                  `json:"addressPoland"`
-	HomeAddress      string                  `json:"homeAddress"`
+	HomeAddress      *string                 `json:"homeAddress"`
 	ResidenceCard    NewResidenceCardDetails `json:"residenceCard"`
 	Employment       NewEmploymentDetails    `json:"employment"`
 	Medicals         NewMedicalDetails       `json:"medicals"`
@@ -356,7 +685,7 @@ type UpdateEmployee struct {
 	MotherMaidenName string                  `json:"motherMaidenName"`
 	BankAccount      string                  `json:"bankAccount"`
 	AddressPoland    string                  `json:"addressPoland"`
-	HomeAddress      string                  `json:"homeAddress"`
+	HomeAddress      *string                 `json:"homeAddress"`
 	ResidenceCard    NewResidenceCardDetails `json:"residenceCard"`
 	Employment       NewEmploymentDetails    `json:"employment"`
 	Medicals         NewMedicalDetails       `json:"medicals"`
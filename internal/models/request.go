@@ -1,6 +1,70 @@
 package models
 
+import "time"
+
+// Fields names fields of an update by their Go names, such as "Email" or
+// "Medicals" for a group of fields. A patch writes only the fields it
+// changes; a nil Fields has every field.
+type Fields map[string]bool
+
+// Has reports whether f has the named field.
+func (f Fields) Has(name string) bool {
+	return f == nil || f[name]
+}
+
 type LoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 }
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type NewAccount struct {
+	Login      string `json:"login"`
+	Password   string `json:"password"`
+	Role       Role   `json:"role"`
+	ProjectIDs []int  `json:"project_ids"`
+}
+
+type ChangePassword struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type ResetPassword struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// Transfer moves an employee to another project. The assignment starts on
+// Date, which defaults to today.
+type Transfer struct {
+	ProjectID int   `json:"project_id"`
+	Date      *Date `json:"date,omitempty"`
+}
+
+// NewStay books a bed for an employee from CheckIn on. Without CheckOut the
+// stay is open-ended.
+type NewStay struct {
+	EmployeeID int   `json:"employee_id"`
+	CheckIn    Date  `json:"check_in"`
+	CheckOut   *Date `json:"check_out,omitempty"`
+}
+
+// CheckOut ends a stay on Date, which defaults to today.
+type CheckOut struct {
+	Date *Date `json:"date,omitempty"`
+}
+
+// NewHandover records a car changing hands at HandedAt, which defaults to
+// now. A nil employee stands for the fleet's pool.
+type NewHandover struct {
+	FromEmployeeID *int       `json:"from_employee_id,omitempty"`
+	ToEmployeeID   *int       `json:"to_employee_id,omitempty"`
+	HandedAt       *time.Time `json:"handed_at,omitempty"`
+	Odometer       int        `json:"odometer"`
+	FuelLevel      int        `json:"fuel_level"`
+	DamageNotes    string     `json:"damage_notes"`
+}
@@ -0,0 +1,180 @@
+package models
+
+// The v2 API reads and writes every resource in the shape it is returned in,
+// so that a record can be sent back with PUT after a GET. Fields that are
+// derived, such as names of related records and counts, are ignored on
+// writes.
+
+// Update returns the update setting the employee to e.
+func (e Employee) Update() UpdateEmployee {
+	return UpdateEmployee{
+		LastName:         e.LastName,
+		FirstName:        e.FirstName,
+		PassportNumber:   e.PassportNumber,
+		Pesel:            e.Pesel,
+		Email:            e.Email,
+		DateOfBirth:      e.DateOfBirth,
+		FatherName:       e.FatherName,
+		MotherName:       e.MotherName,
+		MaidenName:       e.MaidenName,
+		MotherMaidenName: e.MotherMaidenName,
+		BankAccount:      e.BankAccount,
+		AddressPoland:    e.AddressPoland,
+		HomeAddress:      e.HomeAddress,
+		ResidenceCard: NewResidenceCardDetails{
+			Bio:   NewNullableDate(e.ResidenceCard.Bio),
+			Visa:  NewNullableDate(e.ResidenceCard.Visa),
+			TCard: NewNullableDate(e.ResidenceCard.TCard),
+		},
+		Employment: NewEmploymentDetails{
+			ContractType:   e.Employment.ContractType,
+			StartDate:      e.Employment.StartDate,
+			EndDate:        NewNullableDate(e.Employment.EndDate),
+			Authorizations: e.Employment.Authorizations,
+		},
+		Medicals: NewMedicalDetails{
+			OSHValidUntil:         e.Medicals.OSHValidUntil,
+			PsychotestsValidUntil: NewNullableDate(e.Medicals.PsychotestsValidUntil),
+			MedicalValidUntil:     e.Medicals.MedicalValidUntil,
+			SanitaryValidUntil:    NewNullableDate(e.Medicals.SanitaryValidUntil),
+		},
+		ProjectId:       e.ProjectId,
+		AccommodationId: deref(e.AccommodationId),
+		CarId:           deref(e.CarId),
+	}
+}
+
+// Create returns the employee to add with the details of e.
+func (e Employee) Create() NewEmployee {
+	return NewEmployee(e.Update())
+}
+
+// Update returns the update setting the car to c.
+func (c Car) Update() UpdateCar {
+	return UpdateCar{
+		Model:              c.Model,
+		Color:              c.Color,
+		RegistrationNumber: c.RegistrationNumber,
+		VIN:                c.VIN,
+		InspectionFrom:     c.InspectionFrom,
+		InspectionTo:       c.InspectionTo,
+		InsuranceFrom:      c.InsuranceFrom,
+		InsuranceTo:        c.InsuranceTo,
+		FleetCardNumber:    c.FleetCardNumber,
+		IdProject:          c.ProjectID,
+		Service:            c.Service,
+		Leasing:            c.Leasing,
+	}
+}
+
+// Update returns the update setting the project to p.
+func (p Project) Update() UpdateProject {
+	return UpdateProject{
+		Name:          p.Name,
+		OfficeAddress: p.OfficeAddress,
+		ProjectNIP:    p.ProjectNIP,
+		FirstName:     p.FirstName,
+		LastName:      p.LastName,
+		Phone:         p.Phone,
+		Position:      p.Position,
+	}
+}
+
+// Create returns the project to add with the details of p.
+func (p Project) Create() NewProject {
+	return NewProject(p.Update())
+}
+
+// Update returns the update setting the accommodation to a.
+func (a Accommodation) Update() UpdateAccommodation {
+	return UpdateAccommodation{
+		ProjectID:            a.ProjectID,
+		City:                 a.City,
+		AccommodationAddress: a.AccommodationAddress,
+		NumberOfPlaces:       a.NumberOfPlaces,
+		Contact: UpdateContactDetails{
+			FirstName:   deref(a.Contact.FirstName),
+			LastName:    deref(a.Contact.LastName),
+			PhoneNumber: deref(a.Contact.PhoneNumber),
+		},
+		Payment: UpdatePaymentDetails{
+			Cost:          deref(a.Payment.Cost),
+			Deposit:       deref(a.Payment.Deposit),
+			Contract:      deref(a.Payment.Contract),
+			AccountNumber: deref(a.Payment.AccountNumber),
+			PaymentDay:    deref(a.Payment.PaymentDay),
+		},
+	}
+}
+
+// Create returns the accommodation to add with the details of a.
+func (a Accommodation) Create() NewAccommodation {
+	u := a.Update()
+
+	return NewAccommodation{
+		ProjectID:      u.ProjectID,
+		City:           u.City,
+		Address:        u.AccommodationAddress,
+		NumberOfPlaces: u.NumberOfPlaces,
+		Contact:        u.Contact,
+		Payment:        u.Payment,
+	}
+}
+
+// CarResource is a car in the v2 API, which spells its service and leasing
+// details in snake_case like the rest of the car.
+type CarResource struct {
+	Car
+
+	Service CarService `json:"service"`
+	Leasing CarLeasing `json:"leasing"`
+}
+
+type CarService struct {
+	ServiceName string `json:"service_name"`
+	Address     string `json:"address"`
+	PhoneNumber string `json:"phone_number"`
+}
+
+type CarLeasing struct {
+	Amount         float64 `json:"amount"`
+	MonthlyPayment float64 `json:"monthly_payment"`
+	PaymentDay     int     `json:"payment_day"`
+}
+
+func NewCarResource(c Car) CarResource {
+	return CarResource{Car: c, Service: CarService(c.Service), Leasing: CarLeasing(c.Leasing)}
+}
+
+// NewCarResources converts a page of cars.
+func NewCarResources(page Page[Car]) Page[CarResource] {
+	items := make([]CarResource, len(page.Items))
+	for i, c := range page.Items {
+		items[i] = NewCarResource(c)
+	}
+
+	return Page[CarResource]{Items: items, Total: page.Total, Limit: page.Limit, Offset: page.Offset}
+}
+
+// Update returns the update setting the car to r.
+func (r CarResource) Update() UpdateCar {
+	c := r.Car
+	c.Service, c.Leasing = Service(r.Service), Leasing(r.Leasing)
+
+	return c.Update()
+}
+
+// Create returns the car to add with the details of r.
+func (r CarResource) Create() NewCar {
+	return NewCar(r.Update())
+}
+
+// deref returns the value p points to, or the zero value if it is nil.
+func deref[T any](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+
+	return *p
+}
@@ -1,6 +1,20 @@
 package models
 
 type LoginResponse struct {
-	JWT string `json:"jwt"`
-	Exp int64  `json:"exp"`
+	JWT          string `json:"jwt"`
+	Exp          int64  `json:"exp"`
+	RefreshToken string `json:"refresh_token"`
+	RefreshExp   int64  `json:"refresh_exp"`
+}
+
+type Account struct {
+	EmployeeID int    `json:"employee_id"`
+	Login      string `json:"login"`
+	Role       Role   `json:"role"`
+	ProjectIDs []int  `json:"project_ids"`
+}
+
+type PasswordResetResponse struct {
+	Token string `json:"token"`
+	Exp   int64  `json:"exp"`
 }
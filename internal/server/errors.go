@@ -0,0 +1,173 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"api/internal/api"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/httplog/v2"
+	"github.com/pkg/errors"
+)
+
+// problemMediaType is the content type of error responses.
+const problemMediaType = "application/problem+json"
+
+// Machine-readable error codes returned in the "code" field of error
+// responses.
+const (
+	codeBadRequest       = "bad_request"
+	codeUnauthorized     = "unauthorized"
+	codeForbidden        = "forbidden"
+	codeNotFound         = "not_found"
+	codeMethodNotAllowed = "method_not_allowed"
+	codeConflict         = "conflict"
+	codeLoginTaken       = "login_taken"
+	codeReferenced       = "referenced"
+	codeWeakPassword     = "weak_password"
+	codeInvalidAccount   = "invalid_account"
+	codeInvalidTransfer  = "invalid_transfer"
+	codeTooManyAttempts  = "too_many_attempts"
+	codePrecondition     = "precondition_failed"
+	codeNoPrecondition   = "precondition_required"
+	codeArchived         = "archived"
+	codeNoVacancy        = "no_vacancy"
+	codeOverlap          = "overlapping_stay"
+	codeInvalidStay      = "invalid_stay"
+	codeDriverMismatch   = "driver_mismatch"
+	codeCarHandover      = "handover_required"
+	codeInvalidHandover  = "invalid_handover"
+	codeInvalidSort      = "invalid_sort"
+	codeInvalidPatch     = "invalid_patch"
+	codeNotNullable      = "not_nullable"
+	codeValidation       = "validation_failed"
+	codeMediaType        = "unsupported_media_type"
+	codeInternal         = "internal_error"
+)
+
+// problem is the body of error responses, an RFC 7807 problem details
+// object. Code identifies the error for clients and Violations lists the
+// fields of the request that are not valid. Message repeats Detail for
+// clients written before responses were problem details.
+type problem struct {
+	Type       string          `json:"type"`
+	Title      string          `json:"title"`
+	Status     int             `json:"status"`
+	Detail     string          `json:"detail"`
+	Instance   string          `json:"instance"`
+	Code       string          `json:"code"`
+	Message    string          `json:"message"`
+	RequestID  string          `json:"request_id,omitempty"`
+	Violations []api.Violation `json:"violations,omitempty"`
+}
+
+// writeErrorCode writes an error response with the given status and code.
+func writeErrorCode(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	writeProblem(w, r, status, code, message, nil)
+}
+
+// writeProblem writes an error response listing the fields of the request
+// that are not valid.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, code, message string, violations []api.Violation) {
+	w.Header().Set("Content-Type", problemMediaType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(problem{
+		Type:       "about:blank",
+		Title:      http.StatusText(status),
+		Status:     status,
+		Detail:     message,
+		Instance:   r.URL.Path,
+		Code:       code,
+		Message:    message,
+		RequestID:  middleware.GetReqID(r.Context()),
+		Violations: violations,
+	})
+}
+
+// writeDecodeError writes the response for a request body that could not be
+// decoded, naming the field whose value has the wrong type.
+func writeDecodeError(w http.ResponseWriter, r *http.Request, err error) {
+	var typeError *json.UnmarshalTypeError
+	var syntaxError *json.SyntaxError
+
+	switch {
+	case errors.As(err, &typeError) && typeError.Field != "":
+		writeProblem(w, r, http.StatusBadRequest, codeBadRequest, "bad request", []api.Violation{
+			{Field: typeError.Field, Message: "must be of type " + typeError.Type.String()},
+		})
+	case errors.As(err, &syntaxError), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "body is not valid JSON")
+	default:
+		writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+	}
+}
+
+// writeError writes the response for an error returned by the API service.
+// Errors that are not recognised are logged and reported as internal errors.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var lockout *api.LockoutError
+	var validation *api.ValidationError
+	var null *api.NullError
+
+	switch {
+	case errors.As(err, &lockout):
+		w.Header().Set("Retry-After", strconv.Itoa(int(lockout.RetryAfter.Seconds())+1))
+		writeErrorCode(w, r, http.StatusTooManyRequests, codeTooManyAttempts, "too many attempts")
+	case errors.Is(err, api.ErrUnauthorized):
+		writeErrorCode(w, r, http.StatusUnauthorized, codeUnauthorized, "unauthorized")
+	case errors.Is(err, api.ErrForbidden):
+		writeErrorCode(w, r, http.StatusForbidden, codeForbidden, "forbidden")
+	case errors.Is(err, api.ErrNotFound):
+		writeErrorCode(w, r, http.StatusNotFound, codeNotFound, "not found")
+	case errors.Is(err, api.ErrLoginTaken):
+		writeErrorCode(w, r, http.StatusConflict, codeLoginTaken, api.ErrLoginTaken.Error())
+	case errors.Is(err, api.ErrArchived):
+		writeErrorCode(w, r, http.StatusConflict, codeArchived, api.ErrArchived.Error())
+	case errors.Is(err, api.ErrNoVacancy):
+		writeErrorCode(w, r, http.StatusConflict, codeNoVacancy, api.ErrNoVacancy.Error())
+	case errors.Is(err, api.ErrOverlap):
+		writeErrorCode(w, r, http.StatusConflict, codeOverlap, api.ErrOverlap.Error())
+	case errors.Is(err, api.ErrDriverMismatch):
+		writeErrorCode(w, r, http.StatusConflict, codeDriverMismatch, api.ErrDriverMismatch.Error())
+	case errors.Is(err, api.ErrCarHandover):
+		writeErrorCode(w, r, http.StatusConflict, codeCarHandover, api.ErrCarHandover.Error())
+	case errors.Is(err, api.ErrConflict):
+		writeErrorCode(w, r, http.StatusConflict, codeConflict, api.ErrConflict.Error())
+	case errors.Is(err, errPreconditionRequired):
+		writeErrorCode(w, r, http.StatusPreconditionRequired, codeNoPrecondition, errPreconditionRequired.Error())
+	case errors.Is(err, api.ErrVersionMismatch):
+		writeErrorCode(w, r, http.StatusPreconditionFailed, codePrecondition, api.ErrVersionMismatch.Error())
+	case errors.Is(err, api.ErrReferenced):
+		writeErrorCode(w, r, http.StatusUnprocessableEntity, codeReferenced, api.ErrReferenced.Error())
+	case errors.Is(err, api.ErrWeakPassword):
+		writeErrorCode(w, r, http.StatusBadRequest, codeWeakPassword, err.Error())
+	case errors.Is(err, api.ErrInvalidAccount):
+		writeErrorCode(w, r, http.StatusBadRequest, codeInvalidAccount, err.Error())
+	case errors.Is(err, api.ErrInvalidTransfer):
+		writeErrorCode(w, r, http.StatusBadRequest, codeInvalidTransfer, err.Error())
+	case errors.Is(err, api.ErrInvalidStay):
+		writeErrorCode(w, r, http.StatusBadRequest, codeInvalidStay, err.Error())
+	case errors.Is(err, api.ErrInvalidHandover):
+		writeErrorCode(w, r, http.StatusBadRequest, codeInvalidHandover, err.Error())
+	case errors.As(err, &validation):
+		writeProblem(w, r, http.StatusBadRequest, codeValidation, err.Error(), validation.Violations)
+	case errors.Is(err, api.ErrInvalid):
+		writeErrorCode(w, r, http.StatusBadRequest, codeValidation, err.Error())
+	case errors.As(err, &null):
+		writeProblem(w, r, http.StatusUnprocessableEntity, codeNotNullable, err.Error(), []api.Violation{
+			{Field: null.Field, Message: "cannot be null"},
+		})
+	case errors.Is(err, api.ErrInvalidPatch):
+		writeErrorCode(w, r, http.StatusBadRequest, codeInvalidPatch, err.Error())
+	case errors.Is(err, errUnsupportedMediaType):
+		writeErrorCode(w, r, http.StatusUnsupportedMediaType, codeMediaType, errUnsupportedMediaType.Error())
+	case errors.Is(err, api.ErrInvalidSort):
+		writeErrorCode(w, r, http.StatusBadRequest, codeInvalidSort, api.ErrInvalidSort.Error())
+	default:
+		httplog.LogEntry(r.Context()).Error(err.Error())
+		writeErrorCode(w, r, http.StatusInternalServerError, codeInternal, "internal error")
+	}
+}
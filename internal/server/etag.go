@@ -0,0 +1,39 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"api/internal/api"
+	"github.com/pkg/errors"
+)
+
+// errPreconditionRequired is returned when a write lacks an If-Match header.
+var errPreconditionRequired = errors.New("If-Match header is required")
+
+// setETag sends the version of a record as a strong entity tag.
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// ifMatch reads the version a client expects to change from If-Match. Tags
+// that were not issued by setETag can never match the current version.
+func ifMatch(r *http.Request) (int, error) {
+	tag := strings.TrimSpace(r.Header.Get("If-Match"))
+	if tag == "" || tag == "*" {
+		return 0, errPreconditionRequired
+	}
+
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil {
+		return 0, errors.Wrapf(api.ErrVersionMismatch, "invalid entity tag %s", tag)
+	}
+
+	version, err := strconv.Atoi(unquoted)
+	if err != nil {
+		return 0, errors.Wrapf(api.ErrVersionMismatch, "invalid entity tag %s", tag)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"net"
+	"net/http"
+
+	"api/internal/auth"
+	"api/internal/models"
+
+	"github.com/go-chi/jwtauth"
+)
+
+// authenticate rejects requests without a valid token and stores the
+// principal described by the token claims in the request context.
+func authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, claims, err := jwtauth.FromContext(r.Context())
+		if err != nil || token == nil {
+			writeErrorCode(w, r, http.StatusUnauthorized, codeUnauthorized, "unauthorized")
+			return
+		}
+
+		username, _ := claims["username"].(string)
+		role, _ := claims["role"].(string)
+
+		var projectIDs []int
+		projects, _ := claims["projects"].([]interface{})
+		for _, project := range projects {
+			if id, ok := project.(float64); ok {
+				projectIDs = append(projectIDs, int(id))
+			}
+		}
+
+		principal := auth.Principal{
+			Username:   username,
+			Role:       models.Role(role),
+			ProjectIDs: projectIDs,
+
+			TokenID:   token.JwtID(),
+			ExpiresAt: token.Expiration(),
+		}
+
+		if principal.Username == "" || principal.TokenID == "" || !principal.Role.Valid() {
+			writeErrorCode(w, r, http.StatusUnauthorized, codeUnauthorized, "unauthorized")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), principal)))
+	})
+}
+
+// rejectRevoked refuses tokens that were revoked before their expiry, either
+// on logout or by an administrator.
+func (s *Service) rejectRevoked(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := auth.FromContext(r.Context())
+		if !ok {
+			writeErrorCode(w, r, http.StatusUnauthorized, codeUnauthorized, "unauthorized")
+			return
+		}
+
+		revoked, err := s.API.IsTokenRevoked(r.Context(), principal.TokenID)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		if revoked {
+			writeErrorCode(w, r, http.StatusUnauthorized, codeUnauthorized, "unauthorized")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorize allows the request through only if the authenticated principal
+// has one of the given roles.
+func authorize(roles ...models.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.FromContext(r.Context())
+			if !ok {
+				writeErrorCode(w, r, http.StatusUnauthorized, codeUnauthorized, "unauthorized")
+				return
+			}
+
+			if !principal.HasRole(roles...) {
+				writeErrorCode(w, r, http.StatusForbidden, codeForbidden, "forbidden")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientInfo describes the client of the request. The IP is the host part of
+// RemoteAddr, which middleware.RealIP rewrites when proxy headers are trusted.
+func clientInfo(r *http.Request) models.ClientInfo {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	return models.ClientInfo{
+		IP:        ip,
+		UserAgent: r.UserAgent(),
+	}
+}
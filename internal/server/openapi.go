@@ -0,0 +1,452 @@
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"api/internal/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// docsPage renders the OpenAPI document with Redoc.
+//
+//go:embed docs.html
+var docsPage []byte
+
+// redocFiles holds the Redoc standalone bundle loaded by docsPage, served by
+// the API itself so that the docs render on installs without internet
+// access. While it is not committed, docsPage loads Redoc from its CDN.
+//
+//go:embed redoc
+var redocFiles embed.FS
+
+// operation documents a route in the OpenAPI document. Every route of the
+// router must be listed in operations; see TestEveryRouteIsDocumented.
+type operation struct {
+	Method  string
+	Path    string
+	Tag     string
+	Summary string
+
+	// Query lists the query parameters the route reads.
+	Query []parameter
+
+	// Request is a zero value of the JSON body the route decodes, or nil if
+	// it reads none. Patch marks a body that is a merge patch of Request.
+	Request any
+	Patch   bool
+
+	// Status is the status of a successful response, whose body is shaped
+	// like Response unless it is nil.
+	Status   int
+	Response any
+
+	// Roles lists the roles allowed to call the route; any authenticated
+	// user may call it if it is empty. Public routes need no token.
+	Roles  []models.Role
+	Public bool
+
+	// IfMatch marks writes that require the version of the record from its
+	// ETag.
+	IfMatch bool
+}
+
+type parameter struct {
+	Name        string
+	Type        string
+	Format      string
+	Description string
+	Required    bool
+
+	// Sensitive marks parameters that only roles seeing identifiers unmasked
+	// may use; other users get 403.
+	Sensitive bool
+}
+
+// errorResponses describes the error responses, which all have a problem
+// details body and differ only in the status and code.
+var errorResponses = map[int]string{
+	http.StatusBadRequest:           "The request is malformed or invalid.",
+	http.StatusUnauthorized:         "The token is missing, invalid, expired or revoked.",
+	http.StatusForbidden:            "The role of the user does not allow the request.",
+	http.StatusNotFound:             "The record does not exist or is outside the projects of the user.",
+	http.StatusConflict:             "The request conflicts with the current state of the record.",
+	http.StatusPreconditionFailed:   "If-Match does not match the current version of the record.",
+	http.StatusUnsupportedMediaType: "The body is not sent as a merge patch.",
+	http.StatusUnprocessableEntity:  "The record references a missing record or is still referenced, or a patch sets a required field to null.",
+	http.StatusPreconditionRequired: "The If-Match header is missing.",
+	http.StatusTooManyRequests:      "Too many failed login attempts from the client IP; see Retry-After.",
+	http.StatusInternalServerError:  "An unexpected error occurred.",
+}
+
+// openAPIDocument returns the OpenAPI 3.1 document describing operations.
+// Schemas are generated from the types of the request and response bodies
+// so that they follow the models.
+func openAPIDocument() map[string]any {
+	schemas := schemaSet{defs: map[string]any{}}
+
+	schemas.defs["Error"] = schemas.object(reflect.TypeOf(problem{}))
+
+	responses := map[string]any{}
+	for status, description := range errorResponses {
+		responses[responseName(status)] = map[string]any{
+			"description": description,
+			"content": map[string]any{
+				problemMediaType: map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/Error"}},
+			},
+		}
+	}
+
+	paths := map[string]map[string]any{}
+	for _, op := range operations {
+		if paths[op.Path] == nil {
+			paths[op.Path] = map[string]any{}
+		}
+
+		paths[op.Path][strings.ToLower(op.Method)] = op.document(schemas)
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":   "PIC API",
+			"version": "2",
+			"description": "Routes under /v2 read and write every resource in the shape it is returned in. " +
+				"The other routes are kept for existing clients.",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas":   schemas.defs,
+			"responses": responses,
+			"securitySchemes": map[string]any{
+				"bearer": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+		"security": []any{map[string]any{"bearer": []string{}}},
+	}
+}
+
+func (op operation) document(schemas schemaSet) map[string]any {
+	doc := map[string]any{
+		"summary": op.Summary,
+		"tags":    []string{op.Tag},
+	}
+
+	var params []any
+
+	for _, name := range pathParams.FindAllStringSubmatch(op.Path, -1) {
+		p := parameter{Name: name[1], Type: "integer", Required: true}
+		if name[1] == "login" {
+			p.Type = "string"
+		}
+		params = append(params, p.document("path"))
+	}
+
+	for _, p := range op.Query {
+		params = append(params, p.document("query"))
+	}
+
+	if op.IfMatch {
+		params = append(params, parameter{
+			Name:        "If-Match",
+			Type:        "string",
+			Description: "The ETag of the record as last read.",
+			Required:    true,
+		}.document("header"))
+	}
+
+	if params != nil {
+		doc["parameters"] = params
+	}
+
+	if op.Request != nil {
+		schema := schemas.schema(reflect.TypeOf(op.Request))
+		content := jsonContent(schema)
+		if op.Patch {
+			content = map[string]any{
+				mergePatchType:     map[string]any{"schema": schema},
+				"application/json": map[string]any{"schema": schema},
+			}
+		}
+
+		doc["requestBody"] = map[string]any{"required": true, "content": content}
+	}
+
+	success := map[string]any{"description": http.StatusText(op.Status)}
+	if op.Response != nil {
+		success["content"] = jsonContent(schemas.schema(reflect.TypeOf(op.Response)))
+	}
+	if op.Response != nil && hasVersion(reflect.TypeOf(op.Response)) {
+		success["headers"] = map[string]any{
+			"ETag": map[string]any{"description": "The version of the record.", "schema": map[string]any{"type": "string"}},
+		}
+	}
+
+	responses := map[string]any{strconv.Itoa(op.Status): success}
+
+	for _, status := range op.errors() {
+		responses[strconv.Itoa(status)] = map[string]any{"$ref": "#/components/responses/" + responseName(status)}
+	}
+
+	doc["responses"] = responses
+
+	if op.Public {
+		doc["security"] = []any{}
+	}
+
+	if len(op.Roles) > 0 {
+		roles := make([]string, len(op.Roles))
+		for i, role := range op.Roles {
+			roles[i] = string(role)
+		}
+		doc["description"] = "Allowed for: " + strings.Join(roles, ", ") + "."
+	}
+
+	return doc
+}
+
+// errors returns the statuses of the error responses op may reply with.
+func (op operation) errors() []int {
+	statuses := []int{http.StatusInternalServerError}
+
+	if op.Request != nil || op.Query != nil || pathParams.MatchString(op.Path) {
+		statuses = append(statuses, http.StatusBadRequest)
+	}
+
+	if !op.Public {
+		statuses = append(statuses, http.StatusUnauthorized)
+	} else if op.Path == "/login" {
+		statuses = append(statuses, http.StatusUnauthorized, http.StatusTooManyRequests)
+	}
+
+	if len(op.Roles) > 0 || slices.ContainsFunc(op.Query, func(p parameter) bool { return p.Sensitive }) {
+		statuses = append(statuses, http.StatusForbidden)
+	}
+
+	if pathParams.MatchString(op.Path) {
+		statuses = append(statuses, http.StatusNotFound)
+	}
+
+	if !op.Public && op.Method != http.MethodGet {
+		statuses = append(statuses, http.StatusConflict, http.StatusUnprocessableEntity)
+	}
+
+	if op.IfMatch {
+		statuses = append(statuses, http.StatusPreconditionFailed, http.StatusPreconditionRequired)
+	}
+
+	if op.Patch {
+		statuses = append(statuses, http.StatusUnsupportedMediaType)
+	}
+
+	return statuses
+}
+
+var pathParams = regexp.MustCompile(`\{(\w+)\}`)
+
+// responseName names the error response with the given status.
+func responseName(status int) string {
+	return strings.ReplaceAll(http.StatusText(status), " ", "")
+}
+
+// hasVersion reports whether t is a record sent with its version as the
+// ETag.
+func hasVersion(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	_, ok := t.FieldByName("Version")
+
+	return ok
+}
+
+func (p parameter) document(in string) map[string]any {
+	schema := map[string]any{"type": p.Type}
+	if p.Format != "" {
+		schema["format"] = p.Format
+	}
+
+	doc := map[string]any{"name": p.Name, "in": in, "schema": schema}
+	if p.Description != "" {
+		doc["description"] = p.Description
+	}
+	if p.Required {
+		doc["required"] = true
+	}
+
+	return doc
+}
+
+func jsonContent(schema any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+// schemaSet generates JSON schemas for Go types the way encoding/json
+// encodes them. Named structs are added to defs and referenced.
+type schemaSet struct {
+	defs map[string]any
+}
+
+var (
+	dateType         = reflect.TypeOf(models.Date{})
+	nullableDateType = reflect.TypeOf(models.NullableDate{})
+	timeType         = reflect.TypeOf(time.Time{})
+	rawMessageType   = reflect.TypeOf(json.RawMessage{})
+)
+
+func (s schemaSet) schema(t reflect.Type) map[string]any {
+	switch t {
+	case dateType:
+		return map[string]any{"type": "string", "format": "date"}
+	case nullableDateType:
+		return map[string]any{"type": []string{"string", "null"}, "format": "date"}
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(s.schema(t.Elem()))
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": s.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": s.schema(t.Elem())}
+	case reflect.Struct:
+		name := schemaName(t)
+		if _, ok := s.defs[name]; !ok {
+			s.defs[name] = nil // guards against recursive types
+			s.defs[name] = s.object(t)
+		}
+
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+
+	return map[string]any{}
+}
+
+// object returns the schema of a struct. Fields of embedded structs are
+// promoted unless a field of the same name is declared closer to t.
+func (s schemaSet) object(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	s.addFields(properties, t, map[string]int{}, 0)
+
+	return map[string]any{"type": "object", "properties": properties}
+}
+
+func (s schemaSet) addFields(properties map[string]any, t reflect.Type, depths map[string]int, depth int) {
+	var embedded []reflect.Type
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded = append(embedded, field.Type)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if d, ok := depths[name]; ok && d <= depth {
+			continue
+		}
+
+		depths[name] = depth
+		properties[name] = s.schema(field.Type)
+	}
+
+	for _, e := range embedded {
+		s.addFields(properties, e, depths, depth+1)
+	}
+}
+
+func nullable(schema map[string]any) map[string]any {
+	if typ, ok := schema["type"].(string); ok {
+		schema["type"] = []string{typ, "null"}
+		return schema
+	}
+
+	if _, ok := schema["$ref"]; ok {
+		return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+	}
+
+	return schema
+}
+
+var packagePath = regexp.MustCompile(`[\w./-]+\.`)
+
+// schemaName names the schema of a struct after its type; an instance of a
+// generic type is named after its type argument, so Page[Car] is CarPage.
+func schemaName(t reflect.Type) string {
+	name := packagePath.ReplaceAllString(t.Name(), "")
+
+	if generic, args, ok := strings.Cut(name, "["); ok {
+		return strings.NewReplacer(",", "", "]", "").Replace(args) + generic
+	}
+
+	return name
+}
+
+// serveOpenAPI serves the OpenAPI document and a page rendering it with the
+// embedded Redoc bundle.
+func serveOpenAPI(router chi.Router) {
+	// The document only holds maps, slices and strings, which always encode.
+	document, _ := json.Marshal(openAPIDocument())
+
+	router.Get("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(document)
+	})
+
+	router.Get("/docs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(docsPage)
+	})
+
+	// A missing bundle answers 404, on which docsPage falls back to the CDN.
+	bundle, err := redocFiles.ReadFile("redoc/redoc.standalone.js")
+
+	router.Get("/docs/redoc.standalone.js", func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(bundle)
+	})
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth"
+)
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	s := &Service{TokenAuth: jwtauth.New("HS256", []byte("secret"), nil)}
+
+	documented := make(map[string]bool)
+	for _, op := range operations {
+		key := op.Method + " " + op.Path
+		if documented[key] {
+			t.Errorf("%s is documented twice", key)
+		}
+		documented[key] = true
+	}
+
+	routed := make(map[string]bool)
+
+	err := chi.Walk(s.Handler().(chi.Routes), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		key := method + " " + strings.TrimSuffix(route, "/")
+		routed[key] = true
+
+		if !documented[key] {
+			t.Errorf("%s is not documented in operations", key)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key := range documented {
+		if !routed[key] {
+			t.Errorf("%s is documented but not routed", key)
+		}
+	}
+}
+
+func TestOpenAPIReferencesResolve(t *testing.T) {
+	b, err := json.Marshal(openAPIDocument())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var document struct {
+		Components map[string]map[string]any `json:"components"`
+	}
+	if err = json.Unmarshal(b, &document); err != nil {
+		t.Fatal(err)
+	}
+
+	var check func(v any)
+	check = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				kind, name, _ := strings.Cut(strings.TrimPrefix(ref, "#/components/"), "/")
+				if document.Components[kind][name] == nil {
+					t.Errorf("%s does not resolve", ref)
+				}
+			}
+			for _, v := range v {
+				check(v)
+			}
+		case []any:
+			for _, v := range v {
+				check(v)
+			}
+		}
+	}
+
+	var all any
+	_ = json.Unmarshal(b, &all)
+	check(all)
+}
+
+// TestRedocBundle checks that the only file served from redoc is the Redoc
+// standalone bundle, so that a placeholder or a truncated download never
+// replaces the docs.
+func TestRedocBundle(t *testing.T) {
+	entries, err := redocFiles.ReadDir("redoc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		switch entry.Name() {
+		case "README.md":
+		case "redoc.standalone.js":
+			bundle, err := redocFiles.ReadFile("redoc/" + entry.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The bundle is about 1 MB and exports the Redoc global.
+			if len(bundle) < 500_000 || !bytes.Contains(bundle, []byte("Redoc")) {
+				t.Errorf("redoc/redoc.standalone.js is not the Redoc bundle (%d bytes)", len(bundle))
+			}
+		default:
+			t.Errorf("redoc/%s is not used", entry.Name())
+		}
+	}
+}
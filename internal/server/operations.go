@@ -0,0 +1,280 @@
+package server
+
+import (
+	"net/http"
+
+	"api/internal/models"
+)
+
+var (
+	writers = []models.Role{models.RoleAdmin, models.RoleCoordinator}
+	admins  = []models.Role{models.RoleAdmin}
+)
+
+// Query parameters read by parseListFilter and the filters built on it.
+var (
+	listParams = []parameter{
+		{Name: "include_archived", Type: "boolean", Description: "Lists archived records along with active ones."},
+		{Name: "sort", Type: "string", Description: "Comma-separated fields to sort by, named as in the records; a leading - sorts in descending order."},
+		{Name: "limit", Type: "integer", Description: "The size of the page, from 1 to 1000. Defaults to 100."},
+		{Name: "offset", Type: "integer", Description: "The number of records to skip."},
+	}
+
+	projectIDParam      = parameter{Name: "project_id", Type: "integer", Description: "Lists records of the project."}
+	expiringBeforeParam = parameter{Name: "expiring_before", Type: "string", Format: "date", Description: "Lists records with a document expiring before the day."}
+	asOfParam           = parameter{Name: "as_of", Type: "string", Format: "date", Description: "The day headcounts are reported for. Defaults to today."}
+
+	employeeParams = append([]parameter{
+		{Name: "pesel", Type: "string", Sensitive: true, Description: "Lists the employee with the PESEL. Not allowed to read-only users."},
+		{Name: "passport_number", Type: "string", Sensitive: true, Description: "Lists the employee with the passport number. Not allowed to read-only users."},
+		{Name: "contract_type", Type: "string"},
+		projectIDParam,
+		expiringBeforeParam,
+	}, listParams...)
+	searchParams = append([]parameter{
+		{Name: "q", Type: "string", Required: true, Description: "Names, e-mail addresses or identifiers, in any spelling or script. Identifiers are not matched for read-only users."},
+	}, listParams...)
+	carParams           = append([]parameter{projectIDParam, expiringBeforeParam}, listParams...)
+	accommodationParams = append([]parameter{{Name: "city", Type: "string"}, projectIDParam}, listParams...)
+	projectParams       = append([]parameter{asOfParam}, listParams...)
+
+	driverParams = []parameter{
+		{Name: "at", Type: "string", Description: "Lists whoever had the car at the time, given as RFC 3339 or, for the whole day, as YYYY-MM-DD."},
+	}
+	auditParams = []parameter{
+		{Name: "entity", Type: "string"},
+		{Name: "entity_id", Type: "integer"},
+		{Name: "user", Type: "string"},
+		{Name: "from", Type: "string", Description: "RFC 3339 or YYYY-MM-DD."},
+		{Name: "to", Type: "string", Description: "RFC 3339 or YYYY-MM-DD, which includes the whole day."},
+		{Name: "limit", Type: "integer"},
+	}
+)
+
+// operations documents every route of Handler.
+var operations = []operation{
+	{Method: http.MethodPost, Path: "/login", Tag: "Auth", Summary: "Log in", Public: true,
+		Request: models.LoginRequest{}, Status: http.StatusOK, Response: models.LoginResponse{}},
+	{Method: http.MethodPost, Path: "/token/refresh", Tag: "Auth", Summary: "Refresh a token", Public: true,
+		Request: models.RefreshTokenRequest{}, Status: http.StatusOK, Response: models.LoginResponse{}},
+	{Method: http.MethodPost, Path: "/password/reset", Tag: "Auth", Summary: "Reset a password with a reset token", Public: true,
+		Request: models.ResetPassword{}, Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/logout", Tag: "Auth", Summary: "Log out, revoking the token",
+		Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/account/password", Tag: "Auth", Summary: "Change the password of the user",
+		Request: models.ChangePassword{}, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/openapi.json", Tag: "Docs", Summary: "This document", Public: true,
+		Status: http.StatusOK},
+	{Method: http.MethodGet, Path: "/docs", Tag: "Docs", Summary: "This document rendered as HTML", Public: true,
+		Status: http.StatusOK},
+	{Method: http.MethodGet, Path: "/docs/redoc.standalone.js", Tag: "Docs", Summary: "The Redoc script rendering the docs", Public: true,
+		Status: http.StatusOK},
+
+	{Method: http.MethodPost, Path: "/employee/{id}/account", Tag: "Users", Summary: "Create an account for an employee", Roles: admins,
+		Request: models.NewAccount{}, Status: http.StatusCreated, Response: models.Account{}},
+	{Method: http.MethodPost, Path: "/user/{login}/password-reset", Tag: "Users", Summary: "Issue a password reset token", Roles: admins,
+		Status: http.StatusCreated, Response: models.PasswordResetResponse{}},
+	{Method: http.MethodPost, Path: "/user/{login}/unlock", Tag: "Users", Summary: "Unlock a user locked out after failed logins", Roles: admins,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPut, Path: "/user/{login}/projects", Tag: "Users", Summary: "Set the projects of a coordinator", Roles: admins,
+		Request: []int{}, Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/user/{login}/revoke", Tag: "Users", Summary: "Revoke all tokens of a user", Roles: admins,
+		Status: http.StatusNoContent},
+
+	{Method: http.MethodGet, Path: "/dashboard", Tag: "Dashboard", Summary: "Get the dashboard",
+		Query: []parameter{asOfParam}, Status: http.StatusOK, Response: models.Dashboard{}},
+	{Method: http.MethodGet, Path: "/audit", Tag: "Audit", Summary: "List changes to records", Roles: admins,
+		Query: auditParams, Status: http.StatusOK, Response: []models.AuditEntry{}},
+
+	{Method: http.MethodGet, Path: "/cars", Tag: "Cars", Summary: "List cars",
+		Query: carParams, Status: http.StatusOK, Response: models.Page[models.Car]{}},
+	{Method: http.MethodGet, Path: "/car/{id}", Tag: "Cars", Summary: "Get a car",
+		Status: http.StatusOK, Response: models.Car{}},
+	{Method: http.MethodGet, Path: "/car/numbers", Tag: "Cars", Summary: "List registration numbers",
+		Status: http.StatusOK, Response: []models.CarNumbers{}},
+	{Method: http.MethodPost, Path: "/car", Tag: "Cars", Summary: "Add a car", Roles: writers,
+		Request: models.NewCar{}, Status: http.StatusCreated, Response: models.Car{}},
+	{Method: http.MethodPost, Path: "/car/{id}/update", Tag: "Cars", Summary: "Update a car", Roles: writers, IfMatch: true,
+		Request: models.UpdateCar{}, Status: http.StatusOK, Response: models.Car{}},
+	{Method: http.MethodPatch, Path: "/car/{id}", Tag: "Cars", Summary: "Patch the update of a car", Roles: writers, IfMatch: true,
+		Request: models.UpdateCar{}, Patch: true, Status: http.StatusOK, Response: models.Car{}},
+	{Method: http.MethodDelete, Path: "/car/{id}", Tag: "Cars", Summary: "Archive a car", Roles: writers, IfMatch: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/car/{id}/restore", Tag: "Cars", Summary: "Restore an archived car", Roles: writers, IfMatch: true,
+		Status: http.StatusOK, Response: models.Car{}},
+	{Method: http.MethodGet, Path: "/car/{id}/drivers", Tag: "Cars", Summary: "List the drivers of a car",
+		Query: driverParams, Status: http.StatusOK, Response: []models.CarDriver{}},
+	{Method: http.MethodGet, Path: "/car/{id}/handovers", Tag: "Cars", Summary: "List the handovers of a car",
+		Status: http.StatusOK, Response: []models.CarHandover{}},
+	{Method: http.MethodPost, Path: "/car/{id}/handovers", Tag: "Cars", Summary: "Record a handover of a car", Roles: writers,
+		Request: models.NewHandover{}, Status: http.StatusCreated, Response: models.CarHandover{}},
+
+	{Method: http.MethodGet, Path: "/projects", Tag: "Projects", Summary: "List projects",
+		Query: projectParams, Status: http.StatusOK, Response: models.Page[models.Project]{}},
+	{Method: http.MethodGet, Path: "/project/names", Tag: "Projects", Summary: "List project names",
+		Status: http.StatusOK, Response: []models.ProjectNames{}},
+	{Method: http.MethodGet, Path: "/project/{id}", Tag: "Projects", Summary: "Get a project",
+		Status: http.StatusOK, Response: models.Project{}},
+	{Method: http.MethodPost, Path: "/project", Tag: "Projects", Summary: "Add a project", Roles: admins,
+		Request: models.NewProject{}, Status: http.StatusCreated, Response: models.Project{}},
+	{Method: http.MethodPost, Path: "/project/{id}/update", Tag: "Projects", Summary: "Update a project", Roles: writers, IfMatch: true,
+		Request: models.UpdateProject{}, Status: http.StatusOK, Response: models.Project{}},
+	{Method: http.MethodPatch, Path: "/project/{id}", Tag: "Projects", Summary: "Patch the update of a project", Roles: writers, IfMatch: true,
+		Request: models.UpdateProject{}, Patch: true, Status: http.StatusOK, Response: models.Project{}},
+	{Method: http.MethodDelete, Path: "/project/{id}", Tag: "Projects", Summary: "Archive a project", Roles: admins, IfMatch: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/project/{id}/restore", Tag: "Projects", Summary: "Restore an archived project", Roles: admins, IfMatch: true,
+		Status: http.StatusOK, Response: models.Project{}},
+
+	{Method: http.MethodGet, Path: "/accommodations", Tag: "Accommodations", Summary: "List accommodations",
+		Query: accommodationParams, Status: http.StatusOK, Response: models.Page[models.Accommodation]{}},
+	{Method: http.MethodGet, Path: "/accommodation/{id}", Tag: "Accommodations", Summary: "Get an accommodation",
+		Status: http.StatusOK, Response: models.Accommodation{}},
+	{Method: http.MethodGet, Path: "/accommodation/addresses", Tag: "Accommodations", Summary: "List accommodation addresses",
+		Query: []parameter{asOfParam}, Status: http.StatusOK, Response: []models.AccommodationAddresses{}},
+	{Method: http.MethodPost, Path: "/accommodation", Tag: "Accommodations", Summary: "Add an accommodation", Roles: writers,
+		Request: models.NewAccommodation{}, Status: http.StatusCreated, Response: models.Accommodation{}},
+	{Method: http.MethodPost, Path: "/accommodation/{id}/update", Tag: "Accommodations", Summary: "Update an accommodation", Roles: writers, IfMatch: true,
+		Request: models.UpdateAccommodation{}, Status: http.StatusOK, Response: models.Accommodation{}},
+	{Method: http.MethodPatch, Path: "/accommodation/{id}", Tag: "Accommodations", Summary: "Patch the update of an accommodation", Roles: writers, IfMatch: true,
+		Request: models.UpdateAccommodation{}, Patch: true, Status: http.StatusOK, Response: models.Accommodation{}},
+	{Method: http.MethodDelete, Path: "/accommodation/{id}", Tag: "Accommodations", Summary: "Archive an accommodation", Roles: writers, IfMatch: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/accommodation/{id}/restore", Tag: "Accommodations", Summary: "Restore an archived accommodation", Roles: writers, IfMatch: true,
+		Status: http.StatusOK, Response: models.Accommodation{}},
+	{Method: http.MethodGet, Path: "/accommodation/{id}/stays", Tag: "Stays", Summary: "List the stays in an accommodation",
+		Status: http.StatusOK, Response: []models.Stay{}},
+	{Method: http.MethodPost, Path: "/accommodation/{id}/stays", Tag: "Stays", Summary: "Check an employee in or book a stay", Roles: writers,
+		Request: models.NewStay{}, Status: http.StatusCreated, Response: models.Stay{}},
+	{Method: http.MethodPost, Path: "/stay/{id}/checkout", Tag: "Stays", Summary: "Check out of a stay", Roles: writers,
+		Request: models.CheckOut{}, Status: http.StatusOK, Response: models.Stay{}},
+	{Method: http.MethodDelete, Path: "/stay/{id}", Tag: "Stays", Summary: "Cancel a stay", Roles: writers,
+		Status: http.StatusNoContent},
+
+	{Method: http.MethodGet, Path: "/employees", Tag: "Employees", Summary: "List employees",
+		Query: employeeParams, Status: http.StatusOK, Response: models.Page[models.Employee]{}},
+	{Method: http.MethodGet, Path: "/employees/search", Tag: "Employees", Summary: "Search employees",
+		Query: searchParams, Status: http.StatusOK, Response: models.Page[models.Employee]{}},
+	{Method: http.MethodGet, Path: "/employee/{id}", Tag: "Employees", Summary: "Get an employee",
+		Status: http.StatusOK, Response: models.Employee{}},
+	{Method: http.MethodPost, Path: "/employee", Tag: "Employees", Summary: "Add an employee", Roles: writers,
+		Request: models.NewEmployee{}, Status: http.StatusCreated, Response: models.Employee{}},
+	{Method: http.MethodPost, Path: "/employee/{id}/update", Tag: "Employees", Summary: "Update an employee", Roles: writers, IfMatch: true,
+		Request: models.UpdateEmployee{}, Status: http.StatusOK, Response: models.Employee{}},
+	{Method: http.MethodPatch, Path: "/employee/{id}", Tag: "Employees", Summary: "Patch the update of an employee", Roles: writers, IfMatch: true,
+		Request: models.UpdateEmployee{}, Patch: true, Status: http.StatusOK, Response: models.Employee{}},
+	{Method: http.MethodDelete, Path: "/employee/{id}", Tag: "Employees", Summary: "Archive an employee", Roles: writers, IfMatch: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/employee/{id}/restore", Tag: "Employees", Summary: "Restore an archived employee", Roles: writers, IfMatch: true,
+		Status: http.StatusOK, Response: models.Employee{}},
+	{Method: http.MethodGet, Path: "/employee/{id}/assignments", Tag: "Employees", Summary: "List the project assignments of an employee",
+		Status: http.StatusOK, Response: []models.ProjectAssignment{}},
+	{Method: http.MethodGet, Path: "/employee/{id}/stays", Tag: "Stays", Summary: "List the stays of an employee",
+		Status: http.StatusOK, Response: []models.Stay{}},
+	{Method: http.MethodPost, Path: "/employee/{id}/transfer", Tag: "Employees", Summary: "Transfer an employee to another project", Roles: writers, IfMatch: true,
+		Request: models.Transfer{}, Status: http.StatusOK, Response: models.Employee{}},
+
+	{Method: http.MethodGet, Path: "/v2/dashboard", Tag: "Dashboard", Summary: "Get the dashboard",
+		Query: []parameter{asOfParam}, Status: http.StatusOK, Response: models.Dashboard{}},
+	{Method: http.MethodGet, Path: "/v2/audit", Tag: "Audit", Summary: "List changes to records", Roles: admins,
+		Query: auditParams, Status: http.StatusOK, Response: []models.AuditEntry{}},
+
+	{Method: http.MethodGet, Path: "/v2/cars", Tag: "Cars", Summary: "List cars",
+		Query: carParams, Status: http.StatusOK, Response: models.Page[models.CarResource]{}},
+	{Method: http.MethodGet, Path: "/v2/cars/numbers", Tag: "Cars", Summary: "List registration numbers",
+		Status: http.StatusOK, Response: []models.CarNumbers{}},
+	{Method: http.MethodGet, Path: "/v2/cars/{id}", Tag: "Cars", Summary: "Get a car",
+		Status: http.StatusOK, Response: models.CarResource{}},
+	{Method: http.MethodPost, Path: "/v2/cars", Tag: "Cars", Summary: "Add a car", Roles: writers,
+		Request: models.CarResource{}, Status: http.StatusCreated, Response: models.CarResource{}},
+	{Method: http.MethodPut, Path: "/v2/cars/{id}", Tag: "Cars", Summary: "Replace a car", Roles: writers, IfMatch: true,
+		Request: models.CarResource{}, Status: http.StatusOK, Response: models.CarResource{}},
+	{Method: http.MethodPatch, Path: "/v2/cars/{id}", Tag: "Cars", Summary: "Patch a car", Roles: writers, IfMatch: true,
+		Request: models.CarResource{}, Patch: true, Status: http.StatusOK, Response: models.CarResource{}},
+	{Method: http.MethodDelete, Path: "/v2/cars/{id}", Tag: "Cars", Summary: "Archive a car", Roles: writers, IfMatch: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/v2/cars/{id}/restore", Tag: "Cars", Summary: "Restore an archived car", Roles: writers, IfMatch: true,
+		Status: http.StatusOK, Response: models.CarResource{}},
+	{Method: http.MethodGet, Path: "/v2/cars/{id}/drivers", Tag: "Cars", Summary: "List the drivers of a car",
+		Query: driverParams, Status: http.StatusOK, Response: []models.CarDriver{}},
+	{Method: http.MethodGet, Path: "/v2/cars/{id}/handovers", Tag: "Cars", Summary: "List the handovers of a car",
+		Status: http.StatusOK, Response: []models.CarHandover{}},
+	{Method: http.MethodPost, Path: "/v2/cars/{id}/handovers", Tag: "Cars", Summary: "Record a handover of a car", Roles: writers,
+		Request: models.NewHandover{}, Status: http.StatusCreated, Response: models.CarHandover{}},
+
+	{Method: http.MethodGet, Path: "/v2/projects", Tag: "Projects", Summary: "List projects",
+		Query: projectParams, Status: http.StatusOK, Response: models.Page[models.Project]{}},
+	{Method: http.MethodGet, Path: "/v2/projects/names", Tag: "Projects", Summary: "List project names",
+		Status: http.StatusOK, Response: []models.ProjectNames{}},
+	{Method: http.MethodGet, Path: "/v2/projects/{id}", Tag: "Projects", Summary: "Get a project",
+		Status: http.StatusOK, Response: models.Project{}},
+	{Method: http.MethodPost, Path: "/v2/projects", Tag: "Projects", Summary: "Add a project", Roles: admins,
+		Request: models.Project{}, Status: http.StatusCreated, Response: models.Project{}},
+	{Method: http.MethodPut, Path: "/v2/projects/{id}", Tag: "Projects", Summary: "Replace a project", Roles: writers, IfMatch: true,
+		Request: models.Project{}, Status: http.StatusOK, Response: models.Project{}},
+	{Method: http.MethodPatch, Path: "/v2/projects/{id}", Tag: "Projects", Summary: "Patch a project", Roles: writers, IfMatch: true,
+		Request: models.Project{}, Patch: true, Status: http.StatusOK, Response: models.Project{}},
+	{Method: http.MethodDelete, Path: "/v2/projects/{id}", Tag: "Projects", Summary: "Archive a project", Roles: admins, IfMatch: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/v2/projects/{id}/restore", Tag: "Projects", Summary: "Restore an archived project", Roles: admins, IfMatch: true,
+		Status: http.StatusOK, Response: models.Project{}},
+
+	{Method: http.MethodGet, Path: "/v2/accommodations", Tag: "Accommodations", Summary: "List accommodations",
+		Query: accommodationParams, Status: http.StatusOK, Response: models.Page[models.Accommodation]{}},
+	{Method: http.MethodGet, Path: "/v2/accommodations/addresses", Tag: "Accommodations", Summary: "List accommodation addresses",
+		Query: []parameter{asOfParam}, Status: http.StatusOK, Response: []models.AccommodationAddresses{}},
+	{Method: http.MethodGet, Path: "/v2/accommodations/{id}", Tag: "Accommodations", Summary: "Get an accommodation",
+		Status: http.StatusOK, Response: models.Accommodation{}},
+	{Method: http.MethodPost, Path: "/v2/accommodations", Tag: "Accommodations", Summary: "Add an accommodation", Roles: writers,
+		Request: models.Accommodation{}, Status: http.StatusCreated, Response: models.Accommodation{}},
+	{Method: http.MethodPut, Path: "/v2/accommodations/{id}", Tag: "Accommodations", Summary: "Replace an accommodation", Roles: writers, IfMatch: true,
+		Request: models.Accommodation{}, Status: http.StatusOK, Response: models.Accommodation{}},
+	{Method: http.MethodPatch, Path: "/v2/accommodations/{id}", Tag: "Accommodations", Summary: "Patch an accommodation", Roles: writers, IfMatch: true,
+		Request: models.Accommodation{}, Patch: true, Status: http.StatusOK, Response: models.Accommodation{}},
+	{Method: http.MethodDelete, Path: "/v2/accommodations/{id}", Tag: "Accommodations", Summary: "Archive an accommodation", Roles: writers, IfMatch: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/v2/accommodations/{id}/restore", Tag: "Accommodations", Summary: "Restore an archived accommodation", Roles: writers, IfMatch: true,
+		Status: http.StatusOK, Response: models.Accommodation{}},
+	{Method: http.MethodGet, Path: "/v2/accommodations/{id}/stays", Tag: "Stays", Summary: "List the stays in an accommodation",
+		Status: http.StatusOK, Response: []models.Stay{}},
+	{Method: http.MethodPost, Path: "/v2/accommodations/{id}/stays", Tag: "Stays", Summary: "Check an employee in or book a stay", Roles: writers,
+		Request: models.NewStay{}, Status: http.StatusCreated, Response: models.Stay{}},
+	{Method: http.MethodPost, Path: "/v2/stays/{id}/checkout", Tag: "Stays", Summary: "Check out of a stay", Roles: writers,
+		Request: models.CheckOut{}, Status: http.StatusOK, Response: models.Stay{}},
+	{Method: http.MethodDelete, Path: "/v2/stays/{id}", Tag: "Stays", Summary: "Cancel a stay", Roles: writers,
+		Status: http.StatusNoContent},
+
+	{Method: http.MethodGet, Path: "/v2/employees", Tag: "Employees", Summary: "List employees",
+		Query: employeeParams, Status: http.StatusOK, Response: models.Page[models.Employee]{}},
+	{Method: http.MethodGet, Path: "/v2/employees/search", Tag: "Employees", Summary: "Search employees",
+		Query: searchParams, Status: http.StatusOK, Response: models.Page[models.Employee]{}},
+	{Method: http.MethodGet, Path: "/v2/employees/{id}", Tag: "Employees", Summary: "Get an employee",
+		Status: http.StatusOK, Response: models.Employee{}},
+	{Method: http.MethodPost, Path: "/v2/employees", Tag: "Employees", Summary: "Add an employee", Roles: writers,
+		Request: models.Employee{}, Status: http.StatusCreated, Response: models.Employee{}},
+	{Method: http.MethodPut, Path: "/v2/employees/{id}", Tag: "Employees", Summary: "Replace an employee", Roles: writers, IfMatch: true,
+		Request: models.Employee{}, Status: http.StatusOK, Response: models.Employee{}},
+	{Method: http.MethodPatch, Path: "/v2/employees/{id}", Tag: "Employees", Summary: "Patch an employee", Roles: writers, IfMatch: true,
+		Request: models.Employee{}, Patch: true, Status: http.StatusOK, Response: models.Employee{}},
+	{Method: http.MethodDelete, Path: "/v2/employees/{id}", Tag: "Employees", Summary: "Archive an employee", Roles: writers, IfMatch: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/v2/employees/{id}/restore", Tag: "Employees", Summary: "Restore an archived employee", Roles: writers, IfMatch: true,
+		Status: http.StatusOK, Response: models.Employee{}},
+	{Method: http.MethodGet, Path: "/v2/employees/{id}/assignments", Tag: "Employees", Summary: "List the project assignments of an employee",
+		Status: http.StatusOK, Response: []models.ProjectAssignment{}},
+	{Method: http.MethodGet, Path: "/v2/employees/{id}/stays", Tag: "Stays", Summary: "List the stays of an employee",
+		Status: http.StatusOK, Response: []models.Stay{}},
+	{Method: http.MethodPost, Path: "/v2/employees/{id}/transfers", Tag: "Employees", Summary: "Transfer an employee to another project", Roles: writers, IfMatch: true,
+		Request: models.Transfer{}, Status: http.StatusOK, Response: models.Employee{}},
+	{Method: http.MethodPost, Path: "/v2/employees/{id}/account", Tag: "Users", Summary: "Create an account for an employee", Roles: admins,
+		Request: models.NewAccount{}, Status: http.StatusCreated, Response: models.Account{}},
+
+	{Method: http.MethodPost, Path: "/v2/users/{login}/password-reset", Tag: "Users", Summary: "Issue a password reset token", Roles: admins,
+		Status: http.StatusCreated, Response: models.PasswordResetResponse{}},
+	{Method: http.MethodPost, Path: "/v2/users/{login}/unlock", Tag: "Users", Summary: "Unlock a user locked out after failed logins", Roles: admins,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPut, Path: "/v2/users/{login}/projects", Tag: "Users", Summary: "Set the projects of a coordinator", Roles: admins,
+		Request: []int{}, Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/v2/users/{login}/revoke", Tag: "Users", Summary: "Revoke all tokens of a user", Roles: admins,
+		Status: http.StatusNoContent},
+}
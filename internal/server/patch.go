@@ -0,0 +1,27 @@
+package server
+
+import (
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// mergePatchType is the media type of RFC 7396 merge patches.
+const mergePatchType = "application/merge-patch+json"
+
+var errUnsupportedMediaType = errors.New("patch must be sent as " + mergePatchType)
+
+// readMergePatch reads the body of a PATCH request. Plain JSON is accepted as
+// a merge patch too.
+func readMergePatch(r *http.Request) ([]byte, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (mediaType != mergePatchType && mediaType != "application/json") {
+		return nil, errUnsupportedMediaType
+	}
+
+	patch, err := io.ReadAll(r.Body)
+
+	return patch, errors.Wrap(err, "failed to read patch")
+}
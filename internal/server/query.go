@@ -0,0 +1,281 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"api/internal/models"
+	"github.com/pkg/errors"
+)
+
+// parseAuditFilter reads the filter of GET /audit. Dates are accepted either
+// as RFC 3339 timestamps or as YYYY-MM-DD; a bare "to" date includes the
+// whole day.
+func parseAuditFilter(r *http.Request) (models.AuditFilter, error) {
+	q := r.URL.Query()
+
+	filter := models.AuditFilter{
+		EntityType: models.EntityType(q.Get("entity")),
+		Username:   q.Get("user"),
+	}
+
+	var err error
+
+	if v := q.Get("entity_id"); v != "" {
+		if filter.EntityID, err = strconv.Atoi(v); err != nil {
+			return filter, errors.New("invalid entity_id")
+		}
+	}
+
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			return filter, errors.New("invalid limit")
+		}
+	}
+
+	if v := q.Get("from"); v != "" {
+		from, _, err := parseTime(v)
+		if err != nil {
+			return filter, errors.New("invalid from")
+		}
+		filter.From = &from
+	}
+
+	if v := q.Get("to"); v != "" {
+		to, dateOnly, err := parseTime(v)
+		if err != nil {
+			return filter, errors.New("invalid to")
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.To = &to
+	}
+
+	return filter, nil
+}
+
+func parseTime(v string) (t time.Time, dateOnly bool, err error) {
+	if t, err = time.Parse(time.DateOnly, v); err == nil {
+		return t, true, nil
+	}
+
+	t, err = time.Parse(time.RFC3339, v)
+
+	return t, false, err
+}
+
+// Page sizes of the list endpoints.
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+// parseListFilter reads the options shared by the list endpoints. The list
+// is sorted by the comma-separated fields in "sort", each prefixed with "-"
+// to sort in descending order, and paginated by "limit" and "offset".
+func parseListFilter(r *http.Request) (models.ListFilter, error) {
+	q := r.URL.Query()
+
+	filter := models.ListFilter{Limit: defaultLimit}
+
+	if v := q.Get("include_archived"); v != "" {
+		includeArchived, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, errors.New("invalid include_archived")
+		}
+		filter.IncludeArchived = includeArchived
+	}
+
+	if v := q.Get("sort"); v != "" {
+		for _, field := range strings.Split(v, ",") {
+			field, descending := strings.CutPrefix(strings.TrimSpace(field), "-")
+			if field == "" {
+				return filter, errors.New("invalid sort")
+			}
+			filter.Sort = append(filter.Sort, models.SortKey{Field: field, Descending: descending})
+		}
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxLimit {
+			return filter, errors.Errorf("limit must be between 1 and %d", maxLimit)
+		}
+		filter.Limit = limit
+	}
+
+	if v := q.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return filter, errors.New("invalid offset")
+		}
+		filter.Offset = offset
+	}
+
+	return filter, nil
+}
+
+// parseEmployeeFilter reads the filter of GET /employees.
+func parseEmployeeFilter(r *http.Request) (models.EmployeeFilter, error) {
+	q := r.URL.Query()
+
+	filter := models.EmployeeFilter{
+		Pesel:          q.Get("pesel"),
+		PassportNumber: q.Get("passport_number"),
+		ContractType:   q.Get("contract_type"),
+	}
+
+	var err error
+
+	if filter.ListFilter, err = parseListFilter(r); err != nil {
+		return filter, err
+	}
+
+	if filter.ProjectID, err = parseProjectID(r); err != nil {
+		return filter, err
+	}
+
+	filter.ExpiringBefore, err = parseExpiringBefore(r)
+
+	return filter, err
+}
+
+// parseEmployeeSearch reads the query of GET /employees/search.
+func parseEmployeeSearch(r *http.Request) (models.EmployeeSearch, error) {
+	search := models.EmployeeSearch{Query: strings.TrimSpace(r.URL.Query().Get("q"))}
+	if search.Query == "" {
+		return search, errors.New("q is required")
+	}
+
+	var err error
+
+	search.ListFilter, err = parseListFilter(r)
+
+	return search, err
+}
+
+// parseCarFilter reads the filter of GET /cars.
+func parseCarFilter(r *http.Request) (models.CarFilter, error) {
+	var (
+		filter models.CarFilter
+		err    error
+	)
+
+	if filter.ListFilter, err = parseListFilter(r); err != nil {
+		return filter, err
+	}
+
+	if filter.ProjectID, err = parseProjectID(r); err != nil {
+		return filter, err
+	}
+
+	filter.ExpiringBefore, err = parseExpiringBefore(r)
+
+	return filter, err
+}
+
+// parseAccommodationFilter reads the filter of GET /accommodations.
+func parseAccommodationFilter(r *http.Request) (models.AccommodationFilter, error) {
+	filter := models.AccommodationFilter{City: r.URL.Query().Get("city")}
+
+	var err error
+
+	if filter.ListFilter, err = parseListFilter(r); err != nil {
+		return filter, err
+	}
+
+	filter.ProjectID, err = parseProjectID(r)
+
+	return filter, err
+}
+
+// parseProjectID reads the project a list is narrowed down to. It is 0 if
+// not set.
+func parseProjectID(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("project_id")
+	if v == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, errors.New("invalid project_id")
+	}
+
+	return id, nil
+}
+
+// parseExpiringBefore reads the day by which documents must be renewed,
+// given as YYYY-MM-DD.
+func parseExpiringBefore(r *http.Request) (*models.Date, error) {
+	v := r.URL.Query().Get("expiring_before")
+	if v == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return nil, errors.New("invalid expiring_before")
+	}
+
+	day := models.Date(t)
+
+	return &day, nil
+}
+
+// parseAsOf reads the day headcounts are reported for, given as YYYY-MM-DD.
+// It is zero if not set.
+func parseAsOf(r *http.Request) (models.Date, error) {
+	v := r.URL.Query().Get("as_of")
+	if v == "" {
+		return models.Date{}, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return models.Date{}, errors.New("invalid as_of")
+	}
+
+	return models.Date(t), nil
+}
+
+// parseProjectFilter reads the filter of GET /projects.
+func parseProjectFilter(r *http.Request) (models.ProjectFilter, error) {
+	listFilter, err := parseListFilter(r)
+	if err != nil {
+		return models.ProjectFilter{}, err
+	}
+
+	asOf, err := parseAsOf(r)
+
+	return models.ProjectFilter{ListFilter: listFilter, AsOf: asOf}, err
+}
+
+// parseDriverFilter reads the filter of GET /car/{id}/drivers. The time in
+// "at" is given either as an RFC 3339 timestamp or as YYYY-MM-DD, which
+// matches whoever had the car at some time that day.
+func parseDriverFilter(r *http.Request) (models.DriverFilter, error) {
+	var filter models.DriverFilter
+
+	v := r.URL.Query().Get("at")
+	if v == "" {
+		return filter, nil
+	}
+
+	at, dateOnly, err := parseTime(v)
+	if err != nil {
+		return filter, errors.New("invalid at")
+	}
+
+	to := at
+	if dateOnly {
+		to = at.AddDate(0, 0, 1).Add(-time.Second)
+	}
+
+	filter.From, filter.To = &at, &to
+
+	return filter, nil
+}
@@ -3,12 +3,14 @@ package server
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
 
 	"api/internal/api"
+	"api/internal/auth"
 	"api/internal/models"
 	"api/internal/storage"
 	"github.com/pkg/errors"
@@ -24,12 +26,12 @@ type Service struct {
 	Config Config
 
 	TokenAuth *jwtauth.JWTAuth
-	Storage   storage.Service
+	Storage   storage.Repository
 	API       api.ServiceInterface
 }
 
-func NewService() (*Service, error) {
-	svc := &Service{}
+func NewService(repo storage.Repository) (*Service, error) {
+	svc := &Service{Storage: repo}
 
 	cfg, err := readConfig()
 	if err != nil {
@@ -38,17 +40,12 @@ func NewService() (*Service, error) {
 
 	svc.Config = cfg
 
-	svc.Storage, err = storage.New()
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create storage service")
-	}
-
-	svc.API, err = api.New()
+	svc.API, err = api.New(repo)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create API service")
 	}
 
-	svc.TokenAuth = jwtauth.New("HS256", cfg.JWTSecret, nil)
+	svc.TokenAuth = jwtauth.New("HS256", []byte(cfg.JWTSecret), nil)
 
 	fmt.Println("Server initialized successfully!")
 
@@ -80,591 +77,2110 @@ func (s *Service) Handler() http.Handler {
 	router.Use(cors.Handler(cors.Options{
 		//AllowedOrigins: []string{}, // Use this to allow specific origin hosts
 		AllowOriginFunc:  func(r *http.Request, origin string) bool { return true },
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "If-Match", "X-CSRF-Token"},
+		ExposedHeaders:   []string{"ETag", "Link"},
 		AllowCredentials: true,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
+	if s.Config.TrustProxyHeaders {
+		router.Use(middleware.RealIP)
+	}
 	router.Use(httplog.RequestLogger(logger, []string{"/ping"}))
 	router.Use(middleware.Heartbeat("/ping"))
 
+	router.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		writeErrorCode(w, r, http.StatusNotFound, codeNotFound, "not found")
+	})
+	router.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		writeErrorCode(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+	})
+
 	// Router for routes requiring authorization
 	router.Group(func(r chi.Router) {
 		r.Use(jwtauth.Verifier(s.TokenAuth))
+		r.Use(authenticate)
+		r.Use(s.rejectRevoked)
 
-		r.Get("/dashboard", func(w http.ResponseWriter, r *http.Request) {
-			resp, err := s.API.Dashboard(r.Context())
-			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
-				return
-			}
-
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			_ = json.NewEncoder(w).Encode(resp)
-		})
+		r.Post("/logout", func(w http.ResponseWriter, r *http.Request) {
+			principal, _ := auth.FromContext(r.Context())
 
-		r.Get("/cars", func(w http.ResponseWriter, r *http.Request) {
-			cars, err := s.API.Cars(r.Context())
+			err := s.API.Logout(r.Context(), principal.TokenID, principal.ExpiresAt)
 			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
 				return
 			}
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			_ = json.NewEncoder(w).Encode(cars)
+			w.WriteHeader(http.StatusNoContent)
 		})
 
-		r.Get("/car/{id}", func(w http.ResponseWriter, r *http.Request) {
-			stringId := chi.URLParam(r, "id")
-
-			if stringId == "" {
-				http.Error(w, "id is required", http.StatusBadRequest)
-				return
-			}
+		r.Post("/account/password", func(w http.ResponseWriter, r *http.Request) {
+			var changePassword models.ChangePassword
 
-			id, err := strconv.Atoi(stringId)
+			err := json.NewDecoder(r.Body).Decode(&changePassword)
 			if err != nil {
 				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+				writeDecodeError(w, r, err)
 				return
 			}
 
-			car, err := s.API.GetCar(r.Context(), id)
+			principal, _ := auth.FromContext(r.Context())
+
+			err = s.API.ChangePassword(r.Context(), principal.Username, changePassword)
 			if err != nil {
-				// TODO 404
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
 				return
 			}
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			_ = json.NewEncoder(w).Encode(car)
+			w.WriteHeader(http.StatusNoContent)
 		})
 
-		r.Get("/car/numbers", func(w http.ResponseWriter, r *http.Request) {
-			projects, err := s.API.GetCarNumbers(r.Context())
+		createAccount := func(w http.ResponseWriter, r *http.Request) {
+			var newAccount models.NewAccount
+
+			err := json.NewDecoder(r.Body).Decode(&newAccount)
 			if err != nil {
 				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeDecodeError(w, r, err)
 				return
 			}
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			_ = json.NewEncoder(w).Encode(projects)
-		})
-
-		r.Delete("/car/{id}", func(w http.ResponseWriter, r *http.Request) {
 			stringId := chi.URLParam(r, "id")
 
 			if stringId == "" {
-				http.Error(w, "id is required", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
 				return
 			}
 
 			id, err := strconv.Atoi(stringId)
 			if err != nil {
 				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
 				return
 			}
 
-			err = s.API.RemoveCar(r.Context(), id)
+			account, err := s.API.CreateAccount(r.Context(), id, newAccount)
 			if err != nil {
-				// TODO 404
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
 				return
 			}
 
-			w.WriteHeader(http.StatusNoContent)
-		})
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(account)
+		}
 
-		r.Post("/car", func(w http.ResponseWriter, r *http.Request) {
-			var newCar models.NewCar
+		r.With(authorize(models.RoleAdmin)).Post("/employee/{id}/account", createAccount)
 
-			err := json.NewDecoder(r.Body).Decode(&newCar)
-			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+		resetPassword := func(w http.ResponseWriter, r *http.Request) {
+			login := chi.URLParam(r, "login")
+
+			if login == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "login is required")
 				return
 			}
 
-			car, err := s.API.AddCar(r.Context(), newCar)
+			resp, err := s.API.IssuePasswordReset(r.Context(), login)
 			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
 				return
 			}
 
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusCreated)
-			_ = json.NewEncoder(w).Encode(car)
-		})
-
-		r.Post("/car/{id}/update", func(w http.ResponseWriter, r *http.Request) {
-			var updateCar models.UpdateCar
+			_ = json.NewEncoder(w).Encode(resp)
+		}
 
-			err := json.NewDecoder(r.Body).Decode(&updateCar)
-			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
-				return
-			}
+		r.With(authorize(models.RoleAdmin)).Post("/user/{login}/password-reset", resetPassword)
 
-			stringId := chi.URLParam(r, "id")
+		unlockUser := func(w http.ResponseWriter, r *http.Request) {
+			login := chi.URLParam(r, "login")
 
-			if stringId == "" {
-				http.Error(w, "id is required", http.StatusBadRequest)
+			if login == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "login is required")
 				return
 			}
 
-			id, err := strconv.Atoi(stringId)
+			err := s.API.UnlockUser(r.Context(), login)
 			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+				writeError(w, r, err)
 				return
 			}
 
-			car, err := s.API.UpdateCar(r.Context(), id, updateCar)
+			w.WriteHeader(http.StatusNoContent)
+		}
+
+		r.With(authorize(models.RoleAdmin)).Post("/user/{login}/unlock", unlockUser)
+
+		setUserProjects := func(w http.ResponseWriter, r *http.Request) {
+			var projectIDs []int
+
+			err := json.NewDecoder(r.Body).Decode(&projectIDs)
 			if err != nil {
 				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeDecodeError(w, r, err)
 				return
 			}
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			_ = json.NewEncoder(w).Encode(car)
-		})
+			login := chi.URLParam(r, "login")
 
-		r.Get("/projects", func(w http.ResponseWriter, r *http.Request) {
-			projects, err := s.API.Projects(r.Context())
-			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+			if login == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "login is required")
 				return
 			}
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			_ = json.NewEncoder(w).Encode(projects)
-		})
-
-		r.Get("/project/names", func(w http.ResponseWriter, r *http.Request) {
-			projects, err := s.API.GetProjectNames(r.Context())
+			err = s.API.SetUserProjects(r.Context(), login, projectIDs)
 			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
 				return
 			}
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			_ = json.NewEncoder(w).Encode(projects)
-		})
+			w.WriteHeader(http.StatusNoContent)
+		}
 
-		r.Get("/project/{id}", func(w http.ResponseWriter, r *http.Request) {
-			stringId := chi.URLParam(r, "id")
+		r.With(authorize(models.RoleAdmin)).Put("/user/{login}/projects", setUserProjects)
 
-			if stringId == "" {
-				http.Error(w, "id is required", http.StatusBadRequest)
+		revokeUser := func(w http.ResponseWriter, r *http.Request) {
+			login := chi.URLParam(r, "login")
+
+			if login == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "login is required")
 				return
 			}
 
-			id, err := strconv.Atoi(stringId)
+			err := s.API.RevokeUserTokens(r.Context(), login)
 			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+				writeError(w, r, err)
 				return
 			}
 
-			project, err := s.API.GetProject(r.Context(), id)
+			w.WriteHeader(http.StatusNoContent)
+		}
+
+		r.With(authorize(models.RoleAdmin)).Post("/user/{login}/revoke", revokeUser)
+
+		dashboard := func(w http.ResponseWriter, r *http.Request) {
+			asOf, err := parseAsOf(r)
 			if err != nil {
-				// TODO 404
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, err.Error())
+				return
+			}
+
+			resp, err := s.API.Dashboard(r.Context(), asOf)
+			if err != nil {
+				writeError(w, r, err)
 				return
 			}
 
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
-			_ = json.NewEncoder(w).Encode(project)
-		})
+			_ = json.NewEncoder(w).Encode(resp)
+		}
 
-		r.Post("/project", func(w http.ResponseWriter, r *http.Request) {
-			var newProject models.NewProject
+		r.Get("/dashboard", dashboard)
 
-			err := json.NewDecoder(r.Body).Decode(&newProject)
+		audit := func(w http.ResponseWriter, r *http.Request) {
+			filter, err := parseAuditFilter(r)
 			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, err.Error())
 				return
 			}
 
-			project, err := s.API.AddProject(r.Context(), newProject)
+			entries, err := s.API.AuditLog(r.Context(), filter)
 			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
 				return
 			}
 
 			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusCreated)
-			_ = json.NewEncoder(w).Encode(project)
-		})
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(entries)
+		}
 
-		r.Post("/project/{id}/update", func(w http.ResponseWriter, r *http.Request) {
-			var updateProject models.UpdateProject
+		r.With(authorize(models.RoleAdmin)).Get("/audit", audit)
 
-			err := json.NewDecoder(r.Body).Decode(&updateProject)
+		r.Get("/cars", func(w http.ResponseWriter, r *http.Request) {
+			filter, err := parseCarFilter(r)
 			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, err.Error())
 				return
 			}
 
+			cars, err := s.API.Cars(r.Context(), filter)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(cars)
+		})
+
+		r.Get("/car/{id}", func(w http.ResponseWriter, r *http.Request) {
 			stringId := chi.URLParam(r, "id")
 
 			if stringId == "" {
-				http.Error(w, "id is required", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
 				return
 			}
 
 			id, err := strconv.Atoi(stringId)
 			if err != nil {
 				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
 				return
 			}
 
-			car, err := s.API.UpdateProject(r.Context(), id, updateProject)
+			car, err := s.API.GetCar(r.Context(), id)
 			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
 				return
 			}
 
+			setETag(w, car.Version)
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
 			_ = json.NewEncoder(w).Encode(car)
 		})
 
-		r.Delete("/project/{id}", func(w http.ResponseWriter, r *http.Request) {
+		carNumbers := func(w http.ResponseWriter, r *http.Request) {
+			projects, err := s.API.GetCarNumbers(r.Context())
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(projects)
+		}
+
+		r.Get("/car/numbers", carNumbers)
+
+		removeCar := func(w http.ResponseWriter, r *http.Request) {
 			stringId := chi.URLParam(r, "id")
 
 			if stringId == "" {
-				http.Error(w, "id is required", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
 				return
 			}
 
 			id, err := strconv.Atoi(stringId)
 			if err != nil {
 				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
 				return
 			}
 
-			err = s.API.RemoveProject(r.Context(), id)
+			version, err := ifMatch(r)
 			if err != nil {
-				// TODO 404
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
 				return
 			}
 
-			w.WriteHeader(http.StatusNoContent)
-		})
-
-		r.Get("/accommodations", func(w http.ResponseWriter, r *http.Request) {
-			accommodations, err := s.API.Accommodations(r.Context())
+			err = s.API.RemoveCar(r.Context(), id, version)
 			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
 				return
 			}
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			_ = json.NewEncoder(w).Encode(accommodations)
-		})
+			w.WriteHeader(http.StatusNoContent)
+		}
 
-		r.Get("/accommodation/{id}", func(w http.ResponseWriter, r *http.Request) {
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Delete("/car/{id}", removeCar)
+
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/car/{id}/restore", func(w http.ResponseWriter, r *http.Request) {
 			stringId := chi.URLParam(r, "id")
 
 			if stringId == "" {
-				http.Error(w, "id is required", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
 				return
 			}
 
 			id, err := strconv.Atoi(stringId)
 			if err != nil {
 				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
 				return
 			}
 
-			accommodation, err := s.API.GetAccommodation(r.Context(), id)
+			version, err := ifMatch(r)
 			if err != nil {
-				// TODO 404
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
 				return
 			}
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			_ = json.NewEncoder(w).Encode(accommodation)
-		})
-
-		r.Get("/accommodation/addresses", func(w http.ResponseWriter, r *http.Request) {
-			addresses, err := s.API.GetAccommodationAddresses(r.Context())
+			car, err := s.API.RestoreCar(r.Context(), id, version)
 			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
 				return
 			}
 
+			setETag(w, car.Version)
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
-			_ = json.NewEncoder(w).Encode(addresses)
+			_ = json.NewEncoder(w).Encode(car)
 		})
 
-		r.Post("/accommodation", func(w http.ResponseWriter, r *http.Request) {
-			var newAcc models.NewAccommodation
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/car", func(w http.ResponseWriter, r *http.Request) {
+			var newCar models.NewCar
 
-			err := json.NewDecoder(r.Body).Decode(&newAcc)
+			err := json.NewDecoder(r.Body).Decode(&newCar)
 			if err != nil {
 				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+				writeDecodeError(w, r, err)
 				return
 			}
 
-			acc, err := s.API.AddAccommodation(r.Context(), newAcc)
+			car, err := s.API.AddCar(r.Context(), newCar)
 			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
 				return
 			}
 
+			setETag(w, car.Version)
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusCreated)
-			_ = json.NewEncoder(w).Encode(acc)
+			_ = json.NewEncoder(w).Encode(car)
 		})
 
-		r.Post("/accommodation/{id}/update", func(w http.ResponseWriter, r *http.Request) {
-			var updateAccommodation models.UpdateAccommodation
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/car/{id}/update", func(w http.ResponseWriter, r *http.Request) {
+			var updateCar models.UpdateCar
 
-			err := json.NewDecoder(r.Body).Decode(&updateAccommodation)
+			err := json.NewDecoder(r.Body).Decode(&updateCar)
 			if err != nil {
 				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+				writeDecodeError(w, r, err)
 				return
 			}
 
 			stringId := chi.URLParam(r, "id")
 
 			if stringId == "" {
-				http.Error(w, "id is required", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
 				return
 			}
 
 			id, err := strconv.Atoi(stringId)
 			if err != nil {
 				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
 				return
 			}
 
-			car, err := s.API.UpdateAccommodation(r.Context(), id, updateAccommodation)
+			version, err := ifMatch(r)
 			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
+				return
+			}
+
+			car, err := s.API.UpdateCar(r.Context(), id, version, updateCar)
+			if err != nil {
+				writeError(w, r, err)
 				return
 			}
 
+			setETag(w, car.Version)
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
 			_ = json.NewEncoder(w).Encode(car)
 		})
 
-		r.Delete("/accommodation/{id}", func(w http.ResponseWriter, r *http.Request) {
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Patch("/car/{id}", func(w http.ResponseWriter, r *http.Request) {
 			stringId := chi.URLParam(r, "id")
 
 			if stringId == "" {
-				http.Error(w, "id is required", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
 				return
 			}
 
 			id, err := strconv.Atoi(stringId)
 			if err != nil {
 				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
 				return
 			}
 
-			err = s.API.RemoveAccommodation(r.Context(), id)
+			version, err := ifMatch(r)
 			if err != nil {
-				// TODO 404
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
 				return
 			}
 
-			w.WriteHeader(http.StatusNoContent)
-		})
+			patch, err := readMergePatch(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
 
-		r.Get("/employees", func(w http.ResponseWriter, r *http.Request) {
-			employees, err := s.API.Employees(r.Context())
+			car, err := s.API.PatchCar(r.Context(), id, version, patch)
 			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
 				return
 			}
 
+			setETag(w, car.Version)
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
-			_ = json.NewEncoder(w).Encode(employees)
+			_ = json.NewEncoder(w).Encode(car)
 		})
 
-		r.Get("/employee/{id}", func(w http.ResponseWriter, r *http.Request) {
+		carDrivers := func(w http.ResponseWriter, r *http.Request) {
+			filter, err := parseDriverFilter(r)
+			if err != nil {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, err.Error())
+				return
+			}
+
 			stringId := chi.URLParam(r, "id")
 
 			if stringId == "" {
-				http.Error(w, "id is required", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
 				return
 			}
 
 			id, err := strconv.Atoi(stringId)
 			if err != nil {
 				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
 				return
 			}
 
-			employee, err := s.API.GetEmployee(r.Context(), id)
+			drivers, err := s.API.CarDrivers(r.Context(), id, filter)
 			if err != nil {
-				// TODO 404
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
 				return
 			}
 
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
-			_ = json.NewEncoder(w).Encode(employee)
-		})
+			_ = json.NewEncoder(w).Encode(drivers)
+		}
 
-		r.Post("/employee", func(w http.ResponseWriter, r *http.Request) {
-			var newEmployee models.NewEmployee
+		r.Get("/car/{id}/drivers", carDrivers)
 
-			err := json.NewDecoder(r.Body).Decode(&newEmployee)
+		carHandovers := func(w http.ResponseWriter, r *http.Request) {
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
 			if err != nil {
 				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
 				return
 			}
 
-			employee, err := s.API.AddEmployee(r.Context(), newEmployee)
+			handovers, err := s.API.CarHandovers(r.Context(), id)
 			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
 				return
 			}
 
 			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusCreated)
-			_ = json.NewEncoder(w).Encode(employee)
-		})
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(handovers)
+		}
 
-		r.Post("/employee/{id}/update", func(w http.ResponseWriter, r *http.Request) {
-			var updateEmployee models.UpdateEmployee
+		r.Get("/car/{id}/handovers", carHandovers)
 
-			err := json.NewDecoder(r.Body).Decode(&updateEmployee)
+		addHandover := func(w http.ResponseWriter, r *http.Request) {
+			var newHandover models.NewHandover
+
+			err := json.NewDecoder(r.Body).Decode(&newHandover)
 			if err != nil {
 				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+				writeDecodeError(w, r, err)
 				return
 			}
 
 			stringId := chi.URLParam(r, "id")
 
 			if stringId == "" {
-				http.Error(w, "id is required", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
 				return
 			}
 
 			id, err := strconv.Atoi(stringId)
 			if err != nil {
 				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
 				return
 			}
 
-			employee, err := s.API.UpdateEmployee(r.Context(), id, updateEmployee)
+			handover, err := s.API.AddHandover(r.Context(), id, newHandover)
 			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
 				return
 			}
 
 			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			_ = json.NewEncoder(w).Encode(employee)
-		})
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(handover)
+		}
 
-		r.Delete("/employee/{id}", func(w http.ResponseWriter, r *http.Request) {
-			stringId := chi.URLParam(r, "id")
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/car/{id}/handovers", addHandover)
 
-			if stringId == "" {
-				http.Error(w, "id is required", http.StatusBadRequest)
+		listProjects := func(w http.ResponseWriter, r *http.Request) {
+			filter, err := parseProjectFilter(r)
+			if err != nil {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, err.Error())
 				return
 			}
 
-			id, err := strconv.Atoi(stringId)
+			projects, err := s.API.Projects(r.Context(), filter)
 			if err != nil {
-				logger.Error(err.Error())
-				http.Error(w, "bad request", http.StatusBadRequest)
+				writeError(w, r, err)
 				return
 			}
 
-			err = s.API.RemoveEmployee(r.Context(), id)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(projects)
+		}
+
+		r.Get("/projects", listProjects)
+
+		projectNames := func(w http.ResponseWriter, r *http.Request) {
+			projects, err := s.API.GetProjectNames(r.Context())
 			if err != nil {
-				// TODO 404
-				logger.Error(err.Error())
-				http.Error(w, "internal error", http.StatusInternalServerError)
+				writeError(w, r, err)
 				return
 			}
 
-			w.WriteHeader(http.StatusNoContent)
-		})
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(projects)
+		}
 
-	})
+		r.Get("/project/names", projectNames)
 
-	router.Post("/login", func(w http.ResponseWriter, r *http.Request) {
-		var req models.LoginRequest
+		getProject := func(w http.ResponseWriter, r *http.Request) {
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			project, err := s.API.GetProject(r.Context(), id)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			setETag(w, project.Version)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(project)
+		}
+
+		r.Get("/project/{id}", getProject)
+
+		r.With(authorize(models.RoleAdmin)).Post("/project", func(w http.ResponseWriter, r *http.Request) {
+			var newProject models.NewProject
+
+			err := json.NewDecoder(r.Body).Decode(&newProject)
+			if err != nil {
+				logger.Error(err.Error())
+				writeDecodeError(w, r, err)
+				return
+			}
+
+			project, err := s.API.AddProject(r.Context(), newProject)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			setETag(w, project.Version)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(project)
+		})
+
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/project/{id}/update", func(w http.ResponseWriter, r *http.Request) {
+			var updateProject models.UpdateProject
+
+			err := json.NewDecoder(r.Body).Decode(&updateProject)
+			if err != nil {
+				logger.Error(err.Error())
+				writeDecodeError(w, r, err)
+				return
+			}
+
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			version, err := ifMatch(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			car, err := s.API.UpdateProject(r.Context(), id, version, updateProject)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			setETag(w, car.Version)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(car)
+		})
+
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Patch("/project/{id}", func(w http.ResponseWriter, r *http.Request) {
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			version, err := ifMatch(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			patch, err := readMergePatch(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			project, err := s.API.PatchProject(r.Context(), id, version, patch)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			setETag(w, project.Version)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(project)
+		})
+
+		removeProject := func(w http.ResponseWriter, r *http.Request) {
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			version, err := ifMatch(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			err = s.API.RemoveProject(r.Context(), id, version)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		}
+
+		r.With(authorize(models.RoleAdmin)).Delete("/project/{id}", removeProject)
+
+		restoreProject := func(w http.ResponseWriter, r *http.Request) {
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			version, err := ifMatch(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			project, err := s.API.RestoreProject(r.Context(), id, version)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			setETag(w, project.Version)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(project)
+		}
+
+		r.With(authorize(models.RoleAdmin)).Post("/project/{id}/restore", restoreProject)
+
+		listAccommodations := func(w http.ResponseWriter, r *http.Request) {
+			filter, err := parseAccommodationFilter(r)
+			if err != nil {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, err.Error())
+				return
+			}
+
+			accommodations, err := s.API.Accommodations(r.Context(), filter)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(accommodations)
+		}
+
+		r.Get("/accommodations", listAccommodations)
+
+		getAccommodation := func(w http.ResponseWriter, r *http.Request) {
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			accommodation, err := s.API.GetAccommodation(r.Context(), id)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			setETag(w, accommodation.Version)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(accommodation)
+		}
+
+		r.Get("/accommodation/{id}", getAccommodation)
+
+		accommodationAddresses := func(w http.ResponseWriter, r *http.Request) {
+			asOf, err := parseAsOf(r)
+			if err != nil {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, err.Error())
+				return
+			}
+
+			addresses, err := s.API.GetAccommodationAddresses(r.Context(), asOf)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(addresses)
+		}
+
+		r.Get("/accommodation/addresses", accommodationAddresses)
+
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/accommodation", func(w http.ResponseWriter, r *http.Request) {
+			var newAcc models.NewAccommodation
+
+			err := json.NewDecoder(r.Body).Decode(&newAcc)
+			if err != nil {
+				logger.Error(err.Error())
+				writeDecodeError(w, r, err)
+				return
+			}
+
+			acc, err := s.API.AddAccommodation(r.Context(), newAcc)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			setETag(w, acc.Version)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(acc)
+		})
+
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/accommodation/{id}/update", func(w http.ResponseWriter, r *http.Request) {
+			var updateAccommodation models.UpdateAccommodation
+
+			err := json.NewDecoder(r.Body).Decode(&updateAccommodation)
+			if err != nil {
+				logger.Error(err.Error())
+				writeDecodeError(w, r, err)
+				return
+			}
+
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			version, err := ifMatch(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			car, err := s.API.UpdateAccommodation(r.Context(), id, version, updateAccommodation)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			setETag(w, car.Version)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(car)
+		})
+
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Patch("/accommodation/{id}", func(w http.ResponseWriter, r *http.Request) {
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			version, err := ifMatch(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			patch, err := readMergePatch(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			accommodation, err := s.API.PatchAccommodation(r.Context(), id, version, patch)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			setETag(w, accommodation.Version)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(accommodation)
+		})
+
+		removeAccommodation := func(w http.ResponseWriter, r *http.Request) {
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			version, err := ifMatch(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			err = s.API.RemoveAccommodation(r.Context(), id, version)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		}
+
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Delete("/accommodation/{id}", removeAccommodation)
+
+		restoreAccommodation := func(w http.ResponseWriter, r *http.Request) {
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			version, err := ifMatch(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			accommodation, err := s.API.RestoreAccommodation(r.Context(), id, version)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			setETag(w, accommodation.Version)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(accommodation)
+		}
+
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/accommodation/{id}/restore", restoreAccommodation)
+
+		accommodationStays := func(w http.ResponseWriter, r *http.Request) {
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			stays, err := s.API.AccommodationStays(r.Context(), id)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(stays)
+		}
+
+		r.Get("/accommodation/{id}/stays", accommodationStays)
+
+		addStay := func(w http.ResponseWriter, r *http.Request) {
+			var newStay models.NewStay
+
+			err := json.NewDecoder(r.Body).Decode(&newStay)
+			if err != nil {
+				logger.Error(err.Error())
+				writeDecodeError(w, r, err)
+				return
+			}
+
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			stay, err := s.API.AddStay(r.Context(), id, newStay)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(stay)
+		}
+
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/accommodation/{id}/stays", addStay)
+
+		checkOut := func(w http.ResponseWriter, r *http.Request) {
+			var checkOut models.CheckOut
+
+			err := json.NewDecoder(r.Body).Decode(&checkOut)
+			if err != nil && !errors.Is(err, io.EOF) {
+				logger.Error(err.Error())
+				writeDecodeError(w, r, err)
+				return
+			}
+
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			stay, err := s.API.CheckOutStay(r.Context(), id, checkOut)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(stay)
+		}
+
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/stay/{id}/checkout", checkOut)
+
+		removeStay := func(w http.ResponseWriter, r *http.Request) {
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			err = s.API.CancelStay(r.Context(), id)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		}
+
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Delete("/stay/{id}", removeStay)
+
+		listEmployees := func(w http.ResponseWriter, r *http.Request) {
+			filter, err := parseEmployeeFilter(r)
+			if err != nil {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, err.Error())
+				return
+			}
+
+			employees, err := s.API.Employees(r.Context(), filter)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(employees)
+		}
+
+		r.Get("/employees", listEmployees)
+
+		searchEmployees := func(w http.ResponseWriter, r *http.Request) {
+			search, err := parseEmployeeSearch(r)
+			if err != nil {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, err.Error())
+				return
+			}
+
+			employees, err := s.API.SearchEmployees(r.Context(), search)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(employees)
+		}
+
+		r.Get("/employees/search", searchEmployees)
+
+		getEmployee := func(w http.ResponseWriter, r *http.Request) {
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			employee, err := s.API.GetEmployee(r.Context(), id)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			setETag(w, employee.Version)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(employee)
+		}
+
+		r.Get("/employee/{id}", getEmployee)
+
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/employee", func(w http.ResponseWriter, r *http.Request) {
+			var newEmployee models.NewEmployee
+
+			err := json.NewDecoder(r.Body).Decode(&newEmployee)
+			if err != nil {
+				logger.Error(err.Error())
+				writeDecodeError(w, r, err)
+				return
+			}
+
+			employee, err := s.API.AddEmployee(r.Context(), newEmployee)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			setETag(w, employee.Version)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(employee)
+		})
+
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/employee/{id}/update", func(w http.ResponseWriter, r *http.Request) {
+			var updateEmployee models.UpdateEmployee
+
+			err := json.NewDecoder(r.Body).Decode(&updateEmployee)
+			if err != nil {
+				logger.Error(err.Error())
+				writeDecodeError(w, r, err)
+				return
+			}
+
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			version, err := ifMatch(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			employee, err := s.API.UpdateEmployee(r.Context(), id, version, updateEmployee)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			setETag(w, employee.Version)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(employee)
+		})
+
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Patch("/employee/{id}", func(w http.ResponseWriter, r *http.Request) {
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			version, err := ifMatch(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			patch, err := readMergePatch(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			employee, err := s.API.PatchEmployee(r.Context(), id, version, patch)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			setETag(w, employee.Version)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(employee)
+		})
+
+		removeEmployee := func(w http.ResponseWriter, r *http.Request) {
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			version, err := ifMatch(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			err = s.API.RemoveEmployee(r.Context(), id, version)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		}
+
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Delete("/employee/{id}", removeEmployee)
+
+		employeeAssignments := func(w http.ResponseWriter, r *http.Request) {
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			assignments, err := s.API.EmployeeAssignments(r.Context(), id)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(assignments)
+		}
+
+		r.Get("/employee/{id}/assignments", employeeAssignments)
+
+		employeeStays := func(w http.ResponseWriter, r *http.Request) {
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			stays, err := s.API.EmployeeStays(r.Context(), id)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(stays)
+		}
+
+		r.Get("/employee/{id}/stays", employeeStays)
+
+		transferEmployee := func(w http.ResponseWriter, r *http.Request) {
+			var transfer models.Transfer
+
+			err := json.NewDecoder(r.Body).Decode(&transfer)
+			if err != nil {
+				logger.Error(err.Error())
+				writeDecodeError(w, r, err)
+				return
+			}
+
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			version, err := ifMatch(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			employee, err := s.API.TransferEmployee(r.Context(), id, version, transfer)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			setETag(w, employee.Version)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(employee)
+		}
+
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/employee/{id}/transfer", transferEmployee)
+
+		restoreEmployee := func(w http.ResponseWriter, r *http.Request) {
+			stringId := chi.URLParam(r, "id")
+
+			if stringId == "" {
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+				return
+			}
+
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				logger.Error(err.Error())
+				writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+				return
+			}
+
+			version, err := ifMatch(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			employee, err := s.API.RestoreEmployee(r.Context(), id, version)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			setETag(w, employee.Version)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(employee)
+		}
+
+		r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/employee/{id}/restore", restoreEmployee)
+
+		// The v2 API names resources in the plural, uses the standard verbs and
+		// reads and writes each resource in a single JSON shape.
+		r.Route("/v2", func(r chi.Router) {
+			r.Get("/dashboard", dashboard)
+			r.With(authorize(models.RoleAdmin)).Get("/audit", audit)
+
+			listCars := func(w http.ResponseWriter, r *http.Request) {
+				filter, err := parseCarFilter(r)
+				if err != nil {
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, err.Error())
+					return
+				}
+
+				cars, err := s.API.Cars(r.Context(), filter)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				_ = json.NewEncoder(w).Encode(models.NewCarResources(cars))
+			}
+
+			getCar := func(w http.ResponseWriter, r *http.Request) {
+				stringId := chi.URLParam(r, "id")
+
+				if stringId == "" {
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+					return
+				}
+
+				id, err := strconv.Atoi(stringId)
+				if err != nil {
+					logger.Error(err.Error())
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+					return
+				}
+
+				car, err := s.API.GetCar(r.Context(), id)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				setETag(w, car.Version)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				_ = json.NewEncoder(w).Encode(models.NewCarResource(car))
+			}
+
+			createCar := func(w http.ResponseWriter, r *http.Request) {
+				var newCar models.CarResource
+
+				err := json.NewDecoder(r.Body).Decode(&newCar)
+				if err != nil {
+					logger.Error(err.Error())
+					writeDecodeError(w, r, err)
+					return
+				}
+
+				car, err := s.API.AddCar(r.Context(), newCar.Create())
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				setETag(w, car.Version)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(models.NewCarResource(car))
+			}
+
+			replaceCar := func(w http.ResponseWriter, r *http.Request) {
+				var updateCar models.CarResource
+
+				err := json.NewDecoder(r.Body).Decode(&updateCar)
+				if err != nil {
+					logger.Error(err.Error())
+					writeDecodeError(w, r, err)
+					return
+				}
+
+				stringId := chi.URLParam(r, "id")
+
+				if stringId == "" {
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+					return
+				}
+
+				id, err := strconv.Atoi(stringId)
+				if err != nil {
+					logger.Error(err.Error())
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+					return
+				}
+
+				version, err := ifMatch(r)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				car, err := s.API.UpdateCar(r.Context(), id, version, updateCar.Update())
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				setETag(w, car.Version)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				_ = json.NewEncoder(w).Encode(models.NewCarResource(car))
+			}
+
+			patchCar := func(w http.ResponseWriter, r *http.Request) {
+				stringId := chi.URLParam(r, "id")
+
+				if stringId == "" {
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+					return
+				}
+
+				id, err := strconv.Atoi(stringId)
+				if err != nil {
+					logger.Error(err.Error())
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+					return
+				}
+
+				version, err := ifMatch(r)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				patch, err := readMergePatch(r)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				car, err := s.API.PatchCarResource(r.Context(), id, version, patch)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				setETag(w, car.Version)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				_ = json.NewEncoder(w).Encode(models.NewCarResource(car))
+			}
+
+			restoreCar := func(w http.ResponseWriter, r *http.Request) {
+				stringId := chi.URLParam(r, "id")
+
+				if stringId == "" {
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+					return
+				}
+
+				id, err := strconv.Atoi(stringId)
+				if err != nil {
+					logger.Error(err.Error())
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+					return
+				}
+
+				version, err := ifMatch(r)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				car, err := s.API.RestoreCar(r.Context(), id, version)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				setETag(w, car.Version)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				_ = json.NewEncoder(w).Encode(models.NewCarResource(car))
+			}
+
+			r.Get("/cars", listCars)
+			r.Get("/cars/numbers", carNumbers)
+			r.Get("/cars/{id}", getCar)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/cars", createCar)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Put("/cars/{id}", replaceCar)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Patch("/cars/{id}", patchCar)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Delete("/cars/{id}", removeCar)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/cars/{id}/restore", restoreCar)
+			r.Get("/cars/{id}/drivers", carDrivers)
+			r.Get("/cars/{id}/handovers", carHandovers)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/cars/{id}/handovers", addHandover)
+
+			createProject := func(w http.ResponseWriter, r *http.Request) {
+				var newProject models.Project
+
+				err := json.NewDecoder(r.Body).Decode(&newProject)
+				if err != nil {
+					logger.Error(err.Error())
+					writeDecodeError(w, r, err)
+					return
+				}
+
+				project, err := s.API.AddProject(r.Context(), newProject.Create())
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				setETag(w, project.Version)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(project)
+			}
+
+			replaceProject := func(w http.ResponseWriter, r *http.Request) {
+				var updateProject models.Project
+
+				err := json.NewDecoder(r.Body).Decode(&updateProject)
+				if err != nil {
+					logger.Error(err.Error())
+					writeDecodeError(w, r, err)
+					return
+				}
+
+				stringId := chi.URLParam(r, "id")
+
+				if stringId == "" {
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+					return
+				}
+
+				id, err := strconv.Atoi(stringId)
+				if err != nil {
+					logger.Error(err.Error())
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+					return
+				}
+
+				version, err := ifMatch(r)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				project, err := s.API.UpdateProject(r.Context(), id, version, updateProject.Update())
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				setETag(w, project.Version)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				_ = json.NewEncoder(w).Encode(project)
+			}
+
+			patchProject := func(w http.ResponseWriter, r *http.Request) {
+				stringId := chi.URLParam(r, "id")
+
+				if stringId == "" {
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+					return
+				}
+
+				id, err := strconv.Atoi(stringId)
+				if err != nil {
+					logger.Error(err.Error())
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+					return
+				}
+
+				version, err := ifMatch(r)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				patch, err := readMergePatch(r)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				project, err := s.API.PatchProjectResource(r.Context(), id, version, patch)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				setETag(w, project.Version)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				_ = json.NewEncoder(w).Encode(project)
+			}
+
+			r.Get("/projects", listProjects)
+			r.Get("/projects/names", projectNames)
+			r.Get("/projects/{id}", getProject)
+			r.With(authorize(models.RoleAdmin)).Post("/projects", createProject)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Put("/projects/{id}", replaceProject)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Patch("/projects/{id}", patchProject)
+			r.With(authorize(models.RoleAdmin)).Delete("/projects/{id}", removeProject)
+			r.With(authorize(models.RoleAdmin)).Post("/projects/{id}/restore", restoreProject)
+
+			createAccommodation := func(w http.ResponseWriter, r *http.Request) {
+				var newAccommodation models.Accommodation
+
+				err := json.NewDecoder(r.Body).Decode(&newAccommodation)
+				if err != nil {
+					logger.Error(err.Error())
+					writeDecodeError(w, r, err)
+					return
+				}
+
+				accommodation, err := s.API.AddAccommodation(r.Context(), newAccommodation.Create())
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				setETag(w, accommodation.Version)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(accommodation)
+			}
+
+			replaceAccommodation := func(w http.ResponseWriter, r *http.Request) {
+				var updateAccommodation models.Accommodation
+
+				err := json.NewDecoder(r.Body).Decode(&updateAccommodation)
+				if err != nil {
+					logger.Error(err.Error())
+					writeDecodeError(w, r, err)
+					return
+				}
+
+				stringId := chi.URLParam(r, "id")
+
+				if stringId == "" {
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+					return
+				}
+
+				id, err := strconv.Atoi(stringId)
+				if err != nil {
+					logger.Error(err.Error())
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+					return
+				}
+
+				version, err := ifMatch(r)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				accommodation, err := s.API.UpdateAccommodation(r.Context(), id, version, updateAccommodation.Update())
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				setETag(w, accommodation.Version)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				_ = json.NewEncoder(w).Encode(accommodation)
+			}
+
+			patchAccommodation := func(w http.ResponseWriter, r *http.Request) {
+				stringId := chi.URLParam(r, "id")
+
+				if stringId == "" {
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+					return
+				}
+
+				id, err := strconv.Atoi(stringId)
+				if err != nil {
+					logger.Error(err.Error())
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+					return
+				}
+
+				version, err := ifMatch(r)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				patch, err := readMergePatch(r)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				accommodation, err := s.API.PatchAccommodationResource(r.Context(), id, version, patch)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				setETag(w, accommodation.Version)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				_ = json.NewEncoder(w).Encode(accommodation)
+			}
+
+			r.Get("/accommodations", listAccommodations)
+			r.Get("/accommodations/addresses", accommodationAddresses)
+			r.Get("/accommodations/{id}", getAccommodation)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/accommodations", createAccommodation)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Put("/accommodations/{id}", replaceAccommodation)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Patch("/accommodations/{id}", patchAccommodation)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Delete("/accommodations/{id}", removeAccommodation)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/accommodations/{id}/restore", restoreAccommodation)
+			r.Get("/accommodations/{id}/stays", accommodationStays)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/accommodations/{id}/stays", addStay)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/stays/{id}/checkout", checkOut)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Delete("/stays/{id}", removeStay)
+
+			createEmployee := func(w http.ResponseWriter, r *http.Request) {
+				var newEmployee models.Employee
+
+				err := json.NewDecoder(r.Body).Decode(&newEmployee)
+				if err != nil {
+					logger.Error(err.Error())
+					writeDecodeError(w, r, err)
+					return
+				}
+
+				employee, err := s.API.AddEmployee(r.Context(), newEmployee.Create())
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				setETag(w, employee.Version)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(employee)
+			}
+
+			replaceEmployee := func(w http.ResponseWriter, r *http.Request) {
+				var updateEmployee models.Employee
+
+				err := json.NewDecoder(r.Body).Decode(&updateEmployee)
+				if err != nil {
+					logger.Error(err.Error())
+					writeDecodeError(w, r, err)
+					return
+				}
+
+				stringId := chi.URLParam(r, "id")
+
+				if stringId == "" {
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+					return
+				}
+
+				id, err := strconv.Atoi(stringId)
+				if err != nil {
+					logger.Error(err.Error())
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+					return
+				}
+
+				version, err := ifMatch(r)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				employee, err := s.API.UpdateEmployee(r.Context(), id, version, updateEmployee.Update())
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				setETag(w, employee.Version)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				_ = json.NewEncoder(w).Encode(employee)
+			}
+
+			patchEmployee := func(w http.ResponseWriter, r *http.Request) {
+				stringId := chi.URLParam(r, "id")
+
+				if stringId == "" {
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "id is required")
+					return
+				}
+
+				id, err := strconv.Atoi(stringId)
+				if err != nil {
+					logger.Error(err.Error())
+					writeErrorCode(w, r, http.StatusBadRequest, codeBadRequest, "bad request")
+					return
+				}
+
+				version, err := ifMatch(r)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				patch, err := readMergePatch(r)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				employee, err := s.API.PatchEmployeeResource(r.Context(), id, version, patch)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				setETag(w, employee.Version)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				_ = json.NewEncoder(w).Encode(employee)
+			}
+
+			r.Get("/employees", listEmployees)
+			r.Get("/employees/search", searchEmployees)
+			r.Get("/employees/{id}", getEmployee)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/employees", createEmployee)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Put("/employees/{id}", replaceEmployee)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Patch("/employees/{id}", patchEmployee)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Delete("/employees/{id}", removeEmployee)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/employees/{id}/restore", restoreEmployee)
+			r.Get("/employees/{id}/assignments", employeeAssignments)
+			r.Get("/employees/{id}/stays", employeeStays)
+			r.With(authorize(models.RoleAdmin, models.RoleCoordinator)).Post("/employees/{id}/transfers", transferEmployee)
+			r.With(authorize(models.RoleAdmin)).Post("/employees/{id}/account", createAccount)
+
+			r.With(authorize(models.RoleAdmin)).Post("/users/{login}/password-reset", resetPassword)
+			r.With(authorize(models.RoleAdmin)).Post("/users/{login}/unlock", unlockUser)
+			r.With(authorize(models.RoleAdmin)).Put("/users/{login}/projects", setUserProjects)
+			r.With(authorize(models.RoleAdmin)).Post("/users/{login}/revoke", revokeUser)
+		})
+	})
+
+	serveOpenAPI(router)
+
+	router.Post("/login", func(w http.ResponseWriter, r *http.Request) {
+		var req models.LoginRequest
 
 		err := json.NewDecoder(r.Body).Decode(&req)
 		if err != nil {
 			logger.Error(err.Error())
-			http.Error(w, "bad request", http.StatusBadRequest)
+			writeDecodeError(w, r, err)
 			return
 		}
 
-		resp, err := s.API.Login(r.Context(), req.Username, req.Password)
+		resp, err := s.API.Login(r.Context(), req.Username, req.Password, clientInfo(r))
 		if err != nil {
-			if errors.Is(err, api.ErrUnauthorized) {
-				http.Error(w, "unauthorized", http.StatusUnauthorized)
-				return
-			}
-			logger.Error(err.Error())
-			http.Error(w, "internal error", http.StatusInternalServerError)
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+
+	router.Post("/password/reset", func(w http.ResponseWriter, r *http.Request) {
+		var req models.ResetPassword
+
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			writeDecodeError(w, r, err)
+			return
+		}
+
+		if req.Token == "" {
+			writeProblem(w, r, http.StatusBadRequest, codeBadRequest, "bad request", []api.Violation{
+				{Field: "token", Message: "is required"},
+			})
+			return
+		}
+
+		err = s.API.ResetPassword(r.Context(), req)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	router.Post("/token/refresh", func(w http.ResponseWriter, r *http.Request) {
+		var req models.RefreshTokenRequest
+
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			writeDecodeError(w, r, err)
+			return
+		}
+
+		if req.RefreshToken == "" {
+			writeProblem(w, r, http.StatusBadRequest, codeBadRequest, "bad request", []api.Violation{
+				{Field: "refresh_token", Message: "is required"},
+			})
+			return
+		}
+
+		resp, err := s.API.RefreshToken(r.Context(), req.RefreshToken)
+		if err != nil {
+			writeError(w, r, err)
 			return
 		}
 
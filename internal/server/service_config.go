@@ -8,6 +8,10 @@ import (
 
 type Config struct {
 	JWTSecret string `envconfig:"JWT_SECRET" required:"true"`
+
+	// TrustProxyHeaders takes the client IP from X-Forwarded-For/X-Real-IP.
+	// Enable it only behind a reverse proxy that sets these headers.
+	TrustProxyHeaders bool `envconfig:"TRUST_PROXY_HEADERS" default:"false"`
 }
 
 func readConfig() (Config, error) {
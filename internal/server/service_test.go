@@ -0,0 +1,538 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"api/internal/models"
+	"api/internal/storage"
+)
+
+// testPassword meets the default password policy.
+const testPassword = "Str0ng-Passw0rd"
+
+// newTestHandler returns the handler of a service backed by the demo data.
+func newTestHandler(t *testing.T) http.Handler {
+	t.Helper()
+
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("BCRYPT_COST", "4")
+
+	repo, err := storage.NewDemo()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc, err := NewService(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return svc.Handler()
+}
+
+// request is a request sent by serve. Body is sent as JSON unless it is a
+// string, which is sent as is.
+type request struct {
+	method  string
+	path    string
+	token   string
+	body    any
+	headers map[string]string
+}
+
+func serve(t *testing.T, h http.Handler, req request) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body []byte
+	switch b := req.body.(type) {
+	case nil:
+	case string:
+		body = []byte(b)
+	default:
+		var err error
+		if body, err = json.Marshal(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := httptest.NewRequest(req.method, req.path, bytes.NewReader(body))
+	if body != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	if req.token != "" {
+		r.Header.Set("Authorization", "Bearer "+req.token)
+	}
+	for name, value := range req.headers {
+		r.Header.Set(name, value)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+// expect fails the test unless the response has the given status, and
+// decodes its body into v if v is not nil.
+func expect(t *testing.T, w *httptest.ResponseRecorder, status int, v any) {
+	t.Helper()
+
+	if w.Code != status {
+		t.Fatalf("got %d %s, want %d", w.Code, w.Body, status)
+	}
+
+	if v != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+			t.Fatalf("decoding %s: %v", w.Body, err)
+		}
+	}
+}
+
+func login(t *testing.T, h http.Handler, username, password string) models.LoginResponse {
+	t.Helper()
+
+	var resp models.LoginResponse
+	expect(t, serve(t, h, request{method: http.MethodPost, path: "/login", body: models.LoginRequest{Username: username, Password: password}}), http.StatusOK, &resp)
+
+	return resp
+}
+
+// demoIDs returns the IDs of the demo projects and of their employees.
+func demoIDs(t *testing.T, h http.Handler, token string) (projectIDs []int, employeeIDs map[int][]int) {
+	t.Helper()
+
+	var projects models.Page[models.Project]
+	expect(t, serve(t, h, request{method: http.MethodGet, path: "/v2/projects?sort=id", token: token}), http.StatusOK, &projects)
+
+	employeeIDs = make(map[int][]int)
+	for _, p := range projects.Items {
+		projectIDs = append(projectIDs, p.ID)
+
+		var employees models.Page[models.Employee]
+		expect(t, serve(t, h, request{method: http.MethodGet, path: "/v2/employees?sort=id&project_id=" + strconv.Itoa(p.ID), token: token}), http.StatusOK, &employees)
+
+		for _, e := range employees.Items {
+			employeeIDs[p.ID] = append(employeeIDs[p.ID], e.ID)
+		}
+	}
+
+	return projectIDs, employeeIDs
+}
+
+// userLogin is the login addUser gives the employee.
+func userLogin(employeeID int) string {
+	return "user" + strconv.Itoa(employeeID)
+}
+
+// addUser creates an account for the employee and returns a token of it.
+func addUser(t *testing.T, h http.Handler, adminToken string, employeeID int, role models.Role, projectIDs ...int) string {
+	t.Helper()
+
+	account := models.NewAccount{
+		Login:      userLogin(employeeID),
+		Password:   testPassword,
+		Role:       role,
+		ProjectIDs: projectIDs,
+	}
+	expect(t, serve(t, h, request{method: http.MethodPost, path: "/v2/employees/" + strconv.Itoa(employeeID) + "/account", token: adminToken, body: account}), http.StatusCreated, nil)
+
+	return login(t, h, account.Login, testPassword).JWT
+}
+
+func TestAuthorization(t *testing.T) {
+	h := newTestHandler(t)
+	admin := login(t, h, storage.DemoLogin, storage.DemoPassword).JWT
+
+	projectIDs, employeeIDs := demoIDs(t, h, admin)
+	readOnly := addUser(t, h, admin, employeeIDs[projectIDs[1]][0], models.RoleReadOnly)
+
+	tests := []struct {
+		name   string
+		req    request
+		status int
+	}{
+		{"no token", request{method: http.MethodGet, path: "/v2/cars"}, http.StatusUnauthorized},
+		{"invalid token", request{method: http.MethodGet, path: "/v2/cars", token: "not-a-jwt"}, http.StatusUnauthorized},
+		{"read-only lists cars", request{method: http.MethodGet, path: "/v2/cars", token: readOnly}, http.StatusOK},
+		{"read-only archives a project", request{method: http.MethodDelete, path: "/v2/projects/" + strconv.Itoa(projectIDs[0]), token: readOnly, headers: map[string]string{"If-Match": `"1"`}}, http.StatusForbidden},
+		{"read-only audits", request{method: http.MethodGet, path: "/audit", token: readOnly}, http.StatusForbidden},
+		{"admin audits", request{method: http.MethodGet, path: "/audit", token: admin}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expect(t, serve(t, h, tt.req), tt.status, nil)
+		})
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	h := newTestHandler(t)
+	first := login(t, h, storage.DemoLogin, storage.DemoPassword)
+
+	refresh := func(token string) *httptest.ResponseRecorder {
+		return serve(t, h, request{method: http.MethodPost, path: "/token/refresh", body: models.RefreshTokenRequest{RefreshToken: token}})
+	}
+
+	expect(t, refresh(""), http.StatusBadRequest, nil)
+	expect(t, refresh("unknown"), http.StatusUnauthorized, nil)
+
+	var second models.LoginResponse
+	expect(t, refresh(first.RefreshToken), http.StatusOK, &second)
+
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("refreshing returned the same refresh token")
+	}
+
+	expect(t, serve(t, h, request{method: http.MethodGet, path: "/v2/cars", token: second.JWT}), http.StatusOK, nil)
+
+	// Reusing a rotated token ends every session of the user.
+	expect(t, refresh(first.RefreshToken), http.StatusUnauthorized, nil)
+	expect(t, serve(t, h, request{method: http.MethodGet, path: "/v2/cars", token: second.JWT}), http.StatusUnauthorized, nil)
+	expect(t, refresh(second.RefreshToken), http.StatusUnauthorized, nil)
+}
+
+func TestLogout(t *testing.T) {
+	h := newTestHandler(t)
+	session := login(t, h, storage.DemoLogin, storage.DemoPassword)
+	other := login(t, h, storage.DemoLogin, storage.DemoPassword)
+
+	expect(t, serve(t, h, request{method: http.MethodPost, path: "/logout", token: session.JWT}), http.StatusNoContent, nil)
+
+	expect(t, serve(t, h, request{method: http.MethodGet, path: "/v2/cars", token: session.JWT}), http.StatusUnauthorized, nil)
+
+	// Other sessions of the user go on.
+	expect(t, serve(t, h, request{method: http.MethodGet, path: "/v2/cars", token: other.JWT}), http.StatusOK, nil)
+
+	expect(t, serve(t, h, request{method: http.MethodPost, path: "/token/refresh", body: models.RefreshTokenRequest{RefreshToken: session.RefreshToken}}), http.StatusUnauthorized, nil)
+}
+
+func TestAccounts(t *testing.T) {
+	h := newTestHandler(t)
+	admin := login(t, h, storage.DemoLogin, storage.DemoPassword).JWT
+	projectIDs, employeeIDs := demoIDs(t, h, admin)
+	employeeID := employeeIDs[projectIDs[1]][0]
+
+	createAccount := func(employeeID int, account models.NewAccount) *httptest.ResponseRecorder {
+		return serve(t, h, request{method: http.MethodPost, path: "/v2/employees/" + strconv.Itoa(employeeID) + "/account", token: admin, body: account})
+	}
+
+	expect(t, createAccount(employeeID, models.NewAccount{Login: "nowak", Password: "short", Role: models.RoleCoordinator}), http.StatusBadRequest, nil)
+	expect(t, createAccount(employeeID, models.NewAccount{Login: storage.DemoLogin, Password: testPassword, Role: models.RoleCoordinator}), http.StatusConflict, nil)
+	expect(t, createAccount(employeeID, models.NewAccount{Login: "nowak", Password: testPassword, Role: models.RoleCoordinator}), http.StatusCreated, nil)
+
+	// The employee already has an account.
+	expect(t, createAccount(employeeID, models.NewAccount{Login: "nowak2", Password: testPassword, Role: models.RoleAdmin}), http.StatusConflict, nil)
+
+	session := login(t, h, "nowak", testPassword)
+
+	changePassword := func(current, password string) *httptest.ResponseRecorder {
+		return serve(t, h, request{method: http.MethodPost, path: "/account/password", token: session.JWT, body: models.ChangePassword{CurrentPassword: current, NewPassword: password}})
+	}
+
+	expect(t, changePassword("wrong", testPassword+"2"), http.StatusUnauthorized, nil)
+	expect(t, changePassword(testPassword, testPassword+"2"), http.StatusNoContent, nil)
+
+	// Changing the password ends the sessions of the user.
+	expect(t, serve(t, h, request{method: http.MethodGet, path: "/v2/cars", token: session.JWT}), http.StatusUnauthorized, nil)
+	login(t, h, "nowak", testPassword+"2")
+
+	var reset models.PasswordResetResponse
+	expect(t, serve(t, h, request{method: http.MethodPost, path: "/v2/users/nowak/password-reset", token: admin}), http.StatusCreated, &reset)
+
+	resetPassword := func(password string) *httptest.ResponseRecorder {
+		return serve(t, h, request{method: http.MethodPost, path: "/password/reset", body: models.ResetPassword{Token: reset.Token, NewPassword: password}})
+	}
+
+	expect(t, resetPassword("short"), http.StatusBadRequest, nil)
+	expect(t, resetPassword(testPassword+"3"), http.StatusNoContent, nil)
+	expect(t, resetPassword(testPassword+"4"), http.StatusUnauthorized, nil)
+
+	login(t, h, "nowak", testPassword+"3")
+}
+
+func TestLoginLockout(t *testing.T) {
+	t.Setenv("LOGIN_MAX_ATTEMPTS", "3")
+	t.Setenv("LOGIN_IP_MAX_ATTEMPTS", "6")
+
+	h := newTestHandler(t)
+	admin := login(t, h, storage.DemoLogin, storage.DemoPassword).JWT
+	projectIDs, employeeIDs := demoIDs(t, h, admin)
+	addUser(t, h, admin, employeeIDs[projectIDs[1]][0], models.RoleCoordinator, projectIDs[1])
+
+	user := userLogin(employeeIDs[projectIDs[1]][0])
+
+	attempt := func(username, password string) *httptest.ResponseRecorder {
+		return serve(t, h, request{method: http.MethodPost, path: "/login", body: models.LoginRequest{Username: username, Password: password}})
+	}
+
+	for range 3 {
+		expect(t, attempt(user, "wrong"), http.StatusUnauthorized, nil)
+	}
+
+	// A locked out user looks like a wrong password.
+	expect(t, attempt(user, testPassword), http.StatusUnauthorized, nil)
+
+	expect(t, serve(t, h, request{method: http.MethodPost, path: "/v2/users/" + user + "/unlock", token: admin}), http.StatusNoContent, nil)
+	login(t, h, user, testPassword)
+
+	// The successful login cleared the failures of the IP.
+	for range 6 {
+		expect(t, attempt("nobody", "wrong"), http.StatusUnauthorized, nil)
+	}
+
+	w := attempt(storage.DemoLogin, storage.DemoPassword)
+	expect(t, w, http.StatusTooManyRequests, nil)
+
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("a locked out IP gets no Retry-After")
+	}
+}
+
+func TestProjectScope(t *testing.T) {
+	h := newTestHandler(t)
+	admin := login(t, h, storage.DemoLogin, storage.DemoPassword).JWT
+	projectIDs, employeeIDs := demoIDs(t, h, admin)
+	own, other := projectIDs[1], projectIDs[0]
+	coordinator := addUser(t, h, admin, employeeIDs[own][0], models.RoleCoordinator, own)
+
+	var employees models.Page[models.Employee]
+	expect(t, serve(t, h, request{method: http.MethodGet, path: "/v2/employees", token: coordinator}), http.StatusOK, &employees)
+
+	if employees.Total != len(employeeIDs[own]) {
+		t.Errorf("coordinator lists %d employees, want the %d of their project", employees.Total, len(employeeIDs[own]))
+	}
+
+	otherEmployee := "/v2/employees/" + strconv.Itoa(employeeIDs[other][0])
+
+	expect(t, serve(t, h, request{method: http.MethodGet, path: otherEmployee, token: coordinator}), http.StatusNotFound, nil)
+	expect(t, serve(t, h, request{method: http.MethodPatch, path: otherEmployee, token: coordinator, body: `{"email":"x@example.com"}`, headers: map[string]string{"If-Match": `"1"`}}), http.StatusNotFound, nil)
+	expect(t, serve(t, h, request{method: http.MethodGet, path: "/v2/projects/" + strconv.Itoa(other), token: coordinator}), http.StatusNotFound, nil)
+	expect(t, serve(t, h, request{method: http.MethodPost, path: "/v2/employees", token: coordinator, body: models.Employee{LastName: "Nowy", FirstName: "Jan", ProjectId: other}}), http.StatusForbidden, nil)
+
+	// Changing the projects ends the sessions carrying the old ones.
+	expect(t, serve(t, h, request{method: http.MethodPut, path: "/v2/users/" + userLogin(employeeIDs[own][0]) + "/projects", token: admin, body: []int{own, other}}), http.StatusNoContent, nil)
+	expect(t, serve(t, h, request{method: http.MethodGet, path: "/v2/employees", token: coordinator}), http.StatusUnauthorized, nil)
+
+	coordinator = login(t, h, userLogin(employeeIDs[own][0]), testPassword).JWT
+	expect(t, serve(t, h, request{method: http.MethodGet, path: otherEmployee, token: coordinator}), http.StatusOK, nil)
+}
+
+func TestIfMatch(t *testing.T) {
+	h := newTestHandler(t)
+	admin := login(t, h, storage.DemoLogin, storage.DemoPassword).JWT
+	projectIDs, _ := demoIDs(t, h, admin)
+	path := "/v2/projects/" + strconv.Itoa(projectIDs[0])
+
+	var project models.Project
+	w := serve(t, h, request{method: http.MethodGet, path: path, token: admin})
+	expect(t, w, http.StatusOK, &project)
+
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("got no ETag")
+	}
+
+	project.Name = "Magazyn Gniezno"
+	put := func(ifMatch string) *httptest.ResponseRecorder {
+		headers := map[string]string{}
+		if ifMatch != "" {
+			headers["If-Match"] = ifMatch
+		}
+
+		return serve(t, h, request{method: http.MethodPut, path: path, token: admin, body: project, headers: headers})
+	}
+
+	expect(t, put(""), http.StatusPreconditionRequired, nil)
+	expect(t, put("*"), http.StatusPreconditionRequired, nil)
+	expect(t, put(`"999"`), http.StatusPreconditionFailed, nil)
+	expect(t, put("W/"+etag), http.StatusPreconditionFailed, nil)
+
+	w = put(etag)
+	expect(t, w, http.StatusOK, nil)
+
+	if w.Header().Get("ETag") == etag {
+		t.Errorf("got ETag %s after the change, want a new one", etag)
+	}
+
+	// The tag read before the change is stale now, on the v1 routes too.
+	expect(t, put(etag), http.StatusPreconditionFailed, nil)
+	expect(t, serve(t, h, request{method: http.MethodDelete, path: "/project/" + strconv.Itoa(projectIDs[0]), token: admin, headers: map[string]string{"If-Match": etag}}), http.StatusPreconditionFailed, nil)
+	expect(t, serve(t, h, request{method: http.MethodDelete, path: path, token: admin, headers: map[string]string{"If-Match": w.Header().Get("ETag")}}), http.StatusNoContent, nil)
+}
+
+func TestMergePatch(t *testing.T) {
+	h := newTestHandler(t)
+	admin := login(t, h, storage.DemoLogin, storage.DemoPassword).JWT
+	projectIDs, employeeIDs := demoIDs(t, h, admin)
+	path := "/v2/employees/" + strconv.Itoa(employeeIDs[projectIDs[0]][0])
+
+	var before models.Employee
+	w := serve(t, h, request{method: http.MethodGet, path: path, token: admin})
+	expect(t, w, http.StatusOK, &before)
+	etag := w.Header().Get("ETag")
+
+	patch := func(contentType, body string) *httptest.ResponseRecorder {
+		return serve(t, h, request{method: http.MethodPatch, path: path, token: admin, body: body, headers: map[string]string{"If-Match": etag, "Content-Type": contentType}})
+	}
+
+	expect(t, patch("text/plain", `{"email":"marek@example.com"}`), http.StatusUnsupportedMediaType, nil)
+	expect(t, patch(mergePatchType, `{"emial":"marek@example.com"}`), http.StatusBadRequest, nil)
+	expect(t, patch(mergePatchType, `{"email":"marek@example.com"`), http.StatusBadRequest, nil)
+	expect(t, patch(mergePatchType, `{"last_name":null}`), http.StatusUnprocessableEntity, nil)
+
+	var after models.Employee
+	w = patch(mergePatchType, `{"email":"marek@example.com","medicals":{"sanitary_valid_until":"2030-01-31"}}`)
+	expect(t, w, http.StatusOK, &after)
+
+	switch {
+	case after.Email != "marek@example.com":
+		t.Errorf("email = %q, want it patched", after.Email)
+	case after.Medicals.SanitaryValidUntil == nil:
+		t.Error("sanitary valid until is not set, want it patched")
+	case after.LastName != before.LastName || after.Pesel != before.Pesel || after.HomeAddress != nil:
+		t.Errorf("patching the email changed other fields: %+v", after)
+	case !time.Time(after.Medicals.MedicalValidUntil).Equal(time.Time(before.Medicals.MedicalValidUntil)):
+		t.Errorf("medical valid until %v, want %v left alone", after.Medicals.MedicalValidUntil, before.Medicals.MedicalValidUntil)
+	case *after.AccommodationId != *before.AccommodationId || *after.CarId != *before.CarId:
+		t.Errorf("patching the email changed the assignments: %+v", after)
+	}
+
+	etag = w.Header().Get("ETag")
+	w = patch(mergePatchType, `{"medicals":{"sanitary_valid_until":null}}`)
+
+	var cleared models.Employee
+	expect(t, w, http.StatusOK, &cleared)
+
+	if cleared.Medicals.SanitaryValidUntil != nil {
+		t.Errorf("sanitary valid until %v, want null to clear it", cleared.Medicals.SanitaryValidUntil)
+	}
+
+	// The v1 route takes the update model, spelled in camelCase.
+	etag = w.Header().Get("ETag")
+	w = serve(t, h, request{method: http.MethodPatch, path: "/employee/" + strconv.Itoa(after.ID), token: admin, body: `{"firstName":"Mariusz"}`, headers: map[string]string{"If-Match": etag, "Content-Type": mergePatchType}})
+
+	var renamed models.Employee
+	expect(t, w, http.StatusOK, &renamed)
+
+	if renamed.FirstName != "Mariusz" || renamed.Email != "marek@example.com" {
+		t.Errorf("patching the first name gave %+v", renamed)
+	}
+}
+
+// TestResourcesRoundTrip checks that every v2 resource can be replaced with
+// the body it is read with, leaving it as it was.
+func TestResourcesRoundTrip(t *testing.T) {
+	h := newTestHandler(t)
+	admin := login(t, h, storage.DemoLogin, storage.DemoPassword).JWT
+
+	for _, collection := range []string{"projects", "accommodations", "cars", "employees"} {
+		t.Run(collection, func(t *testing.T) {
+			var page struct {
+				Items []map[string]any `json:"items"`
+			}
+			expect(t, serve(t, h, request{method: http.MethodGet, path: "/v2/" + collection, token: admin}), http.StatusOK, &page)
+
+			if len(page.Items) == 0 {
+				t.Fatal("got no items")
+			}
+
+			path := "/v2/" + collection + "/" + strconv.Itoa(int(page.Items[0]["id"].(float64)))
+
+			w := serve(t, h, request{method: http.MethodGet, path: path, token: admin})
+			expect(t, w, http.StatusOK, nil)
+			read := w.Body.String()
+
+			var fields any
+			_ = json.Unmarshal([]byte(read), &fields)
+			checkSnakeCase(t, "", fields)
+
+			w = serve(t, h, request{method: http.MethodPut, path: path, token: admin, body: read, headers: map[string]string{"If-Match": w.Header().Get("ETag")}})
+			expect(t, w, http.StatusOK, nil)
+
+			var before, after any
+			_ = json.Unmarshal([]byte(read), &before)
+			_ = json.Unmarshal(w.Body.Bytes(), &after)
+
+			if !reflect.DeepEqual(before, after) {
+				t.Errorf("replacing with\n%s\ngave\n%s", read, w.Body)
+			}
+		})
+	}
+}
+
+func TestProblemDetails(t *testing.T) {
+	h := newTestHandler(t)
+	admin := login(t, h, storage.DemoLogin, storage.DemoPassword).JWT
+	projectIDs, employeeIDs := demoIDs(t, h, admin)
+
+	driver := "/v2/employees/" + strconv.Itoa(employeeIDs[projectIDs[0]][0])
+	etag := serve(t, h, request{method: http.MethodGet, path: driver, token: admin}).Header().Get("ETag")
+
+	tests := []struct {
+		name      string
+		req       request
+		status    int
+		code      string
+		violation string
+	}{
+		{"unknown route", request{method: http.MethodGet, path: "/v2/unknown", token: admin}, http.StatusNotFound, codeNotFound, ""},
+		{"unknown method", request{method: http.MethodPut, path: "/v2/cars", token: admin}, http.StatusMethodNotAllowed, codeMethodNotAllowed, ""},
+		{"no token", request{method: http.MethodGet, path: "/v2/cars"}, http.StatusUnauthorized, codeUnauthorized, ""},
+		{"unknown record", request{method: http.MethodGet, path: "/v2/cars/999", token: admin}, http.StatusNotFound, codeNotFound, ""},
+		{"invalid JSON", request{method: http.MethodPost, path: "/v2/cars", token: admin, body: `{"model":`}, http.StatusBadRequest, codeBadRequest, ""},
+		{"wrong type", request{method: http.MethodPost, path: "/v2/cars", token: admin, body: `{"project_id":"one"}`}, http.StatusBadRequest, codeBadRequest, "project_id"},
+		{"invalid field", request{method: http.MethodPost, path: "/v2/employees", token: admin, body: models.Employee{LastName: "Nowy", FirstName: "Jan", Pesel: "85010112346", ProjectId: projectIDs[0]}}, http.StatusBadRequest, codeValidation, "pesel"},
+		{"missing refresh token", request{method: http.MethodPost, path: "/token/refresh", body: `{}`}, http.StatusBadRequest, codeBadRequest, "refresh_token"},
+		{"car changing hands", request{method: http.MethodPatch, path: driver, token: admin, body: `{"car_id":null}`, headers: map[string]string{"If-Match": etag, "Content-Type": mergePatchType}}, http.StatusConflict, codeCarHandover, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(t, h, tt.req)
+
+			var p problem
+			expect(t, w, tt.status, &p)
+
+			if got := w.Header().Get("Content-Type"); got != problemMediaType {
+				t.Errorf("Content-Type = %s, want %s", got, problemMediaType)
+			}
+
+			if p.Status != tt.status || p.Code != tt.code || p.Title == "" || p.Detail == "" || p.RequestID == "" {
+				t.Errorf("got %+v, want status %d and code %s", p, tt.status, tt.code)
+			}
+
+			if p.Instance != strings.Split(tt.req.path, "?")[0] {
+				t.Errorf("instance = %s, want %s", p.Instance, tt.req.path)
+			}
+
+			if tt.violation != "" && (len(p.Violations) == 0 || p.Violations[0].Field != tt.violation) {
+				t.Errorf("violations = %+v, want one of %s", p.Violations, tt.violation)
+			}
+		})
+	}
+}
+
+// checkSnakeCase checks that the members of the objects in v are named in
+// snake_case.
+func checkSnakeCase(t *testing.T, path string, v any) {
+	t.Helper()
+
+	if object, ok := v.(map[string]any); ok {
+		for name, value := range object {
+			if strings.ToLower(name) != name {
+				t.Errorf("%s%s is not in snake_case", path, name)
+			}
+
+			checkSnakeCase(t, path+name+".", value)
+		}
+	}
+}
@@ -3,50 +3,106 @@ package storage
 import (
 	"api/internal/models"
 	"context"
+	"fmt"
 	"github.com/pkg/errors"
+	"time"
 )
 
-func (s *Service) Accommodations(ctx context.Context) ([]models.Accommodation, error) {
-	sql := "SELECT a.Id_Accommodation, a.Id_Project, pro.Name, a.City, a.Accommodation_Address, a.Number_Of_Places, c.Id_Contact, c.First_Name, c.Last_Name, c.Phone_Number, p.Id_Payment, p.Cost, p.Deposit, p.Contract, p.Account_Number, p.Payment_Day FROM Accommodation a LEFT JOIN Contact c ON a.Id_Accommodation = c.Id_Accommodation LEFT JOIN Payments p ON a.Id_Accommodation = p.Id_Accommodation LEFT JOIN Project pro ON a.Id_Project = pro.Id_Project;"
-	rows, err := s.DB.QueryContext(ctx, sql)
+// Accommodations returns the filter's page of the accommodation list
+// together with the number of accommodations on all pages.
+func (s *Service) Accommodations(ctx context.Context, filter models.AccommodationFilter) ([]models.Accommodation, int, error) {
+	orderBy, err := accommodationSort.orderBy(filter.Sort)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to query for accommodations")
+		return nil, 0, err
+	}
+
+	scope, args := projectFilter(ctx, "a.Id_Project", nil)
+	scope += " AND " + archivedFilter("a", filter.ListFilter)
+
+	if filter.ProjectID != 0 {
+		args = append(args, filter.ProjectID)
+		scope += fmt.Sprintf(" AND a.Id_Project = @p%d", len(args))
+	}
+
+	if filter.City != "" {
+		args = append(args, filter.City)
+		scope += fmt.Sprintf(" AND LOWER(a.City) = LOWER(@p%d)", len(args))
+	}
+
+	total, err := s.count(ctx, "FROM Accommodation a WHERE "+scope, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pageClause, args := page(filter.ListFilter, args)
+
+	sql := "SELECT a.Id_Accommodation, a.Id_Project, pro.Name, a.City, a.Accommodation_Address, a.Number_Of_Places, c.Id_Contact, c.First_Name, c.Last_Name, c.Phone_Number, p.Id_Payment, p.Cost, p.Deposit, p.Contract, p.Account_Number, p.Payment_Day, a.Archived_At, COALESCE(a.Archived_By, '') FROM Accommodation a LEFT JOIN Contact c ON a.Id_Accommodation = c.Id_Accommodation LEFT JOIN Payments p ON a.Id_Accommodation = p.Id_Accommodation LEFT JOIN Project pro ON a.Id_Project = pro.Id_Project WHERE " + scope + " " + orderBy + pageClause + ";"
+	rows, err := s.conn(ctx).QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to query for accommodations")
 	}
 	defer rows.Close()
 
 	results := make([]models.Accommodation, 0)
 	for rows.Next() {
 		var acc models.Accommodation
-		err = rows.Scan(&acc.ID, &acc.ProjectID, &acc.ProjectName, &acc.City, &acc.AccommodationAddress, &acc.NumberOfPlaces, &acc.Contact.ID, &acc.Contact.FirstName, &acc.Contact.LastName, &acc.Contact.PhoneNumber, &acc.Payment.ID, &acc.Payment.Cost, &acc.Payment.Deposit, &acc.Payment.Contract, &acc.Payment.AccountNumber, &acc.Payment.PaymentDay)
+		err = rows.Scan(&acc.ID, &acc.ProjectID, &acc.ProjectName, &acc.City, &acc.AccommodationAddress, &acc.NumberOfPlaces, &acc.Contact.ID, &acc.Contact.FirstName, &acc.Contact.LastName, &acc.Contact.PhoneNumber, &acc.Payment.ID, &acc.Payment.Cost, &acc.Payment.Deposit, &acc.Payment.Contract, &acc.Payment.AccountNumber, &acc.Payment.PaymentDay, &acc.ArchivedAt, &acc.ArchivedBy)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to scan row")
+			return nil, 0, errors.Wrap(err, "failed to scan row")
+		}
+
+		err = s.crypt.decryptAll(map[string]*string{fieldAccountNumber: acc.Payment.AccountNumber})
+		if err != nil {
+			return nil, 0, err
 		}
+
 		results = append(results, acc)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, errors.Wrap(err, "failed to iterate rows")
+		return nil, 0, errors.Wrap(err, "failed to iterate rows")
 	}
 
-	return results, nil
+	return results, total, nil
 }
 
 func (s *Service) AddAccommodation(ctx context.Context, newAccommodation models.NewAccommodation) (id int, err error) {
+	err = s.InTx(ctx, func(ctx context.Context) error {
+		id, err = s.addAccommodation(ctx, newAccommodation)
+		return err
+	})
+
+	return id, err
+}
+
+func (s *Service) addAccommodation(ctx context.Context, newAccommodation models.NewAccommodation) (id int, err error) {
+	if err = checkProject(ctx, newAccommodation.ProjectID); err != nil {
+		return 0, err
+	}
+
+	if err = s.checkReference(ctx, "Project", "Id_Project", newAccommodation.ProjectID); err != nil {
+		return 0, err
+	}
 
 	sql := "INSERT INTO Accommodation (Id_Project, City, Accommodation_Address, Number_Of_Places) VALUES (@p1,@p2,@p3,@p4); SELECT SCOPE_IDENTITY() AS Id_Accommodation;;"
-	err = s.DB.QueryRowContext(ctx, sql, newAccommodation.ProjectID, newAccommodation.City, newAccommodation.Address, newAccommodation.NumberOfPlaces).Scan(&id)
+	err = s.conn(ctx).QueryRowContext(ctx, sql, newAccommodation.ProjectID, newAccommodation.City, newAccommodation.Address, newAccommodation.NumberOfPlaces).Scan(&id)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to add accommodation")
 	}
 
 	sql = "INSERT INTO Contact (Id_Accommodation, First_Name, Last_Name, Phone_Number) VALUES (@p1,@p2,@p3,@p4);"
-	_, err = s.DB.ExecContext(ctx, sql, id, newAccommodation.Contact.FirstName, newAccommodation.Contact.LastName, newAccommodation.Contact.PhoneNumber)
+	_, err = s.conn(ctx).ExecContext(ctx, sql, id, newAccommodation.Contact.FirstName, newAccommodation.Contact.LastName, newAccommodation.Contact.PhoneNumber)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to add contact")
 	}
 
+	accountNumber, err := s.crypt.encrypt(fieldAccountNumber, newAccommodation.Payment.AccountNumber)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to encrypt account number")
+	}
+
 	sql = "INSERT INTO Payments (Id_Accommodation, Cost, Deposit, Contract, Account_Number, Payment_Day) VALUES (@p1,@p2,@p3,@p4,@p5,@p6);"
-	_, err = s.DB.ExecContext(ctx, sql, id, newAccommodation.Payment.Cost, newAccommodation.Payment.Deposit, newAccommodation.Payment.Contract, newAccommodation.Payment.AccountNumber, newAccommodation.Payment.PaymentDay)
+	_, err = s.conn(ctx).ExecContext(ctx, sql, id, newAccommodation.Payment.Cost, newAccommodation.Payment.Deposit, newAccommodation.Payment.Contract, accountNumber, newAccommodation.Payment.PaymentDay)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to add payment")
 	}
@@ -55,44 +111,158 @@ func (s *Service) AddAccommodation(ctx context.Context, newAccommodation models.
 }
 
 func (s *Service) GetAccommodation(ctx context.Context, id int) (models.Accommodation, error) {
-	sql := "SELECT a.Id_Accommodation, a.Id_Project, pro.Name, a.City, a.Accommodation_Address, a.Number_Of_Places, c.Id_Contact, c.First_Name, c.Last_Name, c.Phone_Number, p.Id_Payment, p.Cost, p.Deposit, p.Contract, p.Account_Number, p.Payment_Day FROM Accommodation a LEFT JOIN Contact c ON a.Id_Accommodation = c.Id_Accommodation LEFT JOIN Payments p ON a.Id_Accommodation = p.Id_Accommodation LEFT JOIN Project pro ON a.Id_Project = pro.Id_Project WHERE a.Id_Accommodation = @p1;"
+	scope, args := projectFilter(ctx, "a.Id_Project", []any{id})
+
+	sql := "SELECT a.Id_Accommodation, a.Id_Project, pro.Name, a.City, a.Accommodation_Address, a.Number_Of_Places, c.Id_Contact, c.First_Name, c.Last_Name, c.Phone_Number, p.Id_Payment, p.Cost, p.Deposit, p.Contract, p.Account_Number, p.Payment_Day, a.Version, a.Archived_At, COALESCE(a.Archived_By, '') FROM Accommodation a LEFT JOIN Contact c ON a.Id_Accommodation = c.Id_Accommodation LEFT JOIN Payments p ON a.Id_Accommodation = p.Id_Accommodation LEFT JOIN Project pro ON a.Id_Project = pro.Id_Project WHERE a.Id_Accommodation = @p1 AND " + scope + ";"
 
 	var acc models.Accommodation
 
-	err := s.DB.QueryRowContext(ctx, sql, id).Scan(&acc.ID, &acc.ProjectID, &acc.ProjectName, &acc.City, &acc.AccommodationAddress, &acc.NumberOfPlaces, &acc.Contact.ID, &acc.Contact.FirstName, &acc.Contact.LastName, &acc.Contact.PhoneNumber, &acc.Payment.ID, &acc.Payment.Cost, &acc.Payment.Deposit, &acc.Payment.Contract, &acc.Payment.AccountNumber, &acc.Payment.PaymentDay)
+	err := s.conn(ctx).QueryRowContext(ctx, sql, args...).Scan(&acc.ID, &acc.ProjectID, &acc.ProjectName, &acc.City, &acc.AccommodationAddress, &acc.NumberOfPlaces, &acc.Contact.ID, &acc.Contact.FirstName, &acc.Contact.LastName, &acc.Contact.PhoneNumber, &acc.Payment.ID, &acc.Payment.Cost, &acc.Payment.Deposit, &acc.Payment.Contract, &acc.Payment.AccountNumber, &acc.Payment.PaymentDay, &acc.Version, &acc.ArchivedAt, &acc.ArchivedBy)
+	if err != nil {
+		return acc, errors.Wrap(err, "failed to retrieve accommodation")
+	}
+
+	err = s.crypt.decryptAll(map[string]*string{fieldAccountNumber: acc.Payment.AccountNumber})
+
+	return acc, err
+}
+
+// ArchiveAccommodation archives the accommodation and unassigns the
+// employees living there.
+func (s *Service) ArchiveAccommodation(ctx context.Context, id, version int) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		return s.archiveAccommodation(ctx, id, version)
+	})
+}
+
+func (s *Service) archiveAccommodation(ctx context.Context, id, version int) error {
+	if err := s.accommodationVisible(ctx, id); err != nil {
+		return errors.Wrap(err, "failed to retrieve accommodation")
+	}
+
+	if err := s.bumpVersion(ctx, "Accommodation", "Id_Accommodation", id, version); err != nil {
+		return err
+	}
+
+	if err := s.archive(ctx, "Accommodation", "Id_Accommodation", id, time.Now().UTC()); err != nil {
+		return err
+	}
 
-	return acc, errors.Wrap(err, "failed to retrieve accommodation")
+	return s.vacate(ctx, "Id_Accommodation = @p1", id)
 }
 
-func (s *Service) RemoveAccommodation(ctx context.Context, id int) error {
-	sql := "DELETE FROM Contact WHERE Id_Accommodation = @p1; DELETE FROM Payments WHERE Id_Accommodation = @p1; DELETE FROM Employee_Accommodation WHERE Id_Accommodation = @p1; DELETE FROM Accommodation WHERE Id_Accommodation = @p1; "
+// RestoreAccommodation brings back an archived accommodation. It returns
+// ErrNotFound if the accommodation is not archived and ErrReferenced while
+// its project is.
+func (s *Service) RestoreAccommodation(ctx context.Context, id, version int) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		return s.restoreAccommodation(ctx, id, version)
+	})
+}
+
+func (s *Service) restoreAccommodation(ctx context.Context, id, version int) error {
+	if err := s.accommodationVisible(ctx, id); err != nil {
+		return errors.Wrap(err, "failed to retrieve accommodation")
+	}
+
+	if err := s.bumpVersion(ctx, "Accommodation", "Id_Accommodation", id, version); err != nil {
+		return err
+	}
+
+	if err := s.restore(ctx, "Accommodation", "Id_Accommodation", id); err != nil {
+		return err
+	}
+
+	var projectID int
 
-	_, err := s.DB.ExecContext(ctx, sql, id)
+	err := s.conn(ctx).QueryRowContext(ctx, "SELECT Id_Project FROM Accommodation WHERE Id_Accommodation = @p1", id).Scan(&projectID)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve accommodation")
+	}
 
-	return errors.Wrap(err, "failed to remove car")
+	return s.checkReference(ctx, "Project", "Id_Project", projectID)
 }
 
-func (s *Service) UpdateAccommodation(ctx context.Context, id int, updateAccommodation models.UpdateAccommodation) error {
+func (s *Service) UpdateAccommodation(ctx context.Context, id, version int, updateAccommodation models.UpdateAccommodation) error {
+	return s.PatchAccommodation(ctx, id, version, updateAccommodation, nil)
+}
 
-	sql := "UPDATE Accommodation SET Id_Project = @p1, City = @p2, Accommodation_Address = @p3, Number_Of_Places = @p4 WHERE Id_Accommodation = @p5;"
+// PatchAccommodation writes the given fields of the update, leaving the other
+// columns and the contact and payment details as they are unless fields has
+// them.
+func (s *Service) PatchAccommodation(ctx context.Context, id, version int, updateAccommodation models.UpdateAccommodation, fields models.Fields) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		return s.updateAccommodation(ctx, id, version, updateAccommodation, fields)
+	})
+}
 
-	_, err := s.DB.ExecContext(ctx, sql, updateAccommodation.ProjectID, updateAccommodation.City, updateAccommodation.AccommodationAddress, updateAccommodation.NumberOfPlaces, id)
+func (s *Service) updateAccommodation(ctx context.Context, id, version int, updateAccommodation models.UpdateAccommodation, fields models.Fields) error {
+	if err := s.accommodationVisible(ctx, id); err != nil {
+		return errors.Wrap(err, "failed to retrieve accommodation")
+	}
 
-	sql = "UPDATE Contact SET First_Name = @p1, Last_Name = @p2, Phone_Number = @p3 WHERE Id_Accommodation = @p4;"
+	if err := s.checkActive(ctx, "Accommodation", "Id_Accommodation", id); err != nil {
+		return err
+	}
 
-	_, err = s.DB.ExecContext(ctx, sql, updateAccommodation.Contact.FirstName, updateAccommodation.Contact.LastName, updateAccommodation.Contact.PhoneNumber, id)
+	if err := s.bumpVersion(ctx, "Accommodation", "Id_Accommodation", id, version); err != nil {
+		return err
+	}
 
-	sql = "UPDATE Payments SET Cost = @p1, Deposit = @p2, Contract = @p3, Account_Number = @p4, Payment_Day = @p5 WHERE Id_Accommodation = @p6;"
+	if fields.Has("ProjectID") {
+		if err := checkProject(ctx, updateAccommodation.ProjectID); err != nil {
+			return err
+		}
 
-	_, err = s.DB.ExecContext(ctx, sql, updateAccommodation.Payment.Cost, updateAccommodation.Payment.Deposit, updateAccommodation.Payment.Contract, updateAccommodation.Payment.AccountNumber, updateAccommodation.Payment.PaymentDay, id)
+		if err := s.checkReference(ctx, "Project", "Id_Project", updateAccommodation.ProjectID); err != nil {
+			return err
+		}
+	}
+
+	err := s.updateColumns(ctx, "Accommodation", "Id_Accommodation", id, []column{
+		{"Id_Project", updateAccommodation.ProjectID, fields.Has("ProjectID")},
+		{"City", updateAccommodation.City, fields.Has("City")},
+		{"Accommodation_Address", updateAccommodation.AccommodationAddress, fields.Has("AccommodationAddress")},
+		{"Number_Of_Places", updateAccommodation.NumberOfPlaces, fields.Has("NumberOfPlaces")},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to update accommodation")
+	}
+
+	if fields.Has("Contact") {
+		sql := "UPDATE Contact SET First_Name = @p1, Last_Name = @p2, Phone_Number = @p3 WHERE Id_Accommodation = @p4;"
+
+		_, err = s.conn(ctx).ExecContext(ctx, sql, updateAccommodation.Contact.FirstName, updateAccommodation.Contact.LastName, updateAccommodation.Contact.PhoneNumber, id)
+		if err != nil {
+			return errors.Wrap(err, "failed to update contact")
+		}
+	}
+
+	if !fields.Has("Payment") {
+		return nil
+	}
+
+	accountNumber, err := s.crypt.encrypt(fieldAccountNumber, updateAccommodation.Payment.AccountNumber)
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt account number")
+	}
+
+	sql := "UPDATE Payments SET Cost = @p1, Deposit = @p2, Contract = @p3, Account_Number = @p4, Payment_Day = @p5 WHERE Id_Accommodation = @p6;"
+
+	_, err = s.conn(ctx).ExecContext(ctx, sql, updateAccommodation.Payment.Cost, updateAccommodation.Payment.Deposit, updateAccommodation.Payment.Contract, accountNumber, updateAccommodation.Payment.PaymentDay, id)
 
 	return errors.Wrap(err, "failed to update accommodation")
 }
 
-func (s *Service) GetAccommodationAddresses(ctx context.Context) ([]models.AccommodationAddresses, error) {
-	sql := "SELECT a.Id_Accommodation,CONCAT(a.City,' ',a.Accommodation_Address) AS FullAddress FROM Accommodation a LEFT JOIN (SELECT Id_Accommodation,COUNT(*) AS OccupiedPlaces FROM Employee_Accommodation GROUP BY Id_Accommodation) ea ON a.Id_Accommodation=ea.Id_Accommodation WHERE a.Number_Of_Places>COALESCE(ea.OccupiedPlaces,0) ORDER BY FullAddress;"
+// GetAccommodationAddresses lists the accommodations with free places on the
+// given day.
+func (s *Service) GetAccommodationAddresses(ctx context.Context, asOf models.Date) ([]models.AccommodationAddresses, error) {
+	occupancy, args := occupancyOn(asOf, nil)
+	scope, args := projectFilter(ctx, "a.Id_Project", args)
 
-	rows, err := s.DB.QueryContext(ctx, sql)
+	sql := "SELECT a.Id_Accommodation,CONCAT(a.City,' ',a.Accommodation_Address) AS FullAddress FROM Accommodation a LEFT JOIN " + occupancy + " ea ON a.Id_Accommodation=ea.Id_Accommodation WHERE a.Archived_At IS NULL AND a.Number_Of_Places>COALESCE(ea.OccupiedPlaces,0) AND " + scope + " ORDER BY FullAddress;"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, sql, args...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to query for accommodation addresses")
 	}
@@ -116,3 +286,9 @@ func (s *Service) GetAccommodationAddresses(ctx context.Context) ([]models.Accom
 
 	return results, errors.Wrap(err, "failed to iterate rows")
 }
+
+func (s *Service) accommodationVisible(ctx context.Context, id int) error {
+	scope, args := projectFilter(ctx, "Id_Project", []any{id})
+
+	return s.checkVisible(ctx, "SELECT 1 FROM Accommodation WHERE Id_Accommodation = @p1 AND "+scope, args...)
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"api/internal/auth"
+	"api/internal/models"
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/pkg/errors"
+)
+
+// archivedFilter returns a condition restricting the table with the given
+// alias to active records, unless the filter includes archived ones.
+func archivedFilter(alias string, filter models.ListFilter) string {
+	if filter.IncludeArchived {
+		return "1 = 1"
+	}
+
+	return alias + ".Archived_At IS NULL"
+}
+
+// archivist returns the user archiving records in ctx.
+func archivist(ctx context.Context) mssql.VarChar {
+	principal, _ := auth.FromContext(ctx)
+
+	return mssql.VarChar(principal.Username)
+}
+
+// checkActive returns ErrArchived if a record the caller has already checked
+// to be visible has been archived and so may not be changed.
+func (s *Service) checkActive(ctx context.Context, table, column string, id int) error {
+	err := s.checkVisible(ctx, fmt.Sprintf("SELECT 1 FROM %s WHERE %s = @p1 AND Archived_At IS NULL", table, column), id)
+	if errors.Is(err, ErrNotFound) {
+		return ErrArchived
+	}
+
+	return errors.Wrapf(err, "failed to retrieve %s", table)
+}
+
+// archive marks a record as archived at the given time by the user in ctx.
+// It returns ErrArchived if the record already was.
+func (s *Service) archive(ctx context.Context, table, column string, id int, at time.Time) error {
+	sql := fmt.Sprintf("UPDATE %s SET Archived_At = @p2, Archived_By = @p3 WHERE %s = @p1 AND Archived_At IS NULL;", table, column)
+
+	res, err := s.conn(ctx).ExecContext(ctx, sql, id, mssql.DateTime1(at), archivist(ctx))
+	if err != nil {
+		return errors.Wrapf(err, "failed to archive %s", table)
+	}
+
+	if err = expectRows(res); errors.Is(err, ErrNotFound) {
+		return ErrArchived
+	}
+
+	return err
+}
+
+// restore brings back an archived record. It returns ErrNotFound if the
+// record is not archived.
+func (s *Service) restore(ctx context.Context, table, column string, id int) error {
+	sql := fmt.Sprintf("UPDATE %s SET Archived_At = NULL, Archived_By = NULL WHERE %s = @p1 AND Archived_At IS NOT NULL;", table, column)
+
+	res, err := s.conn(ctx).ExecContext(ctx, sql, id)
+	if err != nil {
+		return errors.Wrapf(err, "failed to restore %s", table)
+	}
+
+	return errors.Wrapf(expectRows(res), "failed to restore %s", table)
+}
+
+// checkReference returns ErrReferenced if a record that is about to be
+// referenced has been archived. Records that do not exist at all are left to
+// the foreign keys.
+func (s *Service) checkReference(ctx context.Context, table, column string, id int) error {
+	err := s.checkVisible(ctx, fmt.Sprintf("SELECT 1 FROM %s WHERE %s = @p1 AND Archived_At IS NOT NULL", table, column), id)
+	if err == nil {
+		return ErrReferenced
+	}
+
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+
+	return errors.Wrapf(err, "failed to retrieve %s", table)
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"api/internal/models"
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/pkg/errors"
+)
+
+// EmployeeAssignments returns the employee's assignments to the projects the
+// caller may access, most recent first.
+func (s *Service) EmployeeAssignments(ctx context.Context, employeeID int) ([]models.ProjectAssignment, error) {
+	if err := s.employeeVisible(ctx, employeeID); err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve employee")
+	}
+
+	scope, args := projectFilter(ctx, "ep.Id_Project", []any{employeeID})
+
+	sql := "SELECT ep.Id_Assignment, ep.Id_Project, p.Name, ep.Valid_From, ep.Valid_To FROM Employee_Project ep JOIN Project p ON ep.Id_Project = p.Id_Project WHERE ep.Id_Employee = @p1 AND " + scope + " ORDER BY ep.Valid_From DESC, ep.Id_Assignment DESC;"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for assignments")
+	}
+	defer rows.Close()
+
+	results := make([]models.ProjectAssignment, 0)
+
+	for rows.Next() {
+		var assignment models.ProjectAssignment
+		err = rows.Scan(&assignment.ID, &assignment.ProjectID, &assignment.ProjectName, &assignment.ValidFrom, &assignment.ValidTo)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+
+		results = append(results, assignment)
+	}
+
+	err = rows.Err()
+
+	return results, errors.Wrap(err, "failed to iterate rows")
+}
+
+// TransferEmployee closes the employee's current assignment on the day before
+// from and assigns them to the project starting on from.
+func (s *Service) TransferEmployee(ctx context.Context, id, version, projectID int, from models.Date) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		return s.transferEmployee(ctx, id, version, projectID, from)
+	})
+}
+
+func (s *Service) transferEmployee(ctx context.Context, id, version, projectID int, from models.Date) error {
+	if err := s.employeeVisible(ctx, id); err != nil {
+		return errors.Wrap(err, "failed to retrieve employee")
+	}
+
+	if err := s.checkActive(ctx, "Employee", "Id_Employee", id); err != nil {
+		return err
+	}
+
+	if err := s.bumpVersion(ctx, "Employee", "Id_Employee", id, version); err != nil {
+		return err
+	}
+
+	if err := checkProject(ctx, projectID); err != nil {
+		return err
+	}
+
+	if err := s.checkReference(ctx, "Project", "Id_Project", projectID); err != nil {
+		return err
+	}
+
+	return s.assignProject(ctx, id, projectID, from)
+}
+
+// assignProject makes projectID the employee's current project from the given
+// day on. An open assignment to another project is closed the day before, or
+// replaced if it started on that day or later, in which case an assignment to
+// the project that ended the day before is reopened instead. Assigning the
+// current project again does nothing.
+func (s *Service) assignProject(ctx context.Context, employeeID, projectID int, from models.Date) error {
+	var (
+		assignmentID, currentID int
+		validFrom               models.Date
+	)
+
+	sql := "SELECT Id_Assignment, Id_Project, Valid_From FROM Employee_Project WHERE Id_Employee = @p1 AND Valid_To IS NULL;"
+
+	err := s.conn(ctx).QueryRowContext(ctx, sql, employeeID).Scan(&assignmentID, &currentID, &validFrom)
+	switch {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		return errors.Wrap(err, "failed to retrieve assignment")
+	case currentID == projectID:
+		return nil
+	case time.Time(validFrom).Before(time.Time(from)):
+		sql = "UPDATE Employee_Project SET Valid_To = @p2 WHERE Id_Assignment = @p1;"
+
+		_, err = s.conn(ctx).ExecContext(ctx, sql, assignmentID, mssql.DateTime1(from.AddDays(-1)))
+		if err != nil {
+			return errors.Wrap(err, "failed to close assignment")
+		}
+	default:
+		sql = "DELETE FROM Employee_Project WHERE Id_Assignment = @p1;"
+
+		_, err = s.conn(ctx).ExecContext(ctx, sql, assignmentID)
+		if err != nil {
+			return errors.Wrap(err, "failed to replace assignment")
+		}
+
+		sql = "UPDATE Employee_Project SET Valid_To = NULL WHERE Id_Employee = @p1 AND Id_Project = @p2 AND Valid_To = @p3;"
+
+		res, err := s.conn(ctx).ExecContext(ctx, sql, employeeID, projectID, mssql.DateTime1(from.AddDays(-1)))
+		if err != nil {
+			return errors.Wrap(err, "failed to reopen assignment")
+		}
+
+		if err = expectRows(res); !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+
+	sql = "INSERT INTO Employee_Project (Id_Employee, Id_Project, Valid_From) VALUES (@p1, @p2, @p3);"
+
+	_, err = s.conn(ctx).ExecContext(ctx, sql, employeeID, projectID, mssql.DateTime1(from))
+
+	return errors.Wrap(err, "failed to add assignment")
+}
+
+// assignmentStart returns the day a new employee's first assignment starts:
+// the start of their employment, unless it is unknown or still ahead.
+func assignmentStart(start models.Date) models.Date {
+	today := models.Today()
+
+	if time.Time(start).IsZero() || time.Time(start).After(time.Time(today)) {
+		return today
+	}
+
+	return start
+}
+
+// assignedOn returns a condition restricting the assignments with alias ep,
+// joined with employees aliased e, to those in force on the given day,
+// together with args extended by the condition's parameters.
+func assignedOn(ep, e string, day models.Date, args []any) (string, []any) {
+	args = append(args, mssql.DateTime1(day), mssql.DateTime1(day.AddDays(1)))
+	from, next := len(args)-1, len(args)
+
+	return fmt.Sprintf("%[1]s.Valid_From <= @p%[3]d AND (%[1]s.Valid_To IS NULL OR %[1]s.Valid_To >= @p%[3]d) AND (%[2]s.Archived_At IS NULL OR %[2]s.Archived_At >= @p%[4]d)", ep, e, from, next), args
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/pkg/errors"
+
+	"api/internal/models"
+)
+
+func (s *Service) AddAuditEntry(ctx context.Context, entry models.AuditEntry) error {
+	sql := "INSERT INTO Audit_Log (Username, Occurred_At, Entity_Type, Entity_Id, Action, Changes) VALUES (@p1, @p2, @p3, @p4, @p5, @p6);"
+
+	_, err := s.conn(ctx).ExecContext(ctx, sql, entry.Username, mssql.DateTime1(entry.OccurredAt), string(entry.EntityType), entry.EntityID, string(entry.Action), string(entry.Changes))
+
+	return errors.Wrap(err, "failed to add audit entry")
+}
+
+// AuditEntries returns the newest entries matching the filter first.
+func (s *Service) AuditEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	var (
+		where = "1 = 1"
+		args  []any
+	)
+
+	if filter.EntityType != "" {
+		args = append(args, string(filter.EntityType))
+		where += fmt.Sprintf(" AND Entity_Type = @p%d", len(args))
+	}
+
+	if filter.EntityID != 0 {
+		args = append(args, filter.EntityID)
+		where += fmt.Sprintf(" AND Entity_Id = @p%d", len(args))
+	}
+
+	if filter.Username != "" {
+		args = append(args, filter.Username)
+		where += fmt.Sprintf(" AND Username = @p%d", len(args))
+	}
+
+	if filter.From != nil {
+		args = append(args, mssql.DateTime1(*filter.From))
+		where += fmt.Sprintf(" AND Occurred_At >= @p%d", len(args))
+	}
+
+	if filter.To != nil {
+		args = append(args, mssql.DateTime1(*filter.To))
+		where += fmt.Sprintf(" AND Occurred_At < @p%d", len(args))
+	}
+
+	sql := fmt.Sprintf("SELECT TOP %d Id_Audit, Username, Occurred_At, Entity_Type, Entity_Id, Action, Changes FROM Audit_Log WHERE %s ORDER BY Occurred_At DESC, Id_Audit DESC;", filter.Limit, where)
+
+	rows, err := s.conn(ctx).QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for audit entries")
+	}
+	defer rows.Close()
+
+	results := make([]models.AuditEntry, 0)
+
+	for rows.Next() {
+		var (
+			entry   models.AuditEntry
+			changes string
+		)
+
+		err = rows.Scan(&entry.ID, &entry.Username, &entry.OccurredAt, &entry.EntityType, &entry.EntityID, &entry.Action, &changes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+
+		entry.Changes = []byte(changes)
+		results = append(results, entry)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to iterate rows")
+	}
+
+	return results, nil
+}
@@ -3,16 +3,45 @@ package storage
 import (
 	"api/internal/models"
 	"context"
+	"fmt"
 	mssql "github.com/microsoft/go-mssqldb"
 	"github.com/pkg/errors"
+	"time"
 )
 
-func (s *Service) Cars(ctx context.Context) ([]models.Car, error) {
-	sql := "SELECT C.Id_Car, C.Model, C.Color, C.Registration_Number, C.VIN_Number, C.Inspection_From, C.Inspection_To, C.Insurance_From, C.Insurance_To, C.Fleet_Card_Number, C.Id_Project, Project.Name FROM Car C LEFT JOIN Project ON C.Id_Project = Project.Id_Project"
+// Cars returns the filter's page of the car list together with the number
+// of cars on all pages.
+func (s *Service) Cars(ctx context.Context, filter models.CarFilter) ([]models.Car, int, error) {
+	orderBy, err := carSort.orderBy(filter.Sort)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	scope, args := projectFilter(ctx, "C.Id_Project", nil)
+	scope += " AND " + archivedFilter("C", filter.ListFilter)
+
+	if filter.ProjectID != 0 {
+		args = append(args, filter.ProjectID)
+		scope += fmt.Sprintf(" AND C.Id_Project = @p%d", len(args))
+	}
+
+	if filter.ExpiringBefore != nil {
+		args = append(args, mssql.DateTime1(*filter.ExpiringBefore))
+		scope += fmt.Sprintf(" AND (C.Inspection_To < @p%[1]d OR C.Insurance_To < @p%[1]d)", len(args))
+	}
+
+	total, err := s.count(ctx, "FROM Car C WHERE "+scope, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pageClause, args := page(filter.ListFilter, args)
+
+	sql := "SELECT C.Id_Car, C.Model, C.Color, C.Registration_Number, C.VIN_Number, C.Inspection_From, C.Inspection_To, C.Insurance_From, C.Insurance_To, C.Fleet_Card_Number, C.Id_Project, Project.Name, C.Archived_At, COALESCE(C.Archived_By, '') FROM Car C LEFT JOIN Project ON C.Id_Project = Project.Id_Project WHERE " + scope + " " + orderBy + pageClause + ";"
 
-	rows, err := s.DB.QueryContext(ctx, sql)
+	rows, err := s.conn(ctx).QueryContext(ctx, sql, args...)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to query for cars")
+		return nil, 0, errors.Wrap(err, "failed to query for cars")
 	}
 	defer rows.Close()
 
@@ -20,9 +49,9 @@ func (s *Service) Cars(ctx context.Context) ([]models.Car, error) {
 
 	for rows.Next() {
 		var car models.Car
-		err = rows.Scan(&car.ID, &car.Model, &car.Color, &car.RegistrationNumber, &car.VIN, &car.InspectionFrom, &car.InspectionTo, &car.InsuranceFrom, &car.InsuranceTo, &car.FleetCardNumber, &car.ProjectID, &car.ProjectName)
+		err = rows.Scan(&car.ID, &car.Model, &car.Color, &car.RegistrationNumber, &car.VIN, &car.InspectionFrom, &car.InspectionTo, &car.InsuranceFrom, &car.InsuranceTo, &car.FleetCardNumber, &car.ProjectID, &car.ProjectName, &car.ArchivedAt, &car.ArchivedBy)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to scan row")
+			return nil, 0, errors.Wrap(err, "failed to scan row")
 		}
 
 		results = append(results, car)
@@ -30,65 +59,197 @@ func (s *Service) Cars(ctx context.Context) ([]models.Car, error) {
 
 	err = rows.Err()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to iterate rows")
+		return nil, 0, errors.Wrap(err, "failed to iterate rows")
 	}
 
-	return results, nil
+	return results, total, nil
 }
 
 func (s *Service) GetCar(ctx context.Context, id int) (models.Car, error) {
-	sql := "SELECT C.Id_Car,C.Model,C.Color,C.Registration_Number,C.VIN_Number,C.Inspection_From,C.Inspection_To,C.Insurance_From,C.Insurance_To,C.Fleet_Card_Number,C.Id_Project,P.Name,S.Service_Name,S.Address,S.Phone_Number,L.Amount,L.Monthly_Payment,L.Payment_Day FROM Car C LEFT JOIN Project P ON C.Id_Project=P.Id_Project LEFT JOIN Service S ON C.Id_Car=S.Id_Car LEFT JOIN Leasing L ON C.Id_Car=L.Id_Car WHERE C.Id_Car = @p1;"
+	scope, args := projectFilter(ctx, "C.Id_Project", []any{id})
+
+	sql := "SELECT C.Id_Car,C.Model,C.Color,C.Registration_Number,C.VIN_Number,C.Inspection_From,C.Inspection_To,C.Insurance_From,C.Insurance_To,C.Fleet_Card_Number,C.Id_Project,P.Name,S.Service_Name,S.Address,S.Phone_Number,L.Amount,L.Monthly_Payment,L.Payment_Day,C.Version,C.Archived_At,COALESCE(C.Archived_By,'') FROM Car C LEFT JOIN Project P ON C.Id_Project=P.Id_Project LEFT JOIN Service S ON C.Id_Car=S.Id_Car LEFT JOIN Leasing L ON C.Id_Car=L.Id_Car WHERE C.Id_Car = @p1 AND " + scope + ";"
 
 	var car models.Car
 
-	err := s.DB.QueryRowContext(ctx, sql, id).Scan(&car.ID, &car.Model, &car.Color, &car.RegistrationNumber, &car.VIN, &car.InspectionFrom, &car.InspectionTo, &car.InsuranceFrom, &car.InsuranceTo, &car.FleetCardNumber, &car.ProjectID, &car.ProjectName, &car.Service.ServiceName, &car.Service.Address, &car.Service.PhoneNumber, &car.Leasing.Amount, &car.Leasing.MonthlyPayment, &car.Leasing.PaymentDay)
+	err := s.conn(ctx).QueryRowContext(ctx, sql, args...).Scan(&car.ID, &car.Model, &car.Color, &car.RegistrationNumber, &car.VIN, &car.InspectionFrom, &car.InspectionTo, &car.InsuranceFrom, &car.InsuranceTo, &car.FleetCardNumber, &car.ProjectID, &car.ProjectName, &car.Service.ServiceName, &car.Service.Address, &car.Service.PhoneNumber, &car.Leasing.Amount, &car.Leasing.MonthlyPayment, &car.Leasing.PaymentDay, &car.Version, &car.ArchivedAt, &car.ArchivedBy)
 
 	return car, errors.Wrap(err, "failed to retrieve car")
 }
 
 func (s *Service) AddCar(ctx context.Context, newCar models.NewCar) (id int, err error) {
+	err = s.InTx(ctx, func(ctx context.Context) error {
+		id, err = s.addCar(ctx, newCar)
+		return err
+	})
+
+	return id, err
+}
+
+func (s *Service) addCar(ctx context.Context, newCar models.NewCar) (id int, err error) {
+	if err = checkProject(ctx, newCar.IdProject); err != nil {
+		return 0, err
+	}
+
+	if err = s.checkReference(ctx, "Project", "Id_Project", newCar.IdProject); err != nil {
+		return 0, err
+	}
+
 	sql := "INSERT INTO Car (Model, Color, Registration_Number, VIN_Number, Inspection_From, Inspection_To, Insurance_From, Insurance_To, Fleet_Card_Number, Id_Project) VALUES (@p1,@p2,@p3,@p4,@p5,@p6,@p7,@p8,@p9,@p10); SELECT SCOPE_IDENTITY() AS Id_Car;"
 
-	err = s.DB.QueryRowContext(ctx, sql, newCar.Model, newCar.Color, newCar.RegistrationNumber, newCar.VIN, mssql.DateTime1(newCar.InspectionFrom), mssql.DateTime1(newCar.InspectionTo), mssql.DateTime1(newCar.InsuranceFrom), mssql.DateTime1(newCar.InsuranceTo), newCar.FleetCardNumber, newCar.IdProject).Scan(&id)
+	err = s.conn(ctx).QueryRowContext(ctx, sql, newCar.Model, newCar.Color, newCar.RegistrationNumber, newCar.VIN, mssql.DateTime1(newCar.InspectionFrom), mssql.DateTime1(newCar.InspectionTo), mssql.DateTime1(newCar.InsuranceFrom), mssql.DateTime1(newCar.InsuranceTo), newCar.FleetCardNumber, newCar.IdProject).Scan(&id)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to add car")
 	}
 
 	sql = "INSERT INTO [Service] (Id_Car, Service_name, Address, Phone_Number) VALUES (@p1,@p2,@p3,@p4)"
 
-	_, err = s.DB.ExecContext(ctx, sql, id, newCar.Service.ServiceName, newCar.Service.Address, newCar.Service.PhoneNumber)
+	_, err = s.conn(ctx).ExecContext(ctx, sql, id, newCar.Service.ServiceName, newCar.Service.Address, newCar.Service.PhoneNumber)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to add service")
 	}
 
 	sql = "INSERT INTO Leasing (Id_Car, Amount, Monthly_Payment, Payment_Day) VALUES (@p1,@p2,@p3,@p4)"
 
-	_, err = s.DB.ExecContext(ctx, sql, id, newCar.Leasing.Amount, newCar.Leasing.MonthlyPayment, newCar.Leasing.PaymentDay)
+	_, err = s.conn(ctx).ExecContext(ctx, sql, id, newCar.Leasing.Amount, newCar.Leasing.MonthlyPayment, newCar.Leasing.PaymentDay)
 
 	return id, errors.Wrap(err, "failed to add leasing")
 }
 
-func (s *Service) RemoveCar(ctx context.Context, id int) error {
-	sql := "DELETE FROM Leasing WHERE Id_Car = @p1; DELETE FROM Service WHERE Id_Car = @p1; DELETE FROM Employee_Car WHERE Id_Car = @p1; DELETE FROM Car WHERE Id_Car = @p1; "
+// ArchiveCar archives the car and ends the periods of its drivers.
+func (s *Service) ArchiveCar(ctx context.Context, id, version int) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		return s.archiveCar(ctx, id, version)
+	})
+}
+
+func (s *Service) archiveCar(ctx context.Context, id, version int) error {
+	if err := s.carVisible(ctx, id); err != nil {
+		return errors.Wrap(err, "failed to retrieve car")
+	}
+
+	if err := s.bumpVersion(ctx, "Car", "Id_Car", id, version); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	if err := s.archive(ctx, "Car", "Id_Car", id, now); err != nil {
+		return err
+	}
+
+	return s.returnCars(ctx, now, "Id_Car = @p1", id)
+}
+
+// RestoreCar brings back an archived car. It returns ErrNotFound if the car
+// is not archived and ErrReferenced while its project is.
+func (s *Service) RestoreCar(ctx context.Context, id, version int) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		return s.restoreCar(ctx, id, version)
+	})
+}
+
+func (s *Service) restoreCar(ctx context.Context, id, version int) error {
+	if err := s.carVisible(ctx, id); err != nil {
+		return errors.Wrap(err, "failed to retrieve car")
+	}
+
+	if err := s.bumpVersion(ctx, "Car", "Id_Car", id, version); err != nil {
+		return err
+	}
+
+	if err := s.restore(ctx, "Car", "Id_Car", id); err != nil {
+		return err
+	}
+
+	var projectID int
+
+	err := s.conn(ctx).QueryRowContext(ctx, "SELECT Id_Project FROM Car WHERE Id_Car = @p1", id).Scan(&projectID)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve car")
+	}
+
+	return s.checkReference(ctx, "Project", "Id_Project", projectID)
+}
 
-	_, err := s.DB.ExecContext(ctx, sql, id)
+func (s *Service) UpdateCar(ctx context.Context, id, version int, updateCar models.UpdateCar) error {
+	return s.PatchCar(ctx, id, version, updateCar, nil)
+}
 
-	return errors.Wrap(err, "failed to remove car")
+// PatchCar writes the given fields of the update, leaving the other columns
+// and the service and leasing details as they are unless fields has them.
+func (s *Service) PatchCar(ctx context.Context, id, version int, updateCar models.UpdateCar, fields models.Fields) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		return s.updateCar(ctx, id, version, updateCar, fields)
+	})
 }
 
-func (s *Service) UpdateCar(ctx context.Context, id int, updateCar models.UpdateCar) error {
+func (s *Service) updateCar(ctx context.Context, id, version int, updateCar models.UpdateCar, fields models.Fields) error {
+	if err := s.carVisible(ctx, id); err != nil {
+		return errors.Wrap(err, "failed to retrieve car")
+	}
+
+	if err := s.checkActive(ctx, "Car", "Id_Car", id); err != nil {
+		return err
+	}
+
+	if err := s.bumpVersion(ctx, "Car", "Id_Car", id, version); err != nil {
+		return err
+	}
+
+	if fields.Has("IdProject") {
+		if err := checkProject(ctx, updateCar.IdProject); err != nil {
+			return err
+		}
+
+		if err := s.checkReference(ctx, "Project", "Id_Project", updateCar.IdProject); err != nil {
+			return err
+		}
+	}
+
+	err := s.updateColumns(ctx, "Car", "Id_Car", id, []column{
+		{"Model", updateCar.Model, fields.Has("Model")},
+		{"Color", updateCar.Color, fields.Has("Color")},
+		{"Registration_Number", updateCar.RegistrationNumber, fields.Has("RegistrationNumber")},
+		{"VIN_Number", updateCar.VIN, fields.Has("VIN")},
+		{"Inspection_From", mssql.DateTime1(updateCar.InspectionFrom), fields.Has("InspectionFrom")},
+		{"Inspection_To", mssql.DateTime1(updateCar.InspectionTo), fields.Has("InspectionTo")},
+		{"Insurance_From", mssql.DateTime1(updateCar.InsuranceFrom), fields.Has("InsuranceFrom")},
+		{"Insurance_To", mssql.DateTime1(updateCar.InsuranceTo), fields.Has("InsuranceTo")},
+		{"Fleet_Card_Number", updateCar.FleetCardNumber, fields.Has("FleetCardNumber")},
+		{"Id_Project", updateCar.IdProject, fields.Has("IdProject")},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to update car")
+	}
+
+	if fields.Has("Service") {
+		sql := "UPDATE Service SET Service_Name = @p1, Address = @p2, Phone_Number = @p3 WHERE Id_Car = @p4;"
+
+		_, err = s.conn(ctx).ExecContext(ctx, sql, updateCar.Service.ServiceName, updateCar.Service.Address, updateCar.Service.PhoneNumber, id)
+		if err != nil {
+			return errors.Wrap(err, "failed to update service")
+		}
+	}
 
-	sql := "UPDATE Car SET Model = @p1, Color = @p2, Registration_Number = @p3, VIN_Number = @p4, Inspection_From = @p5, Inspection_To = @p6, Insurance_From = @p7, Insurance_To = @p8, Fleet_Card_Number = @p9, Id_Project = @p10 WHERE Id_Car = @p17;UPDATE Service SET Service_Name = @p11, Address = @p12, Phone_Number = @p13 WHERE Id_Car = @p17; UPDATE Leasing SET Amount = @p14, Monthly_Payment = @p15, Payment_Day = @p16 WHERE Id_Car = @p17;"
+	if fields.Has("Leasing") {
+		sql := "UPDATE Leasing SET Amount = @p1, Monthly_Payment = @p2, Payment_Day = @p3 WHERE Id_Car = @p4;"
 
-	_, err2 := s.DB.ExecContext(ctx, sql, updateCar.Model, updateCar.Color, updateCar.RegistrationNumber, updateCar.VIN, mssql.DateTime1(updateCar.InspectionFrom), mssql.DateTime1(updateCar.InspectionTo), mssql.DateTime1(updateCar.InsuranceFrom), mssql.DateTime1(updateCar.InsuranceTo), updateCar.FleetCardNumber, updateCar.IdProject, updateCar.Service.ServiceName, updateCar.Service.Address, updateCar.Service.PhoneNumber, updateCar.Leasing.Amount, updateCar.Leasing.MonthlyPayment, updateCar.Leasing.PaymentDay, id)
+		_, err = s.conn(ctx).ExecContext(ctx, sql, updateCar.Leasing.Amount, updateCar.Leasing.MonthlyPayment, updateCar.Leasing.PaymentDay, id)
+		if err != nil {
+			return errors.Wrap(err, "failed to update leasing")
+		}
+	}
 
-	return errors.Wrap(err2, "failed to update car")
+	return nil
 }
 
 func (s *Service) GetCarNumbers(ctx context.Context) ([]models.CarNumbers, error) {
-	sql := "SELECT c.Id_Car, c.Registration_Number FROM Car c ORDER BY c.Registration_Number;"
+	scope, args := projectFilter(ctx, "c.Id_Project", nil)
 
-	rows, err := s.DB.QueryContext(ctx, sql)
+	sql := "SELECT c.Id_Car, c.Registration_Number FROM Car c WHERE c.Archived_At IS NULL AND " + scope + " ORDER BY c.Registration_Number;"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, sql, args...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to query for car names")
 	}
@@ -112,3 +273,9 @@ func (s *Service) GetCarNumbers(ctx context.Context) ([]models.CarNumbers, error
 
 	return results, errors.Wrap(err, "failed to iterate rows")
 }
+
+func (s *Service) carVisible(ctx context.Context, id int) error {
+	scope, args := projectFilter(ctx, "Id_Project", []any{id})
+
+	return s.checkVisible(ctx, "SELECT 1 FROM Car WHERE Id_Car = @p1 AND "+scope, args...)
+}
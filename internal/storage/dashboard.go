@@ -7,10 +7,15 @@ import (
 	"github.com/pkg/errors"
 )
 
-func (s *Service) DashboardEmployeeProjects(ctx context.Context) ([]models.DashboardEmployeesProject, error) {
-	sql := "WITH ProjectCounts AS (SELECT COUNT(*) AS Count, [Name] FROM Employee_Project pp JOIN Project pro ON pro.Id_Project = pp.Id_Project GROUP BY [Name]), RankedProjects AS (SELECT [Name], Count, ROW_NUMBER() OVER (ORDER BY Count DESC) AS RowNum FROM ProjectCounts), TopProjects AS (SELECT [Name], Count FROM RankedProjects WHERE RowNum <= 10 UNION ALL SELECT 'Pozostałe' AS [Name], SUM(Count) AS Count FROM RankedProjects WHERE RowNum > 10) SELECT [Name], Count FROM TopProjects ORDER BY Count DESC;"
+// DashboardEmployeeProjects counts the employees assigned to each project on
+// the given day.
+func (s *Service) DashboardEmployeeProjects(ctx context.Context, asOf models.Date) ([]models.DashboardEmployeesProject, error) {
+	assigned, args := assignedOn("pp", "e", asOf, nil)
+	scope, args := projectFilter(ctx, "pro.Id_Project", args)
 
-	rows, err := s.DB.QueryContext(ctx, sql)
+	sql := "WITH ProjectCounts AS (SELECT COUNT(*) AS Count, [Name] FROM Employee_Project pp JOIN Project pro ON pro.Id_Project = pp.Id_Project JOIN Employee e ON e.Id_Employee = pp.Id_Employee WHERE pro.Archived_At IS NULL AND " + assigned + " AND " + scope + " GROUP BY [Name]), RankedProjects AS (SELECT [Name], Count, ROW_NUMBER() OVER (ORDER BY Count DESC) AS RowNum FROM ProjectCounts), TopProjects AS (SELECT [Name], Count FROM RankedProjects WHERE RowNum <= 10 UNION ALL SELECT 'Pozostałe' AS [Name], SUM(Count) AS Count FROM RankedProjects WHERE RowNum > 10 HAVING COUNT(*) > 0) SELECT [Name], Count FROM TopProjects ORDER BY Count DESC;"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, sql, args...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to query for dashboard employee projects")
 	}
@@ -37,10 +42,15 @@ func (s *Service) DashboardEmployeeProjects(ctx context.Context) ([]models.Dashb
 	return results, errors.Wrap(err, "failed to iterate over rows")
 }
 
-func (s *Service) Accommodation(ctx context.Context) ([]models.DashboardAccommodation, error) {
-	sql := "SELECT TOP 10 p.Name AS ProjectName, SUM(a.Number_Of_Places - COALESCE(ea.OccupiedPlaces, 0)) AS free, SUM(COALESCE(ea.OccupiedPlaces, 0)) AS taken FROM Project p LEFT JOIN Accommodation a ON p.Id_Project = a.Id_Project LEFT JOIN (SELECT ea.Id_Accommodation, COUNT(ea.Id_Employee) AS OccupiedPlaces FROM Employee_Accommodation ea GROUP BY ea.Id_Accommodation) ea ON a.Id_Accommodation = ea.Id_Accommodation GROUP BY p.Name ORDER BY free DESC"
+// Accommodation sums up the free and taken places of each project on the
+// given day.
+func (s *Service) Accommodation(ctx context.Context, asOf models.Date) ([]models.DashboardAccommodation, error) {
+	occupancy, args := occupancyOn(asOf, nil)
+	scope, args := projectFilter(ctx, "p.Id_Project", args)
+
+	sql := "SELECT TOP 10 p.Name AS ProjectName, COALESCE(SUM(a.Number_Of_Places - COALESCE(ea.OccupiedPlaces, 0)), 0) AS free, COALESCE(SUM(ea.OccupiedPlaces), 0) AS taken FROM Project p LEFT JOIN Accommodation a ON p.Id_Project = a.Id_Project AND a.Archived_At IS NULL LEFT JOIN " + occupancy + " ea ON a.Id_Accommodation = ea.Id_Accommodation WHERE p.Archived_At IS NULL AND " + scope + " GROUP BY p.Name ORDER BY free DESC"
 
-	rows, err := s.DB.QueryContext(ctx, sql)
+	rows, err := s.conn(ctx).QueryContext(ctx, sql, args...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to query for dashboard accommodation")
 	}
@@ -73,9 +83,11 @@ func (s *Service) Accommodation(ctx context.Context) ([]models.DashboardAccommod
 }
 
 func (s *Service) CarInspections(ctx context.Context) ([]models.DashboardCarInspection, error) {
-	sql := "Select TOP 5 Inspection_to, Registration_number from car order by Inspection_To"
+	scope, args := projectFilter(ctx, "Id_Project", nil)
 
-	rows, err := s.DB.QueryContext(ctx, sql)
+	sql := "Select TOP 5 Inspection_to, Registration_number from car where Archived_At IS NULL AND " + scope + " order by Inspection_To"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, sql, args...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to query for dashboard car inspections")
 	}
@@ -106,9 +118,19 @@ func (s *Service) CarInspections(ctx context.Context) ([]models.DashboardCarInsp
 }
 
 func (s *Service) EmployeePermits(ctx context.Context) ([]models.DashboardEmployeePermits, error) {
-	sql := "SELECT TOP 50 E.First_Name, E.Last_Name, 'OSH' AS Document, M.OSH_Valid_Until AS Expiry_Date FROM Employee E INNER JOIN Medicals M ON E.Id_Employee = M.Id_Employee WHERE M.OSH_Valid_Until IS NOT NULL UNION ALL SELECT E.First_Name, E.Last_Name, 'Psychotests' AS Document, M.Psychotests_Valid_Until AS Expiry_Date FROM Employee E INNER JOIN Medicals M ON E.Id_Employee = M.Id_Employee WHERE M.Psychotests_Valid_Until IS NOT NULL UNION ALL SELECT E.First_Name, E.Last_Name, 'Medical' AS Document, M.Medical_Valid_Until AS Expiry_Date FROM Employee E INNER JOIN Medicals M ON E.Id_Employee = M.Id_Employee WHERE M.Medical_Valid_Until IS NOT NULL UNION ALL SELECT E.First_Name, E.Last_Name, 'Sanitary' AS Document, M.Sanitary_Valid_Until AS Expiry_Date FROM Employee E INNER JOIN Medicals M ON E.Id_Employee = M.Id_Employee WHERE M.Sanitary_Valid_Until IS NOT NULL UNION ALL SELECT E.First_Name, E.Last_Name, 'Bio' AS Document, R.Bio AS Expiry_Date FROM Employee E INNER JOIN Residence_Card R ON E.Id_Employee = R.Employee_Id WHERE R.Bio IS NOT NULL UNION ALL SELECT E.First_Name, E.Last_Name, 'Visa' AS Document, R.Visa AS Expiry_Date FROM Employee E INNER JOIN Residence_Card R ON E.Id_Employee = R.Employee_Id WHERE R.Visa IS NOT NULL UNION ALL SELECT E.First_Name, E.Last_Name, 'TCard' AS Document, R.Tcard AS Expiry_Date FROM Employee E INNER JOIN Residence_Card R ON E.Id_Employee = R.Employee_Id WHERE R.Tcard IS NOT NULL ORDER BY Expiry_Date ASC;\n"
-
-	rows, err := s.DB.QueryContext(ctx, sql)
+	scope, args := employeeFilter(ctx, "d.Id_Employee", nil)
+
+	sql := "SELECT TOP 50 d.First_Name, d.Last_Name, d.Document, d.Expiry_Date FROM (" +
+		"SELECT E.Id_Employee, E.First_Name, E.Last_Name, 'OSH' AS Document, M.OSH_Valid_Until AS Expiry_Date FROM Employee E INNER JOIN Medicals M ON E.Id_Employee = M.Id_Employee WHERE M.OSH_Valid_Until IS NOT NULL " +
+		"UNION ALL SELECT E.Id_Employee, E.First_Name, E.Last_Name, 'Psychotests' AS Document, M.Psychotests_Valid_Until AS Expiry_Date FROM Employee E INNER JOIN Medicals M ON E.Id_Employee = M.Id_Employee WHERE M.Psychotests_Valid_Until IS NOT NULL " +
+		"UNION ALL SELECT E.Id_Employee, E.First_Name, E.Last_Name, 'Medical' AS Document, M.Medical_Valid_Until AS Expiry_Date FROM Employee E INNER JOIN Medicals M ON E.Id_Employee = M.Id_Employee WHERE M.Medical_Valid_Until IS NOT NULL " +
+		"UNION ALL SELECT E.Id_Employee, E.First_Name, E.Last_Name, 'Sanitary' AS Document, M.Sanitary_Valid_Until AS Expiry_Date FROM Employee E INNER JOIN Medicals M ON E.Id_Employee = M.Id_Employee WHERE M.Sanitary_Valid_Until IS NOT NULL " +
+		"UNION ALL SELECT E.Id_Employee, E.First_Name, E.Last_Name, 'Bio' AS Document, R.Bio AS Expiry_Date FROM Employee E INNER JOIN Residence_Card R ON E.Id_Employee = R.Employee_Id WHERE R.Bio IS NOT NULL " +
+		"UNION ALL SELECT E.Id_Employee, E.First_Name, E.Last_Name, 'Visa' AS Document, R.Visa AS Expiry_Date FROM Employee E INNER JOIN Residence_Card R ON E.Id_Employee = R.Employee_Id WHERE R.Visa IS NOT NULL " +
+		"UNION ALL SELECT E.Id_Employee, E.First_Name, E.Last_Name, 'TCard' AS Document, R.Tcard AS Expiry_Date FROM Employee E INNER JOIN Residence_Card R ON E.Id_Employee = R.Employee_Id WHERE R.Tcard IS NOT NULL" +
+		") d JOIN Employee emp ON emp.Id_Employee = d.Id_Employee WHERE emp.Archived_At IS NULL AND " + scope + " ORDER BY d.Expiry_Date ASC;"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, sql, args...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to query for dashboard car inspections")
 	}
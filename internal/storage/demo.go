@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"api/internal/auth"
+	"api/internal/models"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Credentials of the administrator account created by NewDemo.
+const (
+	DemoLogin    = "admin"
+	DemoPassword = "demo"
+)
+
+// NewDemo returns a Memory filled with a few projects, accommodations, cars
+// and employees, and an administrator account to log in with.
+func NewDemo() (*Memory, error) {
+	m := NewMemory()
+	ctx := auth.NewContext(context.Background(), auth.System)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	day := func(days int) models.Date {
+		return models.Date(today.AddDate(0, 0, days))
+	}
+
+	err := m.InTx(ctx, func(ctx context.Context) error {
+		projects := []models.NewProject{
+			{Name: "Magazyn Poznań", OfficeAddress: "ul. Głogowska 12, Poznań", ProjectNIP: "7781234563", FirstName: "Anna", LastName: "Nowak", Phone: "+48 600 100 200", Position: "Kierownik"},
+			{Name: "Budowa Wrocław", OfficeAddress: "ul. Legnicka 40, Wrocław", ProjectNIP: "8961234565", FirstName: "Piotr", LastName: "Kowalski", Phone: "+48 600 300 400", Position: "Koordynator"},
+		}
+
+		var projectIDs []int
+		for _, p := range projects {
+			id, err := m.AddProject(ctx, p)
+			if err != nil {
+				return err
+			}
+			projectIDs = append(projectIDs, id)
+		}
+
+		accommodations := []models.NewAccommodation{
+			{ProjectID: projectIDs[0], City: "Poznań", Address: "ul. Dąbrowskiego 5/3", NumberOfPlaces: 4, Contact: models.UpdateContactDetails{FirstName: "Jan", LastName: "Wiśniewski", PhoneNumber: "+48 601 111 222"}, Payment: models.UpdatePaymentDetails{Cost: 3200, Deposit: 3200, Contract: "Najem okazjonalny", AccountNumber: "61109010140000071219812874", PaymentDay: 10}},
+			{ProjectID: projectIDs[1], City: "Wrocław", Address: "ul. Grabiszyńska 88/14", NumberOfPlaces: 6, Contact: models.UpdateContactDetails{FirstName: "Ewa", LastName: "Zielińska", PhoneNumber: "+48 602 333 444"}, Payment: models.UpdatePaymentDetails{Cost: 4500, Deposit: 4500, Contract: "Najem", AccountNumber: "27114020040000300201355387", PaymentDay: 1}},
+		}
+
+		var accommodationIDs []int
+		for _, a := range accommodations {
+			id, err := m.AddAccommodation(ctx, a)
+			if err != nil {
+				return err
+			}
+			accommodationIDs = append(accommodationIDs, id)
+		}
+
+		cars := []models.NewCar{
+			{Model: "Toyota Proace", Color: "biały", RegistrationNumber: "PO1234A", VIN: "VF1RFB00956789012", InspectionFrom: day(-300), InspectionTo: day(65), InsuranceFrom: day(-200), InsuranceTo: day(165), FleetCardNumber: "7001 0001", IdProject: projectIDs[0], Service: models.Service{ServiceName: "Auto Serwis", Address: "ul. Warszawska 3, Poznań", PhoneNumber: "+48 61 800 90 00"}, Leasing: models.Leasing{Amount: 120000, MonthlyPayment: 2100, PaymentDay: 15}},
+			{Model: "Ford Transit", Color: "srebrny", RegistrationNumber: "DW5678B", VIN: "WF0XXXTT5XKA12345", InspectionFrom: day(-350), InspectionTo: day(15), InsuranceFrom: day(-100), InsuranceTo: day(265), FleetCardNumber: "7001 0002", IdProject: projectIDs[1], Service: models.Service{ServiceName: "Ford Serwis", Address: "ul. Krakowska 180, Wrocław", PhoneNumber: "+48 71 700 80 00"}, Leasing: models.Leasing{Amount: 150000, MonthlyPayment: 2600, PaymentDay: 20}},
+		}
+
+		var carIDs []int
+		for _, c := range cars {
+			id, err := m.AddCar(ctx, c)
+			if err != nil {
+				return err
+			}
+			carIDs = append(carIDs, id)
+		}
+
+		employees := []models.NewEmployee{
+			{LastName: "Kowalczyk", FirstName: "Marek", PassportNumber: "EA1234567", Pesel: "85010112345", Email: "marek.kowalczyk@example.com", DateOfBirth: models.Date(time.Date(1985, 1, 1, 0, 0, 0, 0, time.UTC)), BankAccount: "83101010230000261395100000", AddressPoland: "ul. Dąbrowskiego 5/3, Poznań", Employment: models.NewEmploymentDetails{ContractType: "umowa o pracę", StartDate: day(-400)}, Medicals: models.NewMedicalDetails{OSHValidUntil: day(30), MedicalValidUntil: day(120)}, ProjectId: projectIDs[0], AccommodationId: accommodationIDs[0]},
+			{LastName: "Shevchenko", FirstName: "Olena", PassportNumber: "FE987654", Email: "olena.shevchenko@example.com", DateOfBirth: models.Date(time.Date(1992, 6, 14, 0, 0, 0, 0, time.UTC)), BankAccount: "10105000997603123456789123", AddressPoland: "ul. Grabiszyńska 88/14, Wrocław", Employment: models.NewEmploymentDetails{ContractType: "umowa zlecenie", StartDate: day(-90)}, Medicals: models.NewMedicalDetails{OSHValidUntil: day(7), MedicalValidUntil: day(200)}, ProjectId: projectIDs[1], AccommodationId: accommodationIDs[1]},
+			{LastName: "Bondarenko", FirstName: "Andrii", PassportNumber: "FK112233", Email: "andrii.bondarenko@example.com", DateOfBirth: models.Date(time.Date(1990, 3, 22, 0, 0, 0, 0, time.UTC)), BankAccount: "09102028920000550201234567", AddressPoland: "ul. Grabiszyńska 88/14, Wrocław", Employment: models.NewEmploymentDetails{ContractType: "umowa o pracę", StartDate: day(-30)}, Medicals: models.NewMedicalDetails{OSHValidUntil: day(90), MedicalValidUntil: day(60)}, ProjectId: projectIDs[1], AccommodationId: accommodationIDs[1]},
+		}
+
+		var employeeIDs []int
+		for _, e := range employees {
+			id, err := m.AddEmployee(ctx, e)
+			if err != nil {
+				return err
+			}
+			employeeIDs = append(employeeIDs, id)
+		}
+
+		// The first two employees were handed the cars of their projects.
+		for i, carID := range carIDs {
+			_, err := m.AddHandover(ctx, carID, models.NewHandover{
+				ToEmployeeID: &employeeIDs[i],
+				HandedAt:     ptr(time.Time(day(-30))),
+				Odometer:     42000 + 9000*i,
+				FuelLevel:    100,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(DemoPassword), bcrypt.DefaultCost)
+		if err != nil {
+			return errors.Wrap(err, "failed to hash demo password")
+		}
+
+		return m.SetEmployeeAccount(ctx, employeeIDs[0], DemoLogin, string(hash), models.RoleAdmin)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to seed demo data")
+	}
+
+	return m, nil
+}
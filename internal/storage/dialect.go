@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/pkg/errors"
+)
+
+// dialect adapts the T-SQL the queries are written in to the database the
+// Service is connected to.
+type dialect interface {
+	// name is the directory of the dialect's migrations.
+	name() string
+	// migrationsTable creates the schema_migrations table unless it exists.
+	migrationsTable() string
+	// rebind rewrites a query written for SQL Server.
+	rebind(query string) string
+	// arg converts a query argument to a type the driver accepts.
+	arg(v any) any
+}
+
+// openParams derives the database/sql driver name, data source name and
+// dialect from DATABASE_URL. URLs with the sqlite scheme, e.g.
+// sqlite:///var/lib/api/data.db, open an embedded SQLite file; everything
+// else is passed to the SQL Server driver.
+func openParams(databaseURL string) (driver, dsn string, d dialect, err error) {
+	path, ok := strings.CutPrefix(databaseURL, "sqlite://")
+	if !ok {
+		return "sqlserver", databaseURL, sqlServerDialect{}, nil
+	}
+
+	path, query, _ := strings.Cut(path, "?")
+	if path == "" {
+		return "", "", nil, errors.New("sqlite database URL is missing a file path")
+	}
+
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return "", "", nil, errors.Wrap(err, "failed to parse sqlite database URL")
+	}
+
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Set("_time_format", "sqlite")
+	params.Set("_txlock", "immediate")
+
+	return "sqlite", fmt.Sprintf("file:%s?%s", path, params.Encode()), sqliteDialect{}, nil
+}
+
+// sqlServerDialect runs queries as they are written.
+type sqlServerDialect struct{}
+
+func (sqlServerDialect) name() string { return "sqlserver" }
+
+func (sqlServerDialect) migrationsTable() string {
+	return "IF OBJECT_ID(N'schema_migrations', N'U') IS NULL CREATE TABLE schema_migrations (Version INT NOT NULL PRIMARY KEY, Name NVARCHAR(255) NOT NULL, Applied_At DATETIME2 NOT NULL);"
+}
+
+func (sqlServerDialect) rebind(query string) string { return query }
+
+func (sqlServerDialect) arg(v any) any { return v }
+
+var (
+	sqlitePlaceholder = regexp.MustCompile(`@p(\d+)`)
+	sqliteIdentity    = regexp.MustCompile(`(?i);\s*SELECT\s+SCOPE_IDENTITY\(\)\s+AS\s+(\w+)[;\s]*$`)
+	sqliteTop         = regexp.MustCompile(`(?is)^(\s*SELECT)\s+TOP\s+(\d+)\s(.*?)[;\s]*$`)
+	sqliteOffset      = regexp.MustCompile(`(?i)\sOFFSET\s+(\S+)\s+ROWS\s+FETCH\s+NEXT\s+(\S+)\s+ROWS\s+ONLY`)
+	sqliteOutput      = regexp.MustCompile(`(?is)^(\s*UPDATE\s.*?)\s+OUTPUT\s+INSERTED\.(\w+)\s(.*?)[;\s]*$`)
+)
+
+// sqliteDialect translates the T-SQL specifics used by the queries: @pN parameters,
+// SELECT TOP n, OFFSET ... FETCH NEXT, SCOPE_IDENTITY() after an INSERT and
+// OUTPUT INSERTED of a column of an UPDATE.
+type sqliteDialect struct{}
+
+func (sqliteDialect) name() string { return "sqlite" }
+
+func (sqliteDialect) migrationsTable() string {
+	return "CREATE TABLE IF NOT EXISTS schema_migrations (Version INTEGER NOT NULL PRIMARY KEY, Name TEXT NOT NULL, Applied_At DATETIME NOT NULL);"
+}
+
+func (sqliteDialect) rebind(query string) string {
+	query = sqlitePlaceholder.ReplaceAllString(query, "?$1")
+	query = sqliteIdentity.ReplaceAllString(query, " RETURNING rowid AS $1;")
+	query = sqliteTop.ReplaceAllString(query, "$1 $3 LIMIT $2;")
+	query = sqliteOffset.ReplaceAllString(query, " LIMIT $2 OFFSET $1")
+	query = sqliteOutput.ReplaceAllString(query, "$1 $3 RETURNING $2;")
+
+	return query
+}
+
+// arg unwraps the SQL Server specific parameter types and stores times in
+// UTC, so that they compare correctly as text.
+func (sqliteDialect) arg(v any) any {
+	switch v := v.(type) {
+	case mssql.DateTime1:
+		return time.Time(v).UTC()
+	case mssql.VarChar:
+		return string(v)
+	case time.Time:
+		return v.UTC()
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return v.UTC()
+	}
+
+	return v
+}
@@ -1,19 +1,65 @@
 package storage
 
 import (
+	"api/internal/auth"
 	"api/internal/models"
 	"context"
-	"database/sql"
+	"fmt"
 	mssql "github.com/microsoft/go-mssqldb"
 	"github.com/pkg/errors"
+	"time"
 )
 
-func (s *Service) Employees(ctx context.Context) ([]models.Employee, error) {
-	sql := `SELECT e.Id_Employee, e.First_name, e.Last_name, e.Pesel, e.Passport_number, e.Date_of_birth from employee e`
+// Employees returns the filter's page of the employee list together with
+// the number of employees on all pages.
+func (s *Service) Employees(ctx context.Context, filter models.EmployeeFilter) ([]models.Employee, int, error) {
+	orderBy, err := employeeSort.orderBy(filter.Sort)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	scope, args := employeeFilter(ctx, "e.Id_Employee", nil)
+	scope += " AND " + archivedFilter("e", filter.ListFilter)
+
+	if filter.Pesel != "" {
+		args = append(args, s.crypt.blindIndex(fieldPesel, filter.Pesel))
+		scope += fmt.Sprintf(" AND e.Pesel_Index = @p%d", len(args))
+	}
+
+	if filter.PassportNumber != "" {
+		args = append(args, s.crypt.blindIndex(fieldPassportNumber, filter.PassportNumber))
+		scope += fmt.Sprintf(" AND e.Passport_Number_Index = @p%d", len(args))
+	}
+
+	if filter.ProjectID != 0 {
+		args = append(args, filter.ProjectID)
+		scope += fmt.Sprintf(" AND e.Id_Employee IN (SELECT Id_Employee FROM Employee_Project WHERE Valid_To IS NULL AND Id_Project = @p%d)", len(args))
+	}
 
-	rows, err := s.DB.QueryContext(ctx, sql)
+	if filter.ContractType != "" {
+		args = append(args, filter.ContractType)
+		scope += fmt.Sprintf(" AND e.Id_Employee IN (SELECT Id_Employee FROM Employment WHERE Contract_Type = @p%d)", len(args))
+	}
+
+	if filter.ExpiringBefore != nil {
+		args = append(args, mssql.DateTime1(*filter.ExpiringBefore))
+		scope += fmt.Sprintf(" AND (e.Id_Employee IN (SELECT Id_Employee FROM Medicals WHERE OSH_Valid_Until < @p%[1]d OR Psychotests_Valid_Until < @p%[1]d OR Medical_Valid_Until < @p%[1]d OR Sanitary_Valid_Until < @p%[1]d) OR e.Id_Employee IN (SELECT Employee_Id FROM Residence_Card WHERE Bio < @p%[1]d OR Visa < @p%[1]d OR Tcard < @p%[1]d))", len(args))
+	}
+
+	from := "FROM Employee e WHERE " + scope
+
+	total, err := s.count(ctx, from, args...)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to query for employees")
+		return nil, 0, err
+	}
+
+	pageClause, args := page(filter.ListFilter, args)
+
+	sql := "SELECT e.Id_Employee, e.Last_Name, e.First_Name, e.Pesel, e.Passport_Number, e.Date_Of_Birth, e.Archived_At, COALESCE(e.Archived_By, '') " + from + " " + orderBy + pageClause + ";"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to query for employees")
 	}
 	defer rows.Close()
 
@@ -29,10 +75,20 @@ func (s *Service) Employees(ctx context.Context) ([]models.Employee, error) {
 			&employee.Pesel,
 			&employee.PassportNumber,
 			&employee.DateOfBirth,
+			&employee.ArchivedAt,
+			&employee.ArchivedBy,
 		)
 
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to scan row")
+			return nil, 0, errors.Wrap(err, "failed to scan row")
+		}
+
+		err = s.crypt.decryptAll(map[string]*string{
+			fieldPesel:          &employee.Pesel,
+			fieldPassportNumber: &employee.PassportNumber,
+		})
+		if err != nil {
+			return nil, 0, err
 		}
 
 		results = append(results, employee)
@@ -40,17 +96,19 @@ func (s *Service) Employees(ctx context.Context) ([]models.Employee, error) {
 
 	err = rows.Err()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to iterate rows")
+		return nil, 0, errors.Wrap(err, "failed to iterate rows")
 	}
 
-	return results, nil
+	return results, total, nil
 }
 
 func (s *Service) GetEmployee(ctx context.Context, id int) (models.Employee, error) {
-	sql := `SELECT TOP 1 e.Id_Employee, e.Last_Name, e.First_Name, e.Passport_Number, e.Pesel, e.Email, e.Date_Of_Birth, e.Father_Name, e.Mother_Name, e.Maiden_Name, e.Mother_Maiden_Name, e.Bank_Account, e.Address_Poland, e.Home_Address, e.Login, e.Password, m.OSH_Valid_Until, m.Psychotests_Valid_Until, m.Medical_Valid_Until, m.Sanitary_Valid_Until, em.Contract_Type, em.Start_Date, em.End_Date, em.Authorizations, rc.Bio, rc.Visa, rc.Tcard, ea.Id_Accommodation, ep.Id_Project, ec.Id_Car FROM employee e LEFT JOIN (SELECT OSH_Valid_Until, Psychotests_Valid_Until, Medical_Valid_Until, Sanitary_Valid_Until, Id_employee FROM Medicals WHERE Id_employee = @p1) m ON e.Id_Employee = m.Id_employee LEFT JOIN (SELECT Contract_Type, Start_Date, End_Date, Authorizations, Id_Employee FROM Employment WHERE Id_Employee = @p1) em ON e.Id_Employee = em.Id_Employee LEFT JOIN (SELECT Bio, Visa, Tcard, Employee_Id FROM Residence_Card WHERE Employee_Id = @p1) rc ON e.Id_Employee = rc.Employee_Id LEFT JOIN (SELECT Id_Accommodation, Id_Employee FROM Employee_Accommodation WHERE Id_Employee = @p1) ea ON e.Id_Employee = ea.Id_Employee LEFT JOIN (SELECT Id_Project, Id_Employee FROM Employee_Project WHERE Id_Employee = @p1) ep ON e.Id_Employee = ep.Id_Employee LEFT JOIN (SELECT Id_Car, Id_Employee FROM Employee_Car WHERE Id_Employee = @p1) ec ON e.Id_Employee = ec.Id_Employee WHERE e.Id_Employee = @p1;`
+	scope, args := employeeFilter(ctx, "e.Id_Employee", []any{id, mssql.DateTime1(models.Today())})
+
+	sql := `SELECT TOP 1 e.Id_Employee, e.Last_Name, e.First_Name, e.Passport_Number, e.Pesel, e.Email, e.Date_Of_Birth, e.Father_Name, e.Mother_Name, e.Maiden_Name, e.Mother_Maiden_Name, e.Bank_Account, e.Address_Poland, e.Home_Address, e.Login, e.Version, e.Archived_At, COALESCE(e.Archived_By, ''), m.OSH_Valid_Until, m.Psychotests_Valid_Until, m.Medical_Valid_Until, m.Sanitary_Valid_Until, em.Contract_Type, em.Start_Date, em.End_Date, em.Authorizations, rc.Bio, rc.Visa, rc.Tcard, ea.Id_Accommodation, ep.Id_Project, ec.Id_Car FROM employee e LEFT JOIN (SELECT OSH_Valid_Until, Psychotests_Valid_Until, Medical_Valid_Until, Sanitary_Valid_Until, Id_employee FROM Medicals WHERE Id_employee = @p1) m ON e.Id_Employee = m.Id_employee LEFT JOIN (SELECT Contract_Type, Start_Date, End_Date, Authorizations, Id_Employee FROM Employment WHERE Id_Employee = @p1) em ON e.Id_Employee = em.Id_Employee LEFT JOIN (SELECT Bio, Visa, Tcard, Employee_Id FROM Residence_Card WHERE Employee_Id = @p1) rc ON e.Id_Employee = rc.Employee_Id LEFT JOIN (SELECT Id_Accommodation, Id_Employee FROM Employee_Accommodation WHERE Id_Employee = @p1 AND Check_In <= @p2 AND (Check_Out IS NULL OR Check_Out > @p2)) ea ON e.Id_Employee = ea.Id_Employee LEFT JOIN (SELECT Id_Project, Id_Employee FROM Employee_Project WHERE Id_Employee = @p1 AND Valid_To IS NULL) ep ON e.Id_Employee = ep.Id_Employee LEFT JOIN (SELECT Id_Car, Id_Employee FROM Employee_Car WHERE Id_Employee = @p1 AND Valid_To IS NULL) ec ON e.Id_Employee = ec.Id_Employee WHERE e.Id_Employee = @p1 AND ` + scope + `;`
 	var employee models.Employee
 
-	err := s.DB.QueryRowContext(ctx, sql, id).Scan(
+	err := s.conn(ctx).QueryRowContext(ctx, sql, args...).Scan(
 		&employee.ID,
 		&employee.LastName,
 		&employee.FirstName,
@@ -66,7 +124,9 @@ func (s *Service) GetEmployee(ctx context.Context, id int) (models.Employee, err
 		&employee.AddressPoland,
 		&employee.HomeAddress,
 		&employee.Login,
-		&employee.Password,
+		&employee.Version,
+		&employee.ArchivedAt,
+		&employee.ArchivedBy,
 		&employee.Medicals.OSHValidUntil,
 		&employee.Medicals.PsychotestsValidUntil,
 		&employee.Medicals.MedicalValidUntil,
@@ -82,25 +142,62 @@ func (s *Service) GetEmployee(ctx context.Context, id int) (models.Employee, err
 		&employee.ProjectId,
 		&employee.CarId,
 	)
+	if err != nil {
+		return employee, errors.Wrap(err, "failed to retrieve employee")
+	}
 
-	return employee, errors.Wrap(err, "failed to retrieve employee")
+	err = s.crypt.decryptAll(map[string]*string{
+		fieldPesel:          &employee.Pesel,
+		fieldPassportNumber: &employee.PassportNumber,
+		fieldBankAccount:    &employee.BankAccount,
+	})
+
+	return employee, err
 }
 
 func (s *Service) AddEmployee(ctx context.Context, newEmployee models.NewEmployee) (id int, err error) {
+	err = s.InTx(ctx, func(ctx context.Context) error {
+		id, err = s.addEmployee(ctx, newEmployee)
+		return err
+	})
+
+	return id, err
+}
+
+func (s *Service) addEmployee(ctx context.Context, newEmployee models.NewEmployee) (id int, err error) {
+	if newEmployee.CarId != 0 {
+		return 0, errors.Wrap(ErrCarHandover, "failed to add car")
+	}
+
+	err = s.checkAssignments(ctx, newEmployee.ProjectId, newEmployee.AccommodationId, newEmployee.CarId)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.checkAssignmentsActive(ctx, newEmployee.ProjectId, newEmployee.AccommodationId, newEmployee.CarId)
+	if err != nil {
+		return 0, err
+	}
+
+	sensitive, err := s.encryptEmployee(newEmployee.Pesel, newEmployee.PassportNumber, newEmployee.BankAccount)
+	if err != nil {
+		return 0, err
+	}
 
 	sql := `
 	INSERT INTO Employee (
 		Last_Name, First_Name, Passport_Number, Pesel, Email, Date_Of_Birth, 
 		Father_Name, Mother_Name, Maiden_Name, Mother_Maiden_Name, Bank_Account, 
-		Address_Poland, Home_Address
-	) VALUES (@p1, @p2, @p3, @p4, @p5, @p6, @p7, @p8, @p9, @p10, @p11, @p12, @p13);
+		Address_Poland, Home_Address, Pesel_Index, Passport_Number_Index, Search_Key
+	) VALUES (@p1, @p2, @p3, @p4, @p5, @p6, @p7, @p8, @p9, @p10, @p11, @p12, @p13, @p14, @p15, @p16);
 	SELECT SCOPE_IDENTITY() AS Id_Employee;`
-	err = s.DB.QueryRowContext(ctx, sql,
-		newEmployee.LastName, newEmployee.FirstName, newEmployee.PassportNumber,
-		newEmployee.Pesel, newEmployee.Email, mssql.DateTime1(newEmployee.DateOfBirth),
+	err = s.conn(ctx).QueryRowContext(ctx, sql,
+		newEmployee.LastName, newEmployee.FirstName, sensitive.passportNumber,
+		sensitive.pesel, newEmployee.Email, mssql.DateTime1(newEmployee.DateOfBirth),
 		newEmployee.FatherName, newEmployee.MotherName, newEmployee.MaidenName,
-		newEmployee.MotherMaidenName, newEmployee.BankAccount, newEmployee.AddressPoland,
-		newEmployee.HomeAddress,
+		newEmployee.MotherMaidenName, sensitive.bankAccount, newEmployee.AddressPoland,
+		newEmployee.HomeAddress, sensitive.peselIndex, sensitive.passportNumberIndex,
+		searchKey(newEmployee.FirstName, newEmployee.LastName, newEmployee.MaidenName, newEmployee.Email),
 	).Scan(&id)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to add employee")
@@ -108,7 +205,7 @@ func (s *Service) AddEmployee(ctx context.Context, newEmployee models.NewEmploye
 
 	sql = "INSERT INTO Residence_Card (Employee_Id, Bio, Visa, TCard) VALUES (@p1, @p2, @p3, @p4);"
 
-	_, err = s.DB.ExecContext(ctx, sql, id, newEmployee.ResidenceCard.Bio.ConvertToTime(), newEmployee.ResidenceCard.Visa.ConvertToTime(), newEmployee.ResidenceCard.TCard.ConvertToTime())
+	_, err = s.conn(ctx).ExecContext(ctx, sql, id, newEmployee.ResidenceCard.Bio.ConvertToTime(), newEmployee.ResidenceCard.Visa.ConvertToTime(), newEmployee.ResidenceCard.TCard.ConvertToTime())
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to add residence card")
 	}
@@ -117,7 +214,7 @@ func (s *Service) AddEmployee(ctx context.Context, newEmployee models.NewEmploye
 	INSERT INTO Medicals (
 		Id_Employee, OSH_Valid_Until, Psychotests_Valid_Until, Medical_Valid_Until, Sanitary_Valid_Until
 	) VALUES (@p1, @p2, @p3, @p4, @p5);`
-	_, err = s.DB.ExecContext(ctx, sql, id,
+	_, err = s.conn(ctx).ExecContext(ctx, sql, id,
 		mssql.DateTime1(newEmployee.Medicals.OSHValidUntil), newEmployee.Medicals.PsychotestsValidUntil.ConvertToTime(),
 		mssql.DateTime1(newEmployee.Medicals.MedicalValidUntil), newEmployee.Medicals.SanitaryValidUntil.ConvertToTime())
 	if err != nil {
@@ -128,166 +225,298 @@ func (s *Service) AddEmployee(ctx context.Context, newEmployee models.NewEmploye
 	INSERT INTO Employment (
 		Id_Employee, Contract_Type, Start_Date, End_Date, Authorizations
 	) VALUES (@p1, @p2, @p3, @p4, @p5);`
-	_, err = s.DB.ExecContext(ctx, sql, id, newEmployee.Employment.ContractType,
+	_, err = s.conn(ctx).ExecContext(ctx, sql, id, newEmployee.Employment.ContractType,
 		mssql.DateTime1(newEmployee.Employment.StartDate), newEmployee.Employment.EndDate.ConvertToTime(), newEmployee.Employment.Authorizations)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to add employment details")
 	}
 
-	sql = "INSERT INTO Employee_Project (Id_Employee, Id_Project) VALUES (@p1, @p2);"
-	_, err = s.DB.ExecContext(ctx, sql, id, newEmployee.ProjectId)
+	err = s.assignProject(ctx, id, newEmployee.ProjectId, assignmentStart(newEmployee.Employment.StartDate))
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to add project")
 	}
 
-	sql = "INSERT INTO Employee_Accommodation (Id_Employee, Id_Accommodation) VALUES (@p1, @p2);"
-	_, err = s.DB.ExecContext(ctx, sql, id, newEmployee.AccommodationId)
+	err = s.moveIn(ctx, id, newEmployee.AccommodationId)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to add accommodation")
 	}
 
-	sql = "INSERT INTO Employee_Car (Id_Employee, Id_Car) VALUES (@p1, @p2);"
-	_, err = s.DB.ExecContext(ctx, sql, id, newEmployee.CarId)
-	if err != nil {
-		return 0, errors.Wrap(err, "failed to add car")
-	}
-
 	return id, nil
 }
 
-func (s *Service) UpdateEmployee(ctx context.Context, id int, updateEmployee models.UpdateEmployee) error {
-	query := `
-	UPDATE Employee 
-	SET Last_Name = @p1, First_Name = @p2, Passport_Number = @p3, Pesel = @p4, 
-		Email = @p5, Date_Of_Birth = @p6, Father_Name = @p7, Mother_Name = @p8, 
-		Maiden_Name = @p9, Mother_Maiden_Name = @p10, Bank_Account = @p11, 
-		Address_Poland = @p12, Home_Address = @p13
-	WHERE Id_Employee = @p14;`
-	_, err := s.DB.ExecContext(ctx, query,
-		updateEmployee.LastName,
-		updateEmployee.FirstName,
-		updateEmployee.PassportNumber,
-		updateEmployee.Pesel,
-		updateEmployee.Email,
-		mssql.DateTime1(updateEmployee.DateOfBirth),
-		updateEmployee.FatherName,
-		updateEmployee.MotherName,
-		updateEmployee.MaidenName,
-		updateEmployee.MotherMaidenName,
-		updateEmployee.BankAccount,
-		updateEmployee.AddressPoland,
-		updateEmployee.HomeAddress,
-		id)
-	if err != nil {
-		return errors.Wrap(err, "failed to update employee details")
+func (s *Service) UpdateEmployee(ctx context.Context, id, version int, updateEmployee models.UpdateEmployee) error {
+	return s.PatchEmployee(ctx, id, version, updateEmployee, nil)
+}
+
+// PatchEmployee writes the given fields of the update, leaving the other
+// columns, the details in other tables and the assignments as they are. It
+// returns ErrCarHandover if the update gives the employee another car, which
+// only AddHandover does.
+func (s *Service) PatchEmployee(ctx context.Context, id, version int, updateEmployee models.UpdateEmployee, fields models.Fields) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		return s.updateEmployee(ctx, id, version, updateEmployee, fields)
+	})
+}
+
+func (s *Service) updateEmployee(ctx context.Context, id, version int, updateEmployee models.UpdateEmployee, fields models.Fields) error {
+	if err := s.employeeVisible(ctx, id); err != nil {
+		return errors.Wrap(err, "failed to retrieve employee")
 	}
 
-	query = `
-	UPDATE Residence_Card 
-	SET Bio = @p1, Visa = @p2, TCard = @p3 
-	WHERE Employee_Id = @p4;`
-	_, err = s.DB.ExecContext(ctx, query,
-		updateEmployee.ResidenceCard.Bio.ConvertToTime(), updateEmployee.ResidenceCard.Visa.ConvertToTime(),
-		updateEmployee.ResidenceCard.TCard.ConvertToTime(), id)
+	if err := s.checkActive(ctx, "Employee", "Id_Employee", id); err != nil {
+		return err
+	}
+
+	if err := s.bumpVersion(ctx, "Employee", "Id_Employee", id, version); err != nil {
+		return err
+	}
+
+	if fields.Has("CarId") {
+		if err := s.checkCarUnchanged(ctx, id, updateEmployee.CarId); err != nil {
+			return err
+		}
+	}
+
+	if fields.Has("ProjectId") || fields.Has("AccommodationId") {
+		err := s.checkAssignments(ctx, updateEmployee.ProjectId, updateEmployee.AccommodationId, updateEmployee.CarId)
+		if err != nil {
+			return err
+		}
+
+		err = s.checkAssignmentsActive(ctx, updateEmployee.ProjectId, updateEmployee.AccommodationId, updateEmployee.CarId)
+		if err != nil {
+			return err
+		}
+	}
+
+	sensitive, err := s.encryptEmployee(updateEmployee.Pesel, updateEmployee.PassportNumber, updateEmployee.BankAccount)
 	if err != nil {
-		return errors.Wrap(err, "failed to update residence card")
+		return err
 	}
 
-	query = `
-	UPDATE Medicals 
-	SET OSH_Valid_Until = @p1, Psychotests_Valid_Until = @p2, 
-		Medical_Valid_Until = @p3, Sanitary_Valid_Until = @p4 
-	WHERE Id_Employee = @p5;`
-	_, err = s.DB.ExecContext(ctx, query,
-		mssql.DateTime1(updateEmployee.Medicals.OSHValidUntil), updateEmployee.Medicals.PsychotestsValidUntil.ConvertToTime(),
-		mssql.DateTime1(updateEmployee.Medicals.MedicalValidUntil), updateEmployee.Medicals.SanitaryValidUntil.ConvertToTime(), id)
+	named := fields.Has("FirstName") || fields.Has("LastName") || fields.Has("MaidenName") || fields.Has("Email")
+
+	err = s.updateColumns(ctx, "Employee", "Id_Employee", id, []column{
+		{"Last_Name", updateEmployee.LastName, fields.Has("LastName")},
+		{"First_Name", updateEmployee.FirstName, fields.Has("FirstName")},
+		{"Passport_Number", sensitive.passportNumber, fields.Has("PassportNumber")},
+		{"Passport_Number_Index", sensitive.passportNumberIndex, fields.Has("PassportNumber")},
+		{"Pesel", sensitive.pesel, fields.Has("Pesel")},
+		{"Pesel_Index", sensitive.peselIndex, fields.Has("Pesel")},
+		{"Email", updateEmployee.Email, fields.Has("Email")},
+		{"Date_Of_Birth", mssql.DateTime1(updateEmployee.DateOfBirth), fields.Has("DateOfBirth")},
+		{"Father_Name", updateEmployee.FatherName, fields.Has("FatherName")},
+		{"Mother_Name", updateEmployee.MotherName, fields.Has("MotherName")},
+		{"Maiden_Name", updateEmployee.MaidenName, fields.Has("MaidenName")},
+		{"Mother_Maiden_Name", updateEmployee.MotherMaidenName, fields.Has("MotherMaidenName")},
+		{"Bank_Account", sensitive.bankAccount, fields.Has("BankAccount")},
+		{"Address_Poland", updateEmployee.AddressPoland, fields.Has("AddressPoland")},
+		{"Home_Address", updateEmployee.HomeAddress, fields.Has("HomeAddress")},
+		{"Search_Key", searchKey(updateEmployee.FirstName, updateEmployee.LastName, updateEmployee.MaidenName, updateEmployee.Email), named},
+	})
 	if err != nil {
-		return errors.Wrap(err, "failed to update medical details")
+		return errors.Wrap(err, "failed to update employee details")
+	}
+
+	if fields.Has("ResidenceCard") {
+		query := `
+		UPDATE Residence_Card 
+		SET Bio = @p1, Visa = @p2, TCard = @p3 
+		WHERE Employee_Id = @p4;`
+		_, err = s.conn(ctx).ExecContext(ctx, query,
+			updateEmployee.ResidenceCard.Bio.ConvertToTime(), updateEmployee.ResidenceCard.Visa.ConvertToTime(),
+			updateEmployee.ResidenceCard.TCard.ConvertToTime(), id)
+		if err != nil {
+			return errors.Wrap(err, "failed to update residence card")
+		}
 	}
 
-	query = `
+	if fields.Has("Medicals") {
+		query := `
+		UPDATE Medicals 
+		SET OSH_Valid_Until = @p1, Psychotests_Valid_Until = @p2, 
+			Medical_Valid_Until = @p3, Sanitary_Valid_Until = @p4 
+		WHERE Id_Employee = @p5;`
+		_, err = s.conn(ctx).ExecContext(ctx, query,
+			mssql.DateTime1(updateEmployee.Medicals.OSHValidUntil), updateEmployee.Medicals.PsychotestsValidUntil.ConvertToTime(),
+			mssql.DateTime1(updateEmployee.Medicals.MedicalValidUntil), updateEmployee.Medicals.SanitaryValidUntil.ConvertToTime(), id)
+		if err != nil {
+			return errors.Wrap(err, "failed to update medical details")
+		}
+	}
+
+	if fields.Has("Employment") {
+		query := `
 		UPDATE Employment 
 		SET Contract_Type = @p1, Start_Date = @p2, End_Date = @p3, Authorizations = @p4 
 		WHERE Id_Employee = @p5;`
-	_, err = s.DB.ExecContext(ctx, query,
-		updateEmployee.Employment.ContractType, mssql.DateTime1(updateEmployee.Employment.StartDate), updateEmployee.Employment.EndDate.ConvertToTime(),
-		updateEmployee.Employment.Authorizations, id)
-	if err != nil {
-		return errors.Wrap(err, "failed to update employment details")
+		_, err = s.conn(ctx).ExecContext(ctx, query,
+			updateEmployee.Employment.ContractType, mssql.DateTime1(updateEmployee.Employment.StartDate), updateEmployee.Employment.EndDate.ConvertToTime(),
+			updateEmployee.Employment.Authorizations, id)
+		if err != nil {
+			return errors.Wrap(err, "failed to update employment details")
+		}
 	}
 
-	query = `
-	UPDATE Employee_Project 
-	SET Id_Project = @p1 
-	WHERE Id_Employee = @p2;`
-	_, err = s.DB.ExecContext(ctx, query, updateEmployee.ProjectId, id)
-	if err != nil {
-		return errors.Wrap(err, "failed to update project details")
+	if fields.Has("ProjectId") {
+		if err = s.assignProject(ctx, id, updateEmployee.ProjectId, models.Today()); err != nil {
+			return errors.Wrap(err, "failed to update project details")
+		}
+	}
+
+	if fields.Has("AccommodationId") {
+		if err = s.moveIn(ctx, id, updateEmployee.AccommodationId); err != nil {
+			return errors.Wrap(err, "failed to update accommodation details")
+		}
 	}
 
-	var rowExists bool
+	return nil
+}
 
-	query = `SELECT CASE WHEN EXISTS (SELECT 1 FROM Employee_Accommodation WHERE Id_Employee = @p1) THEN 1 ELSE 0 END AS RowExists;`
-	err = s.DB.QueryRowContext(ctx, query, id).Scan(
-		&rowExists,
-	)
+// ArchiveEmployee archives the employee and frees the car and accommodation
+// they were assigned to. Archived employees can no longer log in.
+func (s *Service) ArchiveEmployee(ctx context.Context, id, version int) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		return s.archiveEmployee(ctx, id, version)
+	})
+}
 
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return errors.Wrap(err, "failed to get car details")
+func (s *Service) archiveEmployee(ctx context.Context, id, version int) error {
+	if err := s.employeeVisible(ctx, id); err != nil {
+		return errors.Wrap(err, "failed to retrieve employee")
 	}
 
-	if rowExists {
-		query = `UPDATE Employee_Accommodation SET Id_Accommodation = @p1 WHERE Id_Employee = @p2;`
-		_, err = s.DB.ExecContext(ctx, query, updateEmployee.AccommodationId, id)
-		if err != nil {
-			return errors.Wrap(err, "failed to update accommodation details")
+	if err := s.bumpVersion(ctx, "Employee", "Id_Employee", id, version); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	if err := s.archive(ctx, "Employee", "Id_Employee", id, now); err != nil {
+		return err
+	}
+
+	if err := s.vacate(ctx, "Id_Employee = @p1", id); err != nil {
+		return err
+	}
+
+	return s.returnCars(ctx, now, "Id_Employee = @p1", id)
+}
+
+// RestoreEmployee brings back an archived employee. It returns ErrNotFound if
+// the employee is not archived and ErrReferenced while their project is.
+func (s *Service) RestoreEmployee(ctx context.Context, id, version int) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		return s.restoreEmployee(ctx, id, version)
+	})
+}
+
+func (s *Service) restoreEmployee(ctx context.Context, id, version int) error {
+	if err := s.employeeVisible(ctx, id); err != nil {
+		return errors.Wrap(err, "failed to retrieve employee")
+	}
+
+	if err := s.bumpVersion(ctx, "Employee", "Id_Employee", id, version); err != nil {
+		return err
+	}
+
+	if err := s.restore(ctx, "Employee", "Id_Employee", id); err != nil {
+		return err
+	}
+
+	sql := "SELECT 1 FROM Employee_Project ep JOIN Project p ON ep.Id_Project = p.Id_Project WHERE ep.Id_Employee = @p1 AND ep.Valid_To IS NULL AND p.Archived_At IS NOT NULL"
+
+	err := s.checkVisible(ctx, sql, id)
+	if err == nil {
+		return ErrReferenced
+	}
+
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+
+	return errors.Wrap(err, "failed to retrieve project")
+}
+
+func (s *Service) employeeVisible(ctx context.Context, id int) error {
+	scope, args := employeeFilter(ctx, "Id_Employee", []any{id})
+
+	return s.checkVisible(ctx, "SELECT 1 FROM Employee WHERE Id_Employee = @p1 AND "+scope, args...)
+}
+
+// checkAssignments returns ErrForbidden if the project, accommodation or car
+// an employee is being assigned to is outside of the caller's scope.
+func (s *Service) checkAssignments(ctx context.Context, projectID, accommodationID, carID int) error {
+	if _, restricted := auth.ProjectScope(ctx); !restricted {
+		return nil
+	}
+
+	if err := checkProject(ctx, projectID); err != nil {
+		return err
+	}
+
+	if accommodationID != 0 {
+		if err := s.accommodationVisible(ctx, accommodationID); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return ErrForbidden
+			}
+			return errors.Wrap(err, "failed to retrieve accommodation")
 		}
-	} else {
-		query = "INSERT INTO Employee_Accommodation (Id_Employee, Id_Accommodation) VALUES (@p1, @p2);"
-		_, err = s.DB.ExecContext(ctx, query, id, updateEmployee.AccommodationId)
-		if err != nil {
-			return errors.Wrap(err, "failed to insert car details")
+	}
+
+	if carID != 0 {
+		if err := s.carVisible(ctx, carID); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return ErrForbidden
+			}
+			return errors.Wrap(err, "failed to retrieve car")
 		}
 	}
 
-	query = `SELECT CASE WHEN EXISTS (SELECT 1 FROM Employee_Car WHERE Id_Employee = @p1) THEN 1 ELSE 0 END AS RowExists;`
-	err = s.DB.QueryRowContext(ctx, query, id).Scan(
-		&rowExists,
-	)
+	return nil
+}
 
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return errors.Wrap(err, "failed to get car details")
+// checkAssignmentsActive returns ErrReferenced if the project, accommodation
+// or car an employee is being assigned to has been archived.
+func (s *Service) checkAssignmentsActive(ctx context.Context, projectID, accommodationID, carID int) error {
+	if err := s.checkReference(ctx, "Project", "Id_Project", projectID); err != nil {
+		return err
 	}
 
-	if rowExists {
-		query = `UPDATE Employee_Car SET Id_Car = @p1 WHERE Id_Employee = @p2;`
-		_, err = s.DB.ExecContext(ctx, query, updateEmployee.CarId, id)
-		if err != nil {
-			return errors.Wrap(err, "failed to update car details")
-		}
-	} else {
-		query = "INSERT INTO Employee_Car (Id_Employee, Id_Car) VALUES (@p1, @p2);"
-		_, err = s.DB.ExecContext(ctx, query, id, updateEmployee.CarId)
-		if err != nil {
-			return errors.Wrap(err, "failed to insert car details")
+	if accommodationID != 0 {
+		if err := s.checkReference(ctx, "Accommodation", "Id_Accommodation", accommodationID); err != nil {
+			return err
 		}
 	}
 
+	if carID != 0 {
+		return s.checkReference(ctx, "Car", "Id_Car", carID)
+	}
+
 	return nil
 }
 
-func (s *Service) RemoveEmployee(ctx context.Context, id int) error {
-	sql := `
-		DELETE FROM Employee_Car WHERE Id_Employee = @p1;
-		DELETE FROM Employee_Accommodation WHERE Id_Employee = @p1;
-		DELETE FROM Employee_Project WHERE Id_Employee = @p1;
-		DELETE FROM Employment WHERE Id_Employee = @p1;
-		DELETE FROM Medicals WHERE Id_Employee = @p1;
-		DELETE FROM Residence_Card WHERE Employee_Id = @p1;
-		DELETE FROM Employee WHERE Id_Employee = @p1;
-	`
-
-	_, err := s.DB.ExecContext(ctx, sql, id)
-
-	return errors.Wrap(err, "failed to remove employee")
+// encryptedEmployee holds the stored form of an employee's sensitive fields.
+type encryptedEmployee struct {
+	pesel, passportNumber, bankAccount string
+	peselIndex, passportNumberIndex    string
+}
+
+func (s *Service) encryptEmployee(pesel, passportNumber, bankAccount string) (e encryptedEmployee, err error) {
+	if e.pesel, err = s.crypt.encrypt(fieldPesel, pesel); err != nil {
+		return e, errors.Wrap(err, "failed to encrypt PESEL")
+	}
+
+	if e.passportNumber, err = s.crypt.encrypt(fieldPassportNumber, passportNumber); err != nil {
+		return e, errors.Wrap(err, "failed to encrypt passport number")
+	}
+
+	if e.bankAccount, err = s.crypt.encrypt(fieldBankAccount, bankAccount); err != nil {
+		return e, errors.Wrap(err, "failed to encrypt bank account")
+	}
+
+	e.peselIndex = s.crypt.blindIndex(fieldPesel, pesel)
+	e.passportNumberIndex = s.crypt.blindIndex(fieldPassportNumber, passportNumber)
+
+	return e, nil
 }
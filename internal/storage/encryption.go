@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// encryptedPrefix marks values written by fieldCipher. Values without it are
+// legacy plain text, which is returned as is until the reencrypt command
+// has been run.
+const encryptedPrefix = "enc:v1:"
+
+// Names of the encrypted columns, bound to their ciphertexts.
+const (
+	fieldPesel          = "Employee.Pesel"
+	fieldPassportNumber = "Employee.Passport_Number"
+	fieldBankAccount    = "Employee.Bank_Account"
+	fieldAccountNumber  = "Payments.Account_Number"
+)
+
+// fieldCipher implements envelope encryption of single column values: every
+// value is encrypted with its own random data key, which is in turn wrapped
+// with a key encryption key (KEK) from the configuration. The KEK ID is
+// stored with the value so that keys can be rotated.
+type fieldCipher struct {
+	keys        map[string][]byte
+	activeKeyID string
+	indexKey    []byte
+}
+
+func newFieldCipher(cfg Config) (*fieldCipher, error) {
+	c := &fieldCipher{
+		keys:        make(map[string][]byte, len(cfg.EncryptionKeys)),
+		activeKeyID: cfg.EncryptionActiveKey,
+	}
+
+	for id, encoded := range cfg.EncryptionKeys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, errors.Errorf("invalid encryption key ID %q", id)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode encryption key %q", id)
+		}
+
+		if len(key) != 32 {
+			return nil, errors.Errorf("encryption key %q must be 32 bytes long", id)
+		}
+
+		c.keys[id] = key
+	}
+
+	if _, ok := c.keys[c.activeKeyID]; !ok {
+		return nil, errors.Errorf("active encryption key %q is not configured", c.activeKeyID)
+	}
+
+	indexKey, err := base64.StdEncoding.DecodeString(cfg.BlindIndexKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode blind index key")
+	}
+
+	if len(indexKey) < 32 {
+		return nil, errors.New("blind index key must be at least 32 bytes long")
+	}
+
+	c.indexKey = indexKey
+
+	return c, nil
+}
+
+// encrypt encrypts plaintext for the given field. The field name is bound to
+// the ciphertext, so a value copied into another column fails to decrypt.
+// Empty values are stored as they are.
+func (c *fieldCipher) encrypt(field, plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", errors.Wrap(err, "failed to generate data key")
+	}
+
+	wrappedKey, err := seal(c.keys[c.activeKeyID], dataKey, []byte(c.activeKeyID))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to wrap data key")
+	}
+
+	ciphertext, err := seal(dataKey, []byte(plaintext), []byte(field))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encrypt value")
+	}
+
+	return encryptedPrefix + c.activeKeyID + ":" +
+		base64.RawStdEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.RawStdEncoding.EncodeToString(ciphertext), nil
+}
+
+func (c *fieldCipher) decrypt(field, value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if len(parts) != 3 {
+		return "", errors.New("malformed encrypted value")
+	}
+
+	kek, ok := c.keys[parts[0]]
+	if !ok {
+		return "", errors.Errorf("encryption key %q is not configured", parts[0])
+	}
+
+	wrappedKey, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode data key")
+	}
+
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode value")
+	}
+
+	dataKey, err := open(kek, wrappedKey, []byte(parts[0]))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to unwrap data key")
+	}
+
+	plaintext, err := open(dataKey, ciphertext, []byte(field))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decrypt value")
+	}
+
+	return string(plaintext), nil
+}
+
+// decryptAll decrypts the values in place, stopping at the first error.
+func (c *fieldCipher) decryptAll(fields map[string]*string) error {
+	for field, value := range fields {
+		if value == nil {
+			continue
+		}
+
+		plaintext, err := c.decrypt(field, *value)
+		if err != nil {
+			return errors.Wrapf(err, "failed to decrypt %s", field)
+		}
+
+		*value = plaintext
+	}
+
+	return nil
+}
+
+// needsRotation reports whether value is plain text or encrypted with a key
+// other than the active one.
+func (c *fieldCipher) needsRotation(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	return !strings.HasPrefix(value, encryptedPrefix+c.activeKeyID+":")
+}
+
+// blindIndex returns a keyed hash of the normalised value, which allows
+// exact-match lookups on encrypted columns without decrypting them.
+func (c *fieldCipher) blindIndex(field, value string) string {
+	value = normalizeIdentifier(value)
+	if value == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, c.indexKey)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// normalizeIdentifier upper-cases the value and drops separators, so that
+// "ab 123-456" and "AB123456" index the same.
+func normalizeIdentifier(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, value)
+}
+
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(key, sealed, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+
+	return gcm.Open(nil, nonce, ciphertext, additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+var (
+	ErrForbidden       = errors.New("project is outside of the user's scope")
+	ErrNotFound        = errors.New("record not found")
+	ErrConflict        = errors.New("record already exists")
+	ErrReferenced      = errors.New("record references a missing record or is still referenced")
+	ErrVersionMismatch = errors.New("record has been changed since it was read")
+	ErrArchived        = errors.New("record is archived")
+	ErrNoVacancy       = errors.New("accommodation has no free places")
+	ErrOverlap         = errors.New("employee already has a stay in that period")
+	ErrDriverMismatch  = errors.New("handover does not match the drivers of the car")
+	ErrCarHandover     = errors.New("cars change hands only through handovers")
+	ErrInvalidSort     = errors.New("list cannot be sorted by that field")
+)
+
+// SQL Server error numbers of constraint violations.
+const (
+	errUniqueConstraint = 2627
+	errUniqueIndex      = 2601
+	errForeignKey       = 547
+)
+
+// sqlError is implemented by errors of the SQL Server driver.
+type sqlError interface {
+	SQLErrorNumber() int32
+}
+
+// translateError maps driver errors onto the errors above. The original error
+// stays in the chain.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+
+	var sqlErr sqlError
+	if errors.As(err, &sqlErr) {
+		switch sqlErr.SQLErrorNumber() {
+		case errUniqueConstraint, errUniqueIndex:
+			return fmt.Errorf("%w: %w", ErrConflict, err)
+		case errForeignKey:
+			return fmt.Errorf("%w: %w", ErrReferenced, err)
+		}
+	}
+
+	var liteErr *sqlite.Error
+	if errors.As(err, &liteErr) {
+		switch liteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return fmt.Errorf("%w: %w", ErrConflict, err)
+		case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+			return fmt.Errorf("%w: %w", ErrReferenced, err)
+		}
+	}
+
+	return err
+}
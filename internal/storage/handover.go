@@ -0,0 +1,274 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"api/internal/auth"
+	"api/internal/models"
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/pkg/errors"
+)
+
+// CarDrivers returns the periods in which employees had the car, most recent
+// first.
+func (s *Service) CarDrivers(ctx context.Context, carID int, filter models.DriverFilter) ([]models.CarDriver, error) {
+	if err := s.carVisible(ctx, carID); err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve car")
+	}
+
+	cond, args := "1 = 1", []any{carID}
+
+	if filter.To != nil {
+		args = append(args, mssql.DateTime1(*filter.To))
+		cond = fmt.Sprintf("ec.Valid_From <= @p%d", len(args))
+	}
+
+	if filter.From != nil {
+		args = append(args, mssql.DateTime1(*filter.From))
+		cond += fmt.Sprintf(" AND (ec.Valid_To IS NULL OR ec.Valid_To > @p%d)", len(args))
+	}
+
+	sql := "SELECT ec.Id_Assignment, ec.Id_Car, ec.Id_Employee, e.First_Name, e.Last_Name, ec.Valid_From, ec.Valid_To FROM Employee_Car ec JOIN Employee e ON ec.Id_Employee = e.Id_Employee WHERE ec.Id_Car = @p1 AND " + cond + " ORDER BY ec.Valid_From DESC, ec.Id_Assignment DESC;"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for drivers")
+	}
+	defer rows.Close()
+
+	results := make([]models.CarDriver, 0)
+
+	for rows.Next() {
+		var driver models.CarDriver
+		err = rows.Scan(&driver.ID, &driver.CarID, &driver.EmployeeID, &driver.FirstName, &driver.LastName, &driver.ValidFrom, &driver.ValidTo)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+
+		results = append(results, driver)
+	}
+
+	err = rows.Err()
+
+	return results, errors.Wrap(err, "failed to iterate rows")
+}
+
+// CarHandovers returns the handover protocols of the car, most recent first.
+func (s *Service) CarHandovers(ctx context.Context, carID int) ([]models.CarHandover, error) {
+	if err := s.carVisible(ctx, carID); err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve car")
+	}
+
+	sql := "SELECT Id_Handover, Id_Car, Handed_At, From_Employee, To_Employee, Odometer, Fuel_Level, Damage_Notes, COALESCE(Recorded_By, '') FROM Car_Handover WHERE Id_Car = @p1 ORDER BY Handed_At DESC, Id_Handover DESC;"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, sql, carID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for handovers")
+	}
+	defer rows.Close()
+
+	results := make([]models.CarHandover, 0)
+
+	for rows.Next() {
+		var h models.CarHandover
+		err = rows.Scan(&h.ID, &h.CarID, &h.HandedAt, &h.FromEmployeeID, &h.ToEmployeeID, &h.Odometer, &h.FuelLevel, &h.DamageNotes, &h.RecordedBy)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+
+		results = append(results, h)
+	}
+
+	err = rows.Err()
+
+	return results, errors.Wrap(err, "failed to iterate rows")
+}
+
+func (s *Service) GetHandover(ctx context.Context, id int) (models.CarHandover, error) {
+	scope, args := projectFilter(ctx, "c.Id_Project", []any{id})
+
+	sql := "SELECT h.Id_Handover, h.Id_Car, h.Handed_At, h.From_Employee, h.To_Employee, h.Odometer, h.Fuel_Level, h.Damage_Notes, COALESCE(h.Recorded_By, '') FROM Car_Handover h JOIN Car c ON h.Id_Car = c.Id_Car WHERE h.Id_Handover = @p1 AND " + scope + ";"
+
+	var h models.CarHandover
+
+	err := s.conn(ctx).QueryRowContext(ctx, sql, args...).Scan(&h.ID, &h.CarID, &h.HandedAt, &h.FromEmployeeID, &h.ToEmployeeID, &h.Odometer, &h.FuelLevel, &h.DamageNotes, &h.RecordedBy)
+
+	return h, errors.Wrap(err, "failed to retrieve handover")
+}
+
+// AddHandover records the car changing hands at newHandover.HandedAt: the
+// driving period of the employee handing it over ends, and one of the
+// employee receiving it starts, ending theirs with any other car. It returns
+// ErrDriverMismatch if the parties do not match the drivers of the car at
+// that moment, including a handover from nobody of a car someone drives.
+func (s *Service) AddHandover(ctx context.Context, carID int, newHandover models.NewHandover) (id int, err error) {
+	err = s.InTx(ctx, func(ctx context.Context) error {
+		id, err = s.addHandover(ctx, carID, newHandover)
+		return err
+	})
+
+	return id, err
+}
+
+func (s *Service) addHandover(ctx context.Context, carID int, newHandover models.NewHandover) (int, error) {
+	if err := s.carVisible(ctx, carID); err != nil {
+		return 0, errors.Wrap(err, "failed to retrieve car")
+	}
+
+	if err := s.checkActive(ctx, "Car", "Id_Car", carID); err != nil {
+		return 0, err
+	}
+
+	at := *newHandover.HandedAt
+
+	holder, err := s.carHolder(ctx, carID)
+	if err != nil {
+		return 0, err
+	}
+
+	if from := newHandover.FromEmployeeID; holder != 0 && (from == nil || *from != holder) {
+		return 0, errors.Wrap(ErrDriverMismatch, "the car is driven by another employee")
+	}
+
+	if from := newHandover.FromEmployeeID; from != nil {
+		if err := s.employeeVisible(ctx, *from); err != nil {
+			return 0, errors.Wrap(err, "failed to retrieve employee")
+		}
+
+		current, validFrom, err := s.currentCar(ctx, *from)
+		if err != nil {
+			return 0, err
+		}
+
+		if current != carID || validFrom.After(at) {
+			return 0, errors.Wrap(ErrDriverMismatch, "employee handing over the car does not have it")
+		}
+
+		if err = s.returnCars(ctx, at, "Id_Employee = @p1", *from); err != nil {
+			return 0, err
+		}
+	}
+
+	if to := newHandover.ToEmployeeID; to != nil {
+		if err := s.employeeVisible(ctx, *to); err != nil {
+			return 0, errors.Wrap(err, "failed to retrieve employee")
+		}
+
+		if err := s.checkActive(ctx, "Employee", "Id_Employee", *to); err != nil {
+			return 0, err
+		}
+
+		current, validFrom, err := s.currentCar(ctx, *to)
+		if err != nil {
+			return 0, err
+		}
+
+		if current == carID {
+			return 0, errors.Wrap(ErrDriverMismatch, "employee receiving the car already has it")
+		}
+
+		if current != 0 && validFrom.After(at) {
+			return 0, errors.Wrap(ErrDriverMismatch, "employee receiving the car got another one later")
+		}
+
+		if err = s.assignCar(ctx, *to, carID, at); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, employeeID := range []*int{newHandover.FromEmployeeID, newHandover.ToEmployeeID} {
+		if employeeID == nil {
+			continue
+		}
+
+		_, err := s.conn(ctx).ExecContext(ctx, "UPDATE Employee SET Version = Version + 1 WHERE Id_Employee = @p1;", *employeeID)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to update employee")
+		}
+	}
+
+	principal, _ := auth.FromContext(ctx)
+
+	sql := "INSERT INTO Car_Handover (Id_Car, Handed_At, From_Employee, To_Employee, Odometer, Fuel_Level, Damage_Notes, Recorded_By) VALUES (@p1, @p2, @p3, @p4, @p5, @p6, @p7, @p8); SELECT SCOPE_IDENTITY() AS Id_Handover;"
+
+	var id int
+
+	err = s.conn(ctx).QueryRowContext(ctx, sql, carID, mssql.DateTime1(at), newHandover.FromEmployeeID, newHandover.ToEmployeeID, newHandover.Odometer, newHandover.FuelLevel, newHandover.DamageNotes, mssql.VarChar(principal.Username)).Scan(&id)
+
+	return id, errors.Wrap(err, "failed to add handover")
+}
+
+// currentCar returns the car the employee has and since when, or 0 if they
+// have none.
+func (s *Service) currentCar(ctx context.Context, employeeID int) (carID int, validFrom time.Time, err error) {
+	sql := "SELECT Id_Car, Valid_From FROM Employee_Car WHERE Id_Employee = @p1 AND Valid_To IS NULL;"
+
+	err = s.conn(ctx).QueryRowContext(ctx, sql, employeeID).Scan(&carID, &validFrom)
+	if errors.Is(err, ErrNotFound) {
+		return 0, time.Time{}, nil
+	}
+
+	return carID, validFrom, errors.Wrap(err, "failed to retrieve car")
+}
+
+// carHolder returns the employee who drives the car, or 0 if nobody does.
+func (s *Service) carHolder(ctx context.Context, carID int) (employeeID int, err error) {
+	sql := "SELECT Id_Employee FROM Employee_Car WHERE Id_Car = @p1 AND Valid_To IS NULL;"
+
+	err = s.conn(ctx).QueryRowContext(ctx, sql, carID).Scan(&employeeID)
+	if errors.Is(err, ErrNotFound) {
+		return 0, nil
+	}
+
+	return employeeID, errors.Wrap(err, "failed to retrieve driver")
+}
+
+// checkCarUnchanged returns ErrCarHandover unless carID is the car the
+// employee has, or 0 if they have none: cars change hands only through
+// handovers, which record the state of the car.
+func (s *Service) checkCarUnchanged(ctx context.Context, employeeID, carID int) error {
+	current, _, err := s.currentCar(ctx, employeeID)
+	if err != nil {
+		return err
+	}
+
+	if current != carID {
+		return errors.Wrap(ErrCarHandover, "failed to change car")
+	}
+
+	return nil
+}
+
+// assignCar makes carID the employee's car from the given moment on, ending
+// the period in which they had another one. An ID of 0 only ends it. Giving
+// the employee the car they have does nothing.
+func (s *Service) assignCar(ctx context.Context, employeeID, carID int, at time.Time) error {
+	current, _, err := s.currentCar(ctx, employeeID)
+	if err != nil || current == carID {
+		return err
+	}
+
+	if err = s.returnCars(ctx, at, "Id_Employee = @p1", employeeID); err != nil || carID == 0 {
+		return err
+	}
+
+	sql := "INSERT INTO Employee_Car (Id_Employee, Id_Car, Valid_From) VALUES (@p1, @p2, @p3);"
+
+	_, err = s.conn(ctx).ExecContext(ctx, sql, employeeID, carID, mssql.DateTime1(at))
+
+	return errors.Wrap(err, "failed to assign car")
+}
+
+// returnCars ends the driving periods matching cond at the given moment. The
+// parameters of cond are args.
+func (s *Service) returnCars(ctx context.Context, at time.Time, cond string, args ...any) error {
+	args = append(args, mssql.DateTime1(at))
+
+	sql := fmt.Sprintf("UPDATE Employee_Car SET Valid_To = @p%d WHERE %s AND Valid_To IS NULL;", len(args), cond)
+
+	_, err := s.conn(ctx).ExecContext(ctx, sql, args...)
+
+	return errors.Wrap(err, "failed to return car")
+}
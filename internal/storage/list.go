@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"api/internal/models"
+	"github.com/pkg/errors"
+)
+
+// sortColumns maps the fields a list can be sorted by, named as in the JSON
+// of its records, to the columns holding them. It must include "id".
+type sortColumns map[string]string
+
+var (
+	employeeSort = sortColumns{
+		"id":            "e.Id_Employee",
+		"last_name":     "e.Last_Name",
+		"first_name":    "e.First_Name",
+		"date_of_birth": "e.Date_Of_Birth",
+	}
+	carSort = sortColumns{
+		"id":                  "C.Id_Car",
+		"model":               "C.Model",
+		"color":               "C.Color",
+		"registration_number": "C.Registration_Number",
+		"vin":                 "C.VIN_Number",
+		"inspection_from":     "C.Inspection_From",
+		"inspection_to":       "C.Inspection_To",
+		"insurance_from":      "C.Insurance_From",
+		"insurance_to":        "C.Insurance_To",
+		"fleet_card_number":   "C.Fleet_Card_Number",
+		"project_id":          "C.Id_Project",
+		"project_name":        "Project.Name",
+	}
+	accommodationSort = sortColumns{
+		"id":                    "a.Id_Accommodation",
+		"project_id":            "a.Id_Project",
+		"project_name":          "pro.Name",
+		"city":                  "a.City",
+		"accommodation_address": "a.Accommodation_Address",
+		"number_of_places":      "a.Number_Of_Places",
+	}
+	projectSort = sortColumns{
+		"id":              "p.Id_Project",
+		"name":            "p.Name",
+		"office_address":  "p.Office_Address",
+		"project_nip":     "p.Project_NIP",
+		"employee_amount": "EmployeeCount",
+		"free_places":     "FreeAccommodationPlaces",
+		"amount_cars":     "CarCount",
+	}
+)
+
+// orderBy returns the ORDER BY clause of a list sorted by keys, ending with
+// the ID so that pages neither overlap nor leave records out. It returns
+// ErrInvalidSort for fields that are not in c.
+func (c sortColumns) orderBy(keys []models.SortKey) (string, error) {
+	terms := make([]string, 0, len(keys)+1)
+
+	for _, key := range keys {
+		column, ok := c[key.Field]
+		if !ok {
+			return "", ErrInvalidSort
+		}
+
+		if key.Descending {
+			column += " DESC"
+		}
+
+		terms = append(terms, column)
+	}
+
+	return "ORDER BY " + strings.Join(append(terms, c["id"]), ", "), nil
+}
+
+// page returns the clause selecting the filter's page of a sorted list,
+// together with args extended by its parameters.
+func page(filter models.ListFilter, args []any) (string, []any) {
+	if filter.Limit == 0 {
+		return "", args
+	}
+
+	args = append(args, filter.Offset, filter.Limit)
+
+	return fmt.Sprintf(" OFFSET @p%d ROWS FETCH NEXT @p%d ROWS ONLY", len(args)-1, len(args)), args
+}
+
+// count returns the number of rows selected by from, the FROM clause of a
+// list query together with its conditions.
+func (s *Service) count(ctx context.Context, from string, args ...any) (int, error) {
+	var total int
+
+	err := s.conn(ctx).QueryRowContext(ctx, "SELECT COUNT(*) "+from+";", args...).Scan(&total)
+
+	return total, errors.Wrap(err, "failed to count records")
+}
+
+// memorySort maps the fields a list can be sorted by, named as in the JSON
+// of its records, to functions comparing two records by them.
+type memorySort[T any] map[string]func(a, b T) int
+
+var (
+	memoryEmployeeSort = memorySort[models.Employee]{
+		"id":            func(a, b models.Employee) int { return cmp.Compare(a.ID, b.ID) },
+		"last_name":     func(a, b models.Employee) int { return compareText(a.LastName, b.LastName) },
+		"first_name":    func(a, b models.Employee) int { return compareText(a.FirstName, b.FirstName) },
+		"date_of_birth": func(a, b models.Employee) int { return compareDate(a.DateOfBirth, b.DateOfBirth) },
+	}
+	memoryCarSort = memorySort[models.Car]{
+		"id":                  func(a, b models.Car) int { return cmp.Compare(a.ID, b.ID) },
+		"model":               func(a, b models.Car) int { return compareText(a.Model, b.Model) },
+		"color":               func(a, b models.Car) int { return compareText(a.Color, b.Color) },
+		"registration_number": func(a, b models.Car) int { return compareText(a.RegistrationNumber, b.RegistrationNumber) },
+		"vin":                 func(a, b models.Car) int { return compareText(a.VIN, b.VIN) },
+		"inspection_from":     func(a, b models.Car) int { return compareDate(a.InspectionFrom, b.InspectionFrom) },
+		"inspection_to":       func(a, b models.Car) int { return compareDate(a.InspectionTo, b.InspectionTo) },
+		"insurance_from":      func(a, b models.Car) int { return compareDate(a.InsuranceFrom, b.InsuranceFrom) },
+		"insurance_to":        func(a, b models.Car) int { return compareDate(a.InsuranceTo, b.InsuranceTo) },
+		"fleet_card_number":   func(a, b models.Car) int { return compareText(a.FleetCardNumber, b.FleetCardNumber) },
+		"project_id":          func(a, b models.Car) int { return cmp.Compare(a.ProjectID, b.ProjectID) },
+		"project_name":        func(a, b models.Car) int { return compareText(a.ProjectName, b.ProjectName) },
+	}
+	memoryAccommodationSort = memorySort[models.Accommodation]{
+		"id":           func(a, b models.Accommodation) int { return cmp.Compare(a.ID, b.ID) },
+		"project_id":   func(a, b models.Accommodation) int { return cmp.Compare(a.ProjectID, b.ProjectID) },
+		"project_name": func(a, b models.Accommodation) int { return compareText(a.ProjectName, b.ProjectName) },
+		"city":         func(a, b models.Accommodation) int { return compareText(a.City, b.City) },
+		"accommodation_address": func(a, b models.Accommodation) int {
+			return compareText(a.AccommodationAddress, b.AccommodationAddress)
+		},
+		"number_of_places": func(a, b models.Accommodation) int { return cmp.Compare(a.NumberOfPlaces, b.NumberOfPlaces) },
+	}
+	memoryProjectSort = memorySort[models.Project]{
+		"id":              func(a, b models.Project) int { return cmp.Compare(a.ID, b.ID) },
+		"name":            func(a, b models.Project) int { return compareText(a.Name, b.Name) },
+		"office_address":  func(a, b models.Project) int { return compareText(a.OfficeAddress, b.OfficeAddress) },
+		"project_nip":     func(a, b models.Project) int { return compareText(a.ProjectNIP, b.ProjectNIP) },
+		"employee_amount": func(a, b models.Project) int { return cmp.Compare(a.EmployeeAmount, b.EmployeeAmount) },
+		"free_places":     func(a, b models.Project) int { return cmp.Compare(a.FreePlaces, b.FreePlaces) },
+		"amount_cars":     func(a, b models.Project) int { return cmp.Compare(a.AmountCars, b.AmountCars) },
+	}
+)
+
+// sort applies sortColumns.orderBy to items, which are in the order of their
+// IDs.
+func (f memorySort[T]) sort(items []T, keys []models.SortKey) error {
+	for _, key := range keys {
+		if _, ok := f[key.Field]; !ok {
+			return ErrInvalidSort
+		}
+	}
+
+	slices.SortStableFunc(items, func(a, b T) int {
+		for _, key := range keys {
+			c := f[key.Field](a, b)
+			if key.Descending {
+				c = -c
+			}
+
+			if c != 0 {
+				return c
+			}
+		}
+
+		return 0
+	})
+
+	return nil
+}
+
+// paginate returns the filter's page of a sorted list.
+func paginate[T any](items []T, filter models.ListFilter) []T {
+	if filter.Offset >= len(items) {
+		return make([]T, 0)
+	}
+
+	items = items[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(items) {
+		items = items[:filter.Limit]
+	}
+
+	return items
+}
+
+// compareText compares strings ignoring case, as SQL Server does.
+func compareText(a, b string) int {
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func compareDate(a, b models.Date) int {
+	return time.Time(a).Compare(time.Time(b))
+}
+
+// expiresBefore reports whether any of the dates that are set falls before
+// day.
+func expiresBefore(day models.Date, dates ...*models.Date) bool {
+	for _, date := range dates {
+		if date != nil && time.Time(*date).Before(time.Time(day)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,18 +2,169 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	mssql "github.com/microsoft/go-mssqldb"
 	"github.com/pkg/errors"
+
+	"api/internal/models"
 )
 
-func (s *Service) GetUserPassword(ctx context.Context, login string) (password string, err error) {
-	sql := "SELECT password FROM Employee WHERE Login = @p1"
+func (s *Service) GetUser(ctx context.Context, login string) (models.User, error) {
+	sql := "SELECT Id_Employee, Login, Password, COALESCE(Role, @p2), COALESCE(Failed_Login_Count, 0), Locked_Until FROM Employee WHERE Login = @p1 AND Archived_At IS NULL"
+
+	var user models.User
+
+	err := s.conn(ctx).QueryRowContext(ctx, sql, mssql.VarChar(login), string(models.RoleReadOnly)).Scan(&user.EmployeeID, &user.Login, &user.Password, &user.Role, &user.FailedAttempts, &user.LockedUntil)
+	if err != nil {
+		return models.User{}, errors.Wrap(err, "failed to query for user")
+	}
+
+	return user, nil
+}
+
+func (s *Service) SetEmployeeAccount(ctx context.Context, employeeID int, login, passwordHash string, role models.Role) error {
+	sql := "UPDATE Employee SET Login = @p1, Password = @p2, Role = @p3 WHERE Id_Employee = @p4;"
+
+	res, err := s.conn(ctx).ExecContext(ctx, sql, mssql.VarChar(login), passwordHash, string(role), employeeID)
+	if err != nil {
+		return errors.Wrap(err, "failed to set employee account")
+	}
+
+	return errors.Wrap(expectRows(res), "failed to set employee account")
+}
+
+func (s *Service) UpdatePassword(ctx context.Context, login, passwordHash string) error {
+	sql := "UPDATE Employee SET Password = @p1 WHERE Login = @p2;"
+
+	res, err := s.conn(ctx).ExecContext(ctx, sql, passwordHash, mssql.VarChar(login))
+	if err != nil {
+		return errors.Wrap(err, "failed to update password")
+	}
+
+	return errors.Wrap(expectRows(res), "failed to update password")
+}
+
+func (s *Service) AddPasswordReset(ctx context.Context, reset models.PasswordReset) error {
+	sql := "INSERT INTO Password_Reset (Token_Hash, Login, Expires_At) VALUES (@p1, @p2, @p3);"
+
+	_, err := s.conn(ctx).ExecContext(ctx, sql, reset.Hash, mssql.VarChar(reset.Login), mssql.DateTime1(reset.ExpiresAt))
+
+	return errors.Wrap(err, "failed to add password reset")
+}
+
+// UsePasswordReset marks an unused, unexpired reset token as used and returns
+// the login it was issued for.
+func (s *Service) UsePasswordReset(ctx context.Context, hash string, now time.Time) (login string, err error) {
+	sql := "UPDATE Password_Reset SET Used_At = @p2 WHERE Token_Hash = @p1 AND Used_At IS NULL AND Expires_At > @p2;"
+
+	res, err := s.conn(ctx).ExecContext(ctx, sql, hash, mssql.DateTime1(now))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to use password reset")
+	}
+
+	if err = expectRows(res); err != nil {
+		return "", errors.Wrap(err, "failed to use password reset")
+	}
+
+	sql = "SELECT Login FROM Password_Reset WHERE Token_Hash = @p1;"
+
+	err = s.conn(ctx).QueryRowContext(ctx, sql, hash).Scan(&login)
+
+	return login, errors.Wrap(err, "failed to retrieve password reset")
+}
+
+func (s *Service) SetLoginFailures(ctx context.Context, login string, failures int, lockedUntil *time.Time) error {
+	sql := "UPDATE Employee SET Failed_Login_Count = @p1, Locked_Until = @p2 WHERE Login = @p3;"
+
+	res, err := s.conn(ctx).ExecContext(ctx, sql, failures, lockedUntil, mssql.VarChar(login))
+	if err != nil {
+		return errors.Wrap(err, "failed to set login failures")
+	}
+
+	return errors.Wrap(expectRows(res), "failed to set login failures")
+}
+
+// AddLoginFailure increments the failed login count of the user and returns
+// the new count.
+func (s *Service) AddLoginFailure(ctx context.Context, login string) (failures int, err error) {
+	sql := "UPDATE Employee SET Failed_Login_Count = Failed_Login_Count + 1 OUTPUT INSERTED.Failed_Login_Count WHERE Login = @p1;"
+
+	err = s.conn(ctx).QueryRowContext(ctx, sql, mssql.VarChar(login)).Scan(&failures)
+
+	return failures, errors.Wrap(err, "failed to add login failure")
+}
+
+// LockUser locks the user out until the given time, unless they already are
+// for longer.
+func (s *Service) LockUser(ctx context.Context, login string, until time.Time) error {
+	sql := "UPDATE Employee SET Locked_Until = @p1 WHERE Login = @p2 AND (Locked_Until IS NULL OR Locked_Until < @p1);"
 
-	err = s.DB.QueryRowContext(ctx, sql, mssql.VarChar(login)).Scan(&password)
+	_, err := s.conn(ctx).ExecContext(ctx, sql, mssql.DateTime1(until), mssql.VarChar(login))
+
+	return errors.Wrap(err, "failed to lock user")
+}
+
+func (s *Service) AddLoginAttempt(ctx context.Context, attempt models.LoginAttempt) error {
+	sql := "INSERT INTO Login_Attempt (Login, IP, User_Agent, Success, Reason, Attempted_At) VALUES (@p1, @p2, @p3, @p4, @p5, @p6);"
+
+	_, err := s.conn(ctx).ExecContext(ctx, sql, attempt.Login, attempt.IP, attempt.UserAgent, attempt.Success, attempt.Reason, mssql.DateTime1(attempt.AttemptedAt))
+
+	return errors.Wrap(err, "failed to add login attempt")
+}
+
+func (s *Service) GetUserProjects(ctx context.Context, employeeID int) ([]int, error) {
+	sql := "SELECT Id_Project FROM User_Project WHERE Id_Employee = @p1 ORDER BY Id_Project;"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, sql, employeeID)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to query for user")
+		return nil, errors.Wrap(err, "failed to query for user projects")
+	}
+	defer rows.Close()
+
+	results := make([]int, 0)
+
+	for rows.Next() {
+		var id int
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+
+		results = append(results, id)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to iterate rows")
+	}
+
+	return results, nil
+}
+
+// SetUserProjects replaces the set of projects the user is bound to.
+func (s *Service) SetUserProjects(ctx context.Context, employeeID int, projectIDs []int) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		return s.setUserProjects(ctx, employeeID, projectIDs)
+	})
+}
+
+func (s *Service) setUserProjects(ctx context.Context, employeeID int, projectIDs []int) error {
+	sql := "DELETE FROM User_Project WHERE Id_Employee = @p1;"
+
+	_, err := s.conn(ctx).ExecContext(ctx, sql, employeeID)
+	if err != nil {
+		return errors.Wrap(err, "failed to clear user projects")
+	}
+
+	sql = "INSERT INTO User_Project (Id_Employee, Id_Project) VALUES (@p1, @p2);"
+
+	for _, projectID := range projectIDs {
+		_, err = s.conn(ctx).ExecContext(ctx, sql, employeeID, projectID)
+		if err != nil {
+			return errors.Wrap(err, "failed to add user project")
+		}
 	}
 
-	return password, nil
+	return nil
 }
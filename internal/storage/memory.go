@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"context"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"api/internal/auth"
+	"api/internal/models"
+)
+
+// Memory is a Repository that keeps all data in process. It enforces the
+// same relationships, project scopes and constraint errors as Service, but
+// does not encrypt anything and loses its data on exit.
+type Memory struct {
+	mu   sync.Mutex
+	data memoryData
+}
+
+// memoryData holds the tables of a Memory. Records are replaced as a whole on
+// update, so a shallow copy of the maps is enough for a snapshot.
+type memoryData struct {
+	lastID int
+
+	projects       map[int]models.Project
+	cars           map[int]models.Car
+	accommodations map[int]models.Accommodation
+	employees      map[int]memoryEmployee
+	assignments    map[int][]models.ProjectAssignment
+	stays          map[int]models.Stay
+	drivers        map[int]models.CarDriver
+	handovers      map[int]models.CarHandover
+
+	userProjects   map[int][]int
+	passwordResets map[string]memoryPasswordReset
+	loginAttempts  []models.LoginAttempt
+	refreshTokens  map[string]models.RefreshToken
+	revokedTokens  map[string]time.Time
+	audit          []models.AuditEntry
+}
+
+// memoryEmployee is an employee together with its credentials.
+type memoryEmployee struct {
+	models.Employee
+
+	password       string
+	role           models.Role
+	failedAttempts int
+	lockedUntil    *time.Time
+}
+
+type memoryPasswordReset struct {
+	models.PasswordReset
+
+	usedAt *time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		data: memoryData{
+			projects:       make(map[int]models.Project),
+			cars:           make(map[int]models.Car),
+			accommodations: make(map[int]models.Accommodation),
+			employees:      make(map[int]memoryEmployee),
+			assignments:    make(map[int][]models.ProjectAssignment),
+			stays:          make(map[int]models.Stay),
+			drivers:        make(map[int]models.CarDriver),
+			handovers:      make(map[int]models.CarHandover),
+			userProjects:   make(map[int][]int),
+			passwordResets: make(map[string]memoryPasswordReset),
+			refreshTokens:  make(map[string]models.RefreshToken),
+			revokedTokens:  make(map[string]time.Time),
+		},
+	}
+}
+
+func (d memoryData) clone() memoryData {
+	c := d
+	c.projects = maps.Clone(d.projects)
+	c.cars = maps.Clone(d.cars)
+	c.accommodations = maps.Clone(d.accommodations)
+	c.employees = maps.Clone(d.employees)
+	c.assignments = maps.Clone(d.assignments)
+	c.stays = maps.Clone(d.stays)
+	c.drivers = maps.Clone(d.drivers)
+	c.handovers = maps.Clone(d.handovers)
+	c.userProjects = maps.Clone(d.userProjects)
+	c.passwordResets = maps.Clone(d.passwordResets)
+	c.loginAttempts = slices.Clone(d.loginAttempts)
+	c.refreshTokens = maps.Clone(d.refreshTokens)
+	c.revokedTokens = maps.Clone(d.revokedTokens)
+	c.audit = slices.Clone(d.audit)
+
+	return c
+}
+
+type memoryTxKey struct{}
+
+// InTx runs fn with exclusive access to the data, restoring the state from
+// before the call if fn fails. Nested calls join the outer unit of work.
+func (m *Memory) InTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if ctx.Value(memoryTxKey{}) == m {
+		return fn(ctx)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := m.data.clone()
+
+	defer func() {
+		if p := recover(); p != nil {
+			m.data = snapshot
+			panic(p)
+		}
+
+		if err != nil {
+			m.data = snapshot
+		}
+	}()
+
+	return fn(context.WithValue(ctx, memoryTxKey{}, m))
+}
+
+// lock gives the caller exclusive access to the data unless ctx already
+// carries a unit of work of m. The returned function releases it.
+func (m *Memory) lock(ctx context.Context) func() {
+	if ctx.Value(memoryTxKey{}) == m {
+		return func() {}
+	}
+
+	m.mu.Lock()
+
+	return m.mu.Unlock
+}
+
+func (m *Memory) nextID() int {
+	m.data.lastID++
+
+	return m.data.lastID
+}
+
+// Reencrypt has nothing to do, Memory does not encrypt values.
+func (m *Memory) Reencrypt(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// checkVersion applies the row version check of Service.bumpVersion.
+func checkVersion(current, version int) error {
+	if current != version {
+		return ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// archivedNow returns the archival of records archived by the user in ctx.
+func archivedNow(ctx context.Context) models.Archival {
+	principal, _ := auth.FromContext(ctx)
+
+	return models.Archival{ArchivedAt: ptr(time.Now().UTC()), ArchivedBy: principal.Username}
+}
+
+// listed reports whether a list with the given filter includes a record.
+func listed(filter models.ListFilter, a models.Archival) bool {
+	return filter.IncludeArchived || !a.Archived()
+}
+
+// inScope reports whether the principal in ctx may access records of the
+// given project.
+func inScope(ctx context.Context, projectID int) bool {
+	return checkProject(ctx, projectID) == nil
+}
+
+// sortedKeys returns the keys of a table in ascending order.
+func sortedKeys[V any](table map[int]V) []int {
+	return slices.Sorted(maps.Keys(table))
+}
+
+// patched returns current with the given fields taken from update, as
+// Service writes only those columns on a patch.
+func patched[T any](current, update T, fields models.Fields) T {
+	if fields == nil {
+		return update
+	}
+
+	c, u := reflect.ValueOf(&current).Elem(), reflect.ValueOf(update)
+	for name := range fields {
+		if field := c.FieldByName(name); field.IsValid() {
+			field.Set(u.FieldByName(name))
+		}
+	}
+
+	return current
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
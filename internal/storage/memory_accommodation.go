@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"api/internal/models"
+	"github.com/pkg/errors"
+)
+
+func (m *Memory) Accommodations(ctx context.Context, filter models.AccommodationFilter) ([]models.Accommodation, int, error) {
+	defer m.lock(ctx)()
+
+	results := make([]models.Accommodation, 0)
+
+	for _, id := range sortedKeys(m.data.accommodations) {
+		acc := m.data.accommodations[id]
+		if !inScope(ctx, acc.ProjectID) || !listed(filter.ListFilter, acc.Archival) {
+			continue
+		}
+
+		if filter.ProjectID != 0 && acc.ProjectID != filter.ProjectID {
+			continue
+		}
+
+		if filter.City != "" && !strings.EqualFold(acc.City, filter.City) {
+			continue
+		}
+
+		acc.ProjectName = m.data.projects[acc.ProjectID].Name
+		results = append(results, acc)
+	}
+
+	if err := memoryAccommodationSort.sort(results, filter.Sort); err != nil {
+		return nil, 0, err
+	}
+
+	return paginate(results, filter.ListFilter), len(results), nil
+}
+
+func (m *Memory) AddAccommodation(ctx context.Context, newAccommodation models.NewAccommodation) (int, error) {
+	defer m.lock(ctx)()
+
+	if err := checkProject(ctx, newAccommodation.ProjectID); err != nil {
+		return 0, err
+	}
+
+	if err := m.data.checkProjectExists(newAccommodation.ProjectID); err != nil {
+		return 0, errors.Wrap(err, "failed to add accommodation")
+	}
+
+	if err := m.data.checkProjectActive(newAccommodation.ProjectID); err != nil {
+		return 0, err
+	}
+
+	id := m.nextID()
+
+	m.data.accommodations[id] = models.Accommodation{
+		ID:                   id,
+		ProjectID:            newAccommodation.ProjectID,
+		City:                 newAccommodation.City,
+		AccommodationAddress: newAccommodation.Address,
+		NumberOfPlaces:       newAccommodation.NumberOfPlaces,
+		Contact:              memoryContact(m.nextID(), newAccommodation.Contact),
+		Payment:              memoryPayment(m.nextID(), newAccommodation.Payment),
+		Version:              1,
+	}
+
+	return id, nil
+}
+
+func (m *Memory) GetAccommodation(ctx context.Context, id int) (models.Accommodation, error) {
+	defer m.lock(ctx)()
+
+	acc, ok := m.data.accommodations[id]
+	if !ok || !inScope(ctx, acc.ProjectID) {
+		return models.Accommodation{}, errors.Wrap(ErrNotFound, "failed to retrieve accommodation")
+	}
+
+	acc.ProjectName = m.data.projects[acc.ProjectID].Name
+
+	return acc, nil
+}
+
+func (m *Memory) ArchiveAccommodation(ctx context.Context, id, version int) error {
+	defer m.lock(ctx)()
+
+	acc, ok := m.data.accommodations[id]
+	if !ok || !inScope(ctx, acc.ProjectID) {
+		return errors.Wrap(ErrNotFound, "failed to retrieve accommodation")
+	}
+
+	if err := checkVersion(acc.Version, version); err != nil {
+		return err
+	}
+
+	if acc.Archived() {
+		return ErrArchived
+	}
+
+	acc.Archival = archivedNow(ctx)
+	acc.Version++
+	m.data.accommodations[id] = acc
+	m.data.vacate(func(s models.Stay) bool { return s.AccommodationID == id })
+
+	return nil
+}
+
+func (m *Memory) RestoreAccommodation(ctx context.Context, id, version int) error {
+	defer m.lock(ctx)()
+
+	acc, ok := m.data.accommodations[id]
+	if !ok || !inScope(ctx, acc.ProjectID) {
+		return errors.Wrap(ErrNotFound, "failed to retrieve accommodation")
+	}
+
+	if err := checkVersion(acc.Version, version); err != nil {
+		return err
+	}
+
+	if !acc.Archived() {
+		return errors.Wrap(ErrNotFound, "failed to restore Accommodation")
+	}
+
+	if err := m.data.checkProjectActive(acc.ProjectID); err != nil {
+		return err
+	}
+
+	acc.Archival = models.Archival{}
+	acc.Version++
+	m.data.accommodations[id] = acc
+
+	return nil
+}
+
+func (m *Memory) UpdateAccommodation(ctx context.Context, id, version int, updateAccommodation models.UpdateAccommodation) error {
+	return m.PatchAccommodation(ctx, id, version, updateAccommodation, nil)
+}
+
+func (m *Memory) PatchAccommodation(ctx context.Context, id, version int, updateAccommodation models.UpdateAccommodation, fields models.Fields) error {
+	defer m.lock(ctx)()
+
+	acc, ok := m.data.accommodations[id]
+	if !ok || !inScope(ctx, acc.ProjectID) {
+		return errors.Wrap(ErrNotFound, "failed to retrieve accommodation")
+	}
+
+	if acc.Archived() {
+		return ErrArchived
+	}
+
+	if err := checkVersion(acc.Version, version); err != nil {
+		return err
+	}
+
+	updateAccommodation = patched(acc.Update(), updateAccommodation, fields)
+
+	if fields.Has("ProjectID") {
+		if err := checkProject(ctx, updateAccommodation.ProjectID); err != nil {
+			return err
+		}
+
+		if err := m.data.checkProjectExists(updateAccommodation.ProjectID); err != nil {
+			return errors.Wrap(err, "failed to update accommodation")
+		}
+
+		if err := m.data.checkProjectActive(updateAccommodation.ProjectID); err != nil {
+			return err
+		}
+	}
+
+	m.data.accommodations[id] = models.Accommodation{
+		ID:                   id,
+		ProjectID:            updateAccommodation.ProjectID,
+		City:                 updateAccommodation.City,
+		AccommodationAddress: updateAccommodation.AccommodationAddress,
+		NumberOfPlaces:       updateAccommodation.NumberOfPlaces,
+		Contact:              memoryContact(*acc.Contact.ID, updateAccommodation.Contact),
+		Payment:              memoryPayment(*acc.Payment.ID, updateAccommodation.Payment),
+		Version:              acc.Version + 1,
+	}
+
+	return nil
+}
+
+func (m *Memory) GetAccommodationAddresses(ctx context.Context, asOf models.Date) ([]models.AccommodationAddresses, error) {
+	defer m.lock(ctx)()
+
+	results := make([]models.AccommodationAddresses, 0)
+
+	for id, acc := range m.data.accommodations {
+		if inScope(ctx, acc.ProjectID) && !acc.Archived() && acc.NumberOfPlaces > m.data.occupiedPlaces(id, asOf) {
+			results = append(results, models.AccommodationAddresses{ID: id, Address: acc.City + " " + acc.AccommodationAddress})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Address < results[j].Address
+	})
+
+	return results, nil
+}
+
+func memoryContact(id int, contact models.UpdateContactDetails) models.ContactDetails {
+	return models.ContactDetails{
+		ID:          ptr(id),
+		FirstName:   ptr(contact.FirstName),
+		LastName:    ptr(contact.LastName),
+		PhoneNumber: ptr(contact.PhoneNumber),
+	}
+}
+
+func memoryPayment(id int, payment models.UpdatePaymentDetails) models.PaymentDetails {
+	return models.PaymentDetails{
+		ID:            ptr(id),
+		Cost:          ptr(payment.Cost),
+		Deposit:       ptr(payment.Deposit),
+		Contract:      ptr(payment.Contract),
+		AccountNumber: ptr(payment.AccountNumber),
+		PaymentDay:    ptr(payment.PaymentDay),
+	}
+}
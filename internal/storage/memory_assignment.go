@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"api/internal/models"
+	"github.com/pkg/errors"
+)
+
+func (m *Memory) EmployeeAssignments(ctx context.Context, employeeID int) ([]models.ProjectAssignment, error) {
+	defer m.lock(ctx)()
+
+	e, ok := m.data.employees[employeeID]
+	if !ok || !inScope(ctx, e.ProjectId) {
+		return nil, errors.Wrap(ErrNotFound, "failed to retrieve employee")
+	}
+
+	results := make([]models.ProjectAssignment, 0)
+
+	for _, a := range slices.Backward(m.data.assignments[employeeID]) {
+		if !inScope(ctx, a.ProjectID) {
+			continue
+		}
+
+		a.ProjectName = m.data.projects[a.ProjectID].Name
+		results = append(results, a)
+	}
+
+	return results, nil
+}
+
+func (m *Memory) TransferEmployee(ctx context.Context, id, version, projectID int, from models.Date) error {
+	defer m.lock(ctx)()
+
+	e, ok := m.data.employees[id]
+	if !ok || !inScope(ctx, e.ProjectId) {
+		return errors.Wrap(ErrNotFound, "failed to retrieve employee")
+	}
+
+	if e.Archived() {
+		return ErrArchived
+	}
+
+	if err := checkVersion(e.Version, version); err != nil {
+		return err
+	}
+
+	if err := checkProject(ctx, projectID); err != nil {
+		return err
+	}
+
+	if err := m.data.checkProjectExists(projectID); err != nil {
+		return err
+	}
+
+	if err := m.data.checkProjectActive(projectID); err != nil {
+		return err
+	}
+
+	m.assignProject(id, projectID, from)
+
+	e.ProjectId = projectID
+	e.Version++
+	m.data.employees[id] = e
+
+	return nil
+}
+
+// assignProject applies Service.assignProject to the employee's assignments,
+// which are kept oldest first. The caller updates Employee.ProjectId.
+func (m *Memory) assignProject(employeeID, projectID int, from models.Date) {
+	assignments := slices.Clone(m.data.assignments[employeeID])
+
+	if n := len(assignments); n > 0 {
+		current := assignments[n-1]
+
+		switch {
+		case current.ProjectID == projectID:
+			return
+		case time.Time(current.ValidFrom).Before(time.Time(from)):
+			assignments[n-1].ValidTo = ptr(from.AddDays(-1))
+		default:
+			assignments = assignments[:n-1]
+
+			if n > 1 && assignments[n-2].ProjectID == projectID && time.Time(*assignments[n-2].ValidTo).Equal(time.Time(from.AddDays(-1))) {
+				assignments[n-2].ValidTo = nil
+				m.data.assignments[employeeID] = assignments
+
+				return
+			}
+		}
+	}
+
+	m.data.assignments[employeeID] = append(assignments, models.ProjectAssignment{
+		ID:        m.nextID(),
+		ProjectID: projectID,
+		ValidFrom: from,
+	})
+}
+
+// projectEmployees counts the employees assigned to the project on the given
+// day, leaving out those archived by then.
+func (d memoryData) projectEmployees(projectID int, day models.Date) int {
+	next := time.Time(day.AddDays(1))
+
+	n := 0
+	for id, e := range d.employees {
+		if e.Archived() && e.ArchivedAt.Before(next) {
+			continue
+		}
+
+		if slices.ContainsFunc(d.assignments[id], func(a models.ProjectAssignment) bool {
+			return a.ProjectID == projectID && inForce(a, day)
+		}) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// inForce reports whether the assignment applies on the given day.
+func inForce(a models.ProjectAssignment, day models.Date) bool {
+	return !time.Time(a.ValidFrom).After(time.Time(day)) && (a.ValidTo == nil || !time.Time(*a.ValidTo).Before(time.Time(day)))
+}
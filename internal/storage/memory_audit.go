@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"slices"
+
+	"api/internal/models"
+)
+
+func (m *Memory) AddAuditEntry(ctx context.Context, entry models.AuditEntry) error {
+	defer m.lock(ctx)()
+
+	entry.ID = m.nextID()
+	entry.Changes = slices.Clone(entry.Changes)
+	m.data.audit = append(m.data.audit, entry)
+
+	return nil
+}
+
+// AuditEntries returns the newest entries matching the filter first.
+func (m *Memory) AuditEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	defer m.lock(ctx)()
+
+	results := make([]models.AuditEntry, 0)
+
+	for _, entry := range slices.Backward(m.data.audit) {
+		if len(results) == filter.Limit {
+			break
+		}
+
+		switch {
+		case filter.EntityType != "" && entry.EntityType != filter.EntityType,
+			filter.EntityID != 0 && entry.EntityID != filter.EntityID,
+			filter.Username != "" && entry.Username != filter.Username,
+			filter.From != nil && entry.OccurredAt.Before(*filter.From),
+			filter.To != nil && !entry.OccurredAt.Before(*filter.To):
+			continue
+		}
+
+		results = append(results, entry)
+	}
+
+	return results, nil
+}
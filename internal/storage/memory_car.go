@@ -0,0 +1,235 @@
+package storage
+
+import (
+	"context"
+	"sort"
+
+	"api/internal/models"
+	"github.com/pkg/errors"
+)
+
+func (m *Memory) Cars(ctx context.Context, filter models.CarFilter) ([]models.Car, int, error) {
+	defer m.lock(ctx)()
+
+	results := make([]models.Car, 0)
+
+	for _, id := range sortedKeys(m.data.cars) {
+		car := m.data.cars[id]
+		if !inScope(ctx, car.ProjectID) || !listed(filter.ListFilter, car.Archival) {
+			continue
+		}
+
+		if filter.ProjectID != 0 && car.ProjectID != filter.ProjectID {
+			continue
+		}
+
+		if filter.ExpiringBefore != nil && !expiresBefore(*filter.ExpiringBefore, &car.InspectionTo, &car.InsuranceTo) {
+			continue
+		}
+
+		car.ProjectName = m.data.projects[car.ProjectID].Name
+		car.Service = models.Service{}
+		car.Leasing = models.Leasing{}
+
+		results = append(results, car)
+	}
+
+	if err := memoryCarSort.sort(results, filter.Sort); err != nil {
+		return nil, 0, err
+	}
+
+	return paginate(results, filter.ListFilter), len(results), nil
+}
+
+func (m *Memory) GetCar(ctx context.Context, id int) (models.Car, error) {
+	defer m.lock(ctx)()
+
+	car, ok := m.data.cars[id]
+	if !ok || !inScope(ctx, car.ProjectID) {
+		return models.Car{}, errors.Wrap(ErrNotFound, "failed to retrieve car")
+	}
+
+	car.ProjectName = m.data.projects[car.ProjectID].Name
+
+	return car, nil
+}
+
+func (m *Memory) AddCar(ctx context.Context, newCar models.NewCar) (int, error) {
+	defer m.lock(ctx)()
+
+	if err := checkProject(ctx, newCar.IdProject); err != nil {
+		return 0, err
+	}
+
+	if err := m.data.checkCar(0, newCar.IdProject, newCar.VIN); err != nil {
+		return 0, errors.Wrap(err, "failed to add car")
+	}
+
+	if err := m.data.checkProjectActive(newCar.IdProject); err != nil {
+		return 0, err
+	}
+
+	id := m.nextID()
+
+	m.data.cars[id] = models.Car{
+		ID:                 id,
+		Model:              newCar.Model,
+		Color:              newCar.Color,
+		RegistrationNumber: newCar.RegistrationNumber,
+		VIN:                newCar.VIN,
+		InspectionFrom:     newCar.InspectionFrom,
+		InspectionTo:       newCar.InspectionTo,
+		InsuranceFrom:      newCar.InsuranceFrom,
+		InsuranceTo:        newCar.InsuranceTo,
+		FleetCardNumber:    newCar.FleetCardNumber,
+		ProjectID:          newCar.IdProject,
+		Service:            newCar.Service,
+		Leasing:            newCar.Leasing,
+		Version:            1,
+	}
+
+	return id, nil
+}
+
+func (m *Memory) ArchiveCar(ctx context.Context, id, version int) error {
+	defer m.lock(ctx)()
+
+	car, ok := m.data.cars[id]
+	if !ok || !inScope(ctx, car.ProjectID) {
+		return errors.Wrap(ErrNotFound, "failed to retrieve car")
+	}
+
+	if err := checkVersion(car.Version, version); err != nil {
+		return err
+	}
+
+	if car.Archived() {
+		return ErrArchived
+	}
+
+	car.Archival = archivedNow(ctx)
+	car.Version++
+	m.data.cars[id] = car
+	m.data.returnCars(*car.ArchivedAt, func(d models.CarDriver) bool { return d.CarID == id })
+
+	return nil
+}
+
+func (m *Memory) RestoreCar(ctx context.Context, id, version int) error {
+	defer m.lock(ctx)()
+
+	car, ok := m.data.cars[id]
+	if !ok || !inScope(ctx, car.ProjectID) {
+		return errors.Wrap(ErrNotFound, "failed to retrieve car")
+	}
+
+	if err := checkVersion(car.Version, version); err != nil {
+		return err
+	}
+
+	if !car.Archived() {
+		return errors.Wrap(ErrNotFound, "failed to restore Car")
+	}
+
+	if err := m.data.checkProjectActive(car.ProjectID); err != nil {
+		return err
+	}
+
+	car.Archival = models.Archival{}
+	car.Version++
+	m.data.cars[id] = car
+
+	return nil
+}
+
+func (m *Memory) UpdateCar(ctx context.Context, id, version int, updateCar models.UpdateCar) error {
+	return m.PatchCar(ctx, id, version, updateCar, nil)
+}
+
+func (m *Memory) PatchCar(ctx context.Context, id, version int, updateCar models.UpdateCar, fields models.Fields) error {
+	defer m.lock(ctx)()
+
+	car, ok := m.data.cars[id]
+	if !ok || !inScope(ctx, car.ProjectID) {
+		return errors.Wrap(ErrNotFound, "failed to retrieve car")
+	}
+
+	if car.Archived() {
+		return ErrArchived
+	}
+
+	if err := checkVersion(car.Version, version); err != nil {
+		return err
+	}
+
+	updateCar = patched(car.Update(), updateCar, fields)
+
+	if fields.Has("IdProject") {
+		if err := checkProject(ctx, updateCar.IdProject); err != nil {
+			return err
+		}
+	}
+
+	if err := m.data.checkCar(id, updateCar.IdProject, updateCar.VIN); err != nil {
+		return errors.Wrap(err, "failed to update car")
+	}
+
+	if fields.Has("IdProject") {
+		if err := m.data.checkProjectActive(updateCar.IdProject); err != nil {
+			return err
+		}
+	}
+
+	m.data.cars[id] = models.Car{
+		ID:                 id,
+		Model:              updateCar.Model,
+		Color:              updateCar.Color,
+		RegistrationNumber: updateCar.RegistrationNumber,
+		VIN:                updateCar.VIN,
+		InspectionFrom:     updateCar.InspectionFrom,
+		InspectionTo:       updateCar.InspectionTo,
+		InsuranceFrom:      updateCar.InsuranceFrom,
+		InsuranceTo:        updateCar.InsuranceTo,
+		FleetCardNumber:    updateCar.FleetCardNumber,
+		ProjectID:          updateCar.IdProject,
+		Service:            updateCar.Service,
+		Leasing:            updateCar.Leasing,
+		Version:            car.Version + 1,
+	}
+
+	return nil
+}
+
+func (m *Memory) GetCarNumbers(ctx context.Context) ([]models.CarNumbers, error) {
+	defer m.lock(ctx)()
+
+	results := make([]models.CarNumbers, 0)
+
+	for _, car := range m.data.cars {
+		if inScope(ctx, car.ProjectID) && !car.Archived() {
+			results = append(results, models.CarNumbers{ID: car.ID, RegistrationNumber: car.RegistrationNumber})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].RegistrationNumber < results[j].RegistrationNumber
+	})
+
+	return results, nil
+}
+
+// checkCar enforces the project reference and the unique VIN of the car with
+// the given ID, 0 for a new one.
+func (d memoryData) checkCar(id, projectID int, vin string) error {
+	if err := d.checkProjectExists(projectID); err != nil {
+		return err
+	}
+
+	for _, car := range d.cars {
+		if car.ID != id && vin != "" && car.VIN == vin {
+			return errors.Wrap(ErrConflict, "VIN is already registered")
+		}
+	}
+
+	return nil
+}
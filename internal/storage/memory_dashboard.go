@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"api/internal/models"
+)
+
+func (m *Memory) DashboardEmployeeProjects(ctx context.Context, asOf models.Date) ([]models.DashboardEmployeesProject, error) {
+	defer m.lock(ctx)()
+
+	counts := make(map[string]int)
+	for _, p := range m.data.projects {
+		if n := m.data.projectEmployees(p.ID, asOf); n > 0 && inScope(ctx, p.ID) && !p.Archived() {
+			counts[p.Name] += n
+		}
+	}
+
+	results := make([]models.DashboardEmployeesProject, 0, len(counts))
+	for name, count := range counts {
+		results = append(results, models.DashboardEmployeesProject{Name: name, Count: count})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Count > results[j].Count
+	})
+
+	if len(results) > 10 {
+		rest := models.DashboardEmployeesProject{Name: "Pozostałe"}
+		for _, r := range results[10:] {
+			rest.Count += r.Count
+		}
+
+		results = append(results[:10], rest)
+	}
+
+	return results, nil
+}
+
+func (m *Memory) Accommodation(ctx context.Context, asOf models.Date) ([]models.DashboardAccommodation, error) {
+	defer m.lock(ctx)()
+
+	byName := make(map[string]*models.DashboardAccommodation)
+	for _, p := range m.data.projects {
+		if inScope(ctx, p.ID) && !p.Archived() && byName[p.Name] == nil {
+			byName[p.Name] = &models.DashboardAccommodation{Name: p.Name}
+		}
+	}
+
+	for id, acc := range m.data.accommodations {
+		p, ok := m.data.projects[acc.ProjectID]
+		if !ok || !inScope(ctx, p.ID) || p.Archived() || acc.Archived() {
+			continue
+		}
+
+		taken := m.data.occupiedPlaces(id, asOf)
+		byName[p.Name].Taken += taken
+		byName[p.Name].Free += acc.NumberOfPlaces - taken
+	}
+
+	results := make([]models.DashboardAccommodation, 0, len(byName))
+	for _, r := range byName {
+		results = append(results, *r)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Free > results[j].Free
+	})
+
+	if len(results) > 10 {
+		results = results[:10]
+	}
+
+	return results, nil
+}
+
+func (m *Memory) CarInspections(ctx context.Context) ([]models.DashboardCarInspection, error) {
+	defer m.lock(ctx)()
+
+	var cars []models.Car
+	for _, car := range m.data.cars {
+		if inScope(ctx, car.ProjectID) && !car.Archived() {
+			cars = append(cars, car)
+		}
+	}
+
+	sort.Slice(cars, func(i, j int) bool {
+		return cars[i].InspectionTo.ConvertToTime().Before(cars[j].InspectionTo.ConvertToTime())
+	})
+
+	if len(cars) > 5 {
+		cars = cars[:5]
+	}
+
+	results := make([]models.DashboardCarInspection, 0, len(cars))
+	for _, car := range cars {
+		results = append(results, models.DashboardCarInspection{
+			Date:               memoryTimestamp(car.InspectionTo.ConvertToTime()),
+			RegistrationNumber: car.RegistrationNumber,
+		})
+	}
+
+	return results, nil
+}
+
+func (m *Memory) EmployeePermits(ctx context.Context) ([]models.DashboardEmployeePermits, error) {
+	defer m.lock(ctx)()
+
+	type permit struct {
+		models.DashboardEmployeePermits
+		expires time.Time
+	}
+
+	var permits []permit
+
+	for _, e := range m.data.employees {
+		if !inScope(ctx, e.ProjectId) || e.Archived() {
+			continue
+		}
+
+		documents := []struct {
+			name string
+			date *models.Date
+		}{
+			{"OSH", ptr(e.Medicals.OSHValidUntil)},
+			{"Psychotests", e.Medicals.PsychotestsValidUntil},
+			{"Medical", ptr(e.Medicals.MedicalValidUntil)},
+			{"Sanitary", e.Medicals.SanitaryValidUntil},
+			{"Bio", e.ResidenceCard.Bio},
+			{"Visa", e.ResidenceCard.Visa},
+			{"TCard", e.ResidenceCard.TCard},
+		}
+
+		for _, doc := range documents {
+			if doc.date == nil || doc.date.ConvertToTime().IsZero() {
+				continue
+			}
+
+			permits = append(permits, permit{
+				DashboardEmployeePermits: models.DashboardEmployeePermits{
+					FirstName: e.FirstName,
+					LastName:  e.LastName,
+					Document:  doc.name,
+					Date:      memoryTimestamp(doc.date.ConvertToTime()),
+				},
+				expires: doc.date.ConvertToTime(),
+			})
+		}
+	}
+
+	sort.Slice(permits, func(i, j int) bool {
+		return permits[i].expires.Before(permits[j].expires)
+	})
+
+	if len(permits) > 50 {
+		permits = permits[:50]
+	}
+
+	results := make([]models.DashboardEmployeePermits, 0, len(permits))
+	for _, p := range permits {
+		results = append(results, p.DashboardEmployeePermits)
+	}
+
+	return results, nil
+}
+
+// memoryTimestamp formats t the way database/sql converts a date column
+// scanned into a string.
+func memoryTimestamp(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
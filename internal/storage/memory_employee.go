@@ -0,0 +1,335 @@
+package storage
+
+import (
+	"context"
+
+	"api/internal/auth"
+	"api/internal/models"
+	"github.com/pkg/errors"
+)
+
+func (m *Memory) Employees(ctx context.Context, filter models.EmployeeFilter) ([]models.Employee, int, error) {
+	defer m.lock(ctx)()
+
+	results := make([]models.Employee, 0)
+
+	for _, id := range sortedKeys(m.data.employees) {
+		e := m.data.employees[id]
+		if !inScope(ctx, e.ProjectId) || !listed(filter.ListFilter, e.Archival) {
+			continue
+		}
+
+		if filter.Pesel != "" && normalizeIdentifier(e.Pesel) != normalizeIdentifier(filter.Pesel) {
+			continue
+		}
+
+		if filter.PassportNumber != "" && normalizeIdentifier(e.PassportNumber) != normalizeIdentifier(filter.PassportNumber) {
+			continue
+		}
+
+		if filter.ProjectID != 0 && e.ProjectId != filter.ProjectID {
+			continue
+		}
+
+		if filter.ContractType != "" && e.Employment.ContractType != filter.ContractType {
+			continue
+		}
+
+		if filter.ExpiringBefore != nil && !expiresBefore(*filter.ExpiringBefore, &e.Medicals.OSHValidUntil, e.Medicals.PsychotestsValidUntil, &e.Medicals.MedicalValidUntil, e.Medicals.SanitaryValidUntil, e.ResidenceCard.Bio, e.ResidenceCard.Visa, e.ResidenceCard.TCard) {
+			continue
+		}
+
+		results = append(results, models.Employee{
+			ID:             e.ID,
+			LastName:       e.LastName,
+			FirstName:      e.FirstName,
+			Pesel:          e.Pesel,
+			PassportNumber: e.PassportNumber,
+			DateOfBirth:    e.DateOfBirth,
+			Archival:       e.Archival,
+		})
+	}
+
+	if err := memoryEmployeeSort.sort(results, filter.Sort); err != nil {
+		return nil, 0, err
+	}
+
+	return paginate(results, filter.ListFilter), len(results), nil
+}
+
+func (m *Memory) SearchEmployees(ctx context.Context, search models.EmployeeSearch) ([]models.Employee, int, error) {
+	defer m.lock(ctx)()
+
+	terms := searchTerms(search.Query)
+	identifier := searchIdentifier(search)
+	ranked := make([]rankedEmployee, 0)
+
+	for _, id := range sortedKeys(m.data.employees) {
+		e := m.data.employees[id]
+		if !inScope(ctx, e.ProjectId) || !listed(search.ListFilter, e.Archival) {
+			continue
+		}
+
+		key := searchKey(e.FirstName, e.LastName, e.MaidenName, e.Email)
+		identifierMatch := identifier != "" && (normalizeIdentifier(e.Pesel) == identifier || normalizeIdentifier(e.PassportNumber) == identifier)
+
+		if rank := searchRank(key, terms, identifierMatch); rank > 0 {
+			ranked = append(ranked, rankedEmployee{
+				Employee: models.Employee{
+					ID:             e.ID,
+					LastName:       e.LastName,
+					FirstName:      e.FirstName,
+					Pesel:          e.Pesel,
+					PassportNumber: e.PassportNumber,
+					Email:          e.Email,
+					DateOfBirth:    e.DateOfBirth,
+					Archival:       e.Archival,
+				},
+				rank: rank,
+			})
+		}
+	}
+
+	return paginate(sortRanked(ranked), search.ListFilter), len(ranked), nil
+}
+
+func (m *Memory) GetEmployee(ctx context.Context, id int) (models.Employee, error) {
+	defer m.lock(ctx)()
+
+	e, ok := m.data.employees[id]
+	if !ok || !inScope(ctx, e.ProjectId) {
+		return models.Employee{}, errors.Wrap(ErrNotFound, "failed to retrieve employee")
+	}
+
+	if stay, ok := m.data.currentStay(id, models.Today()); ok {
+		e.AccommodationId = ptr(stay.AccommodationID)
+	}
+
+	if driver, ok := m.data.currentCar(id); ok {
+		e.CarId = ptr(driver.CarID)
+	}
+
+	return e.Employee, nil
+}
+
+func (m *Memory) AddEmployee(ctx context.Context, newEmployee models.NewEmployee) (int, error) {
+	defer m.lock(ctx)()
+
+	if newEmployee.CarId != 0 {
+		return 0, errors.Wrap(ErrCarHandover, "failed to add car")
+	}
+
+	err := m.data.checkAssignments(ctx, newEmployee.ProjectId, newEmployee.AccommodationId, newEmployee.CarId)
+	if err != nil {
+		return 0, err
+	}
+
+	id := m.nextID()
+
+	m.data.employees[id] = memoryEmployee{
+		Employee: memoryEmployeeRecord(id, 1, models.UpdateEmployee(newEmployee)),
+	}
+	m.assignProject(id, newEmployee.ProjectId, assignmentStart(newEmployee.Employment.StartDate))
+
+	if err := m.moveIn(id, newEmployee.AccommodationId); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (m *Memory) UpdateEmployee(ctx context.Context, id, version int, updateEmployee models.UpdateEmployee) error {
+	return m.PatchEmployee(ctx, id, version, updateEmployee, nil)
+}
+
+func (m *Memory) PatchEmployee(ctx context.Context, id, version int, updateEmployee models.UpdateEmployee, fields models.Fields) error {
+	defer m.lock(ctx)()
+
+	e, ok := m.data.employees[id]
+	if !ok || !inScope(ctx, e.ProjectId) {
+		return errors.Wrap(ErrNotFound, "failed to retrieve employee")
+	}
+
+	if e.Archived() {
+		return ErrArchived
+	}
+
+	if err := checkVersion(e.Version, version); err != nil {
+		return err
+	}
+
+	updateEmployee = patched(e.Update(), updateEmployee, fields)
+
+	if fields.Has("CarId") {
+		if err := m.data.checkCarUnchanged(id, updateEmployee.CarId); err != nil {
+			return err
+		}
+	}
+
+	if fields.Has("ProjectId") || fields.Has("AccommodationId") {
+		err := m.data.checkAssignments(ctx, updateEmployee.ProjectId, updateEmployee.AccommodationId, updateEmployee.CarId)
+		if err != nil {
+			return err
+		}
+	}
+
+	if fields.Has("ProjectId") {
+		m.assignProject(id, updateEmployee.ProjectId, models.Today())
+	}
+
+	if fields.Has("AccommodationId") {
+		if err := m.moveIn(id, updateEmployee.AccommodationId); err != nil {
+			return err
+		}
+	}
+
+	login := e.Login
+	e.Employee = memoryEmployeeRecord(id, e.Version+1, updateEmployee)
+	e.Login = login
+
+	m.data.employees[id] = e
+
+	return nil
+}
+
+// ArchiveEmployee archives the employee and frees their car and
+// accommodation.
+func (m *Memory) ArchiveEmployee(ctx context.Context, id, version int) error {
+	defer m.lock(ctx)()
+
+	e, ok := m.data.employees[id]
+	if !ok || !inScope(ctx, e.ProjectId) {
+		return errors.Wrap(ErrNotFound, "failed to retrieve employee")
+	}
+
+	if err := checkVersion(e.Version, version); err != nil {
+		return err
+	}
+
+	if e.Archived() {
+		return ErrArchived
+	}
+
+	e.Archival = archivedNow(ctx)
+	e.Version++
+	m.data.employees[id] = e
+	m.data.vacate(func(s models.Stay) bool { return s.EmployeeID == id })
+	m.data.returnCars(*e.ArchivedAt, func(d models.CarDriver) bool { return d.EmployeeID == id })
+
+	return nil
+}
+
+func (m *Memory) RestoreEmployee(ctx context.Context, id, version int) error {
+	defer m.lock(ctx)()
+
+	e, ok := m.data.employees[id]
+	if !ok || !inScope(ctx, e.ProjectId) {
+		return errors.Wrap(ErrNotFound, "failed to retrieve employee")
+	}
+
+	if err := checkVersion(e.Version, version); err != nil {
+		return err
+	}
+
+	if !e.Archived() {
+		return errors.Wrap(ErrNotFound, "failed to restore Employee")
+	}
+
+	if err := m.data.checkProjectActive(e.ProjectId); err != nil {
+		return err
+	}
+
+	e.Archival = models.Archival{}
+	e.Version++
+	m.data.employees[id] = e
+
+	return nil
+}
+
+// checkAssignments applies the checks of Service.checkAssignments and
+// Service.checkAssignmentsActive, and the foreign keys of the employee's
+// project, accommodation and car.
+func (d memoryData) checkAssignments(ctx context.Context, projectID, accommodationID, carID int) error {
+	if _, restricted := auth.ProjectScope(ctx); restricted {
+		if err := checkProject(ctx, projectID); err != nil {
+			return err
+		}
+
+		if acc, ok := d.accommodations[accommodationID]; ok && !inScope(ctx, acc.ProjectID) {
+			return ErrForbidden
+		}
+
+		if car, ok := d.cars[carID]; ok && !inScope(ctx, car.ProjectID) {
+			return ErrForbidden
+		}
+	}
+
+	if err := d.checkProjectExists(projectID); err != nil {
+		return err
+	}
+
+	if _, ok := d.accommodations[accommodationID]; accommodationID != 0 && !ok {
+		return errors.Wrap(ErrReferenced, "accommodation does not exist")
+	}
+
+	if _, ok := d.cars[carID]; carID != 0 && !ok {
+		return errors.Wrap(ErrReferenced, "car does not exist")
+	}
+
+	if d.projects[projectID].Archived() || d.accommodations[accommodationID].Archived() || d.cars[carID].Archived() {
+		return ErrReferenced
+	}
+
+	return nil
+}
+
+// memoryEmployeeRecord builds the stored employee from a request. The car and
+// accommodation follow from the employee's driving periods and stays.
+func memoryEmployeeRecord(id, version int, e models.UpdateEmployee) models.Employee {
+	employee := models.Employee{
+		ID:               id,
+		LastName:         e.LastName,
+		FirstName:        e.FirstName,
+		PassportNumber:   e.PassportNumber,
+		Pesel:            e.Pesel,
+		Email:            e.Email,
+		DateOfBirth:      e.DateOfBirth,
+		FatherName:       e.FatherName,
+		MotherName:       e.MotherName,
+		MaidenName:       e.MaidenName,
+		MotherMaidenName: e.MotherMaidenName,
+		BankAccount:      e.BankAccount,
+		AddressPoland:    e.AddressPoland,
+		HomeAddress:      e.HomeAddress,
+		ResidenceCard: models.ResidenceCardDetails{
+			Bio:   memoryDate(e.ResidenceCard.Bio),
+			Visa:  memoryDate(e.ResidenceCard.Visa),
+			TCard: memoryDate(e.ResidenceCard.TCard),
+		},
+		Employment: models.EmploymentDetails{
+			ContractType:   e.Employment.ContractType,
+			StartDate:      e.Employment.StartDate,
+			EndDate:        memoryDate(e.Employment.EndDate),
+			Authorizations: e.Employment.Authorizations,
+		},
+		Medicals: models.MedicalDetails{
+			OSHValidUntil:         e.Medicals.OSHValidUntil,
+			PsychotestsValidUntil: memoryDate(e.Medicals.PsychotestsValidUntil),
+			MedicalValidUntil:     e.Medicals.MedicalValidUntil,
+			SanitaryValidUntil:    memoryDate(e.Medicals.SanitaryValidUntil),
+		},
+		ProjectId: e.ProjectId,
+		Version:   version,
+	}
+
+	return employee
+}
+
+func memoryDate(d models.NullableDate) *models.Date {
+	t := d.ConvertToTime()
+	if t == nil {
+		return nil
+	}
+
+	return ptr(models.Date(*t))
+}
@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"api/internal/auth"
+	"api/internal/models"
+	"github.com/pkg/errors"
+)
+
+func (m *Memory) CarDrivers(ctx context.Context, carID int, filter models.DriverFilter) ([]models.CarDriver, error) {
+	defer m.lock(ctx)()
+
+	car, ok := m.data.cars[carID]
+	if !ok || !inScope(ctx, car.ProjectID) {
+		return nil, errors.Wrap(ErrNotFound, "failed to retrieve car")
+	}
+
+	results := make([]models.CarDriver, 0)
+
+	for _, id := range sortedKeys(m.data.drivers) {
+		driver := m.data.drivers[id]
+		if driver.CarID != carID {
+			continue
+		}
+
+		if filter.To != nil && driver.ValidFrom.After(*filter.To) {
+			continue
+		}
+
+		if filter.From != nil && driver.ValidTo != nil && !driver.ValidTo.After(*filter.From) {
+			continue
+		}
+
+		e := m.data.employees[driver.EmployeeID]
+		driver.FirstName, driver.LastName = e.FirstName, e.LastName
+
+		results = append(results, driver)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].ValidFrom.After(results[j].ValidFrom)
+	})
+
+	return results, nil
+}
+
+func (m *Memory) CarHandovers(ctx context.Context, carID int) ([]models.CarHandover, error) {
+	defer m.lock(ctx)()
+
+	car, ok := m.data.cars[carID]
+	if !ok || !inScope(ctx, car.ProjectID) {
+		return nil, errors.Wrap(ErrNotFound, "failed to retrieve car")
+	}
+
+	results := make([]models.CarHandover, 0)
+
+	for _, id := range sortedKeys(m.data.handovers) {
+		if h := m.data.handovers[id]; h.CarID == carID {
+			results = append(results, h)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].HandedAt.After(results[j].HandedAt)
+	})
+
+	return results, nil
+}
+
+func (m *Memory) GetHandover(ctx context.Context, id int) (models.CarHandover, error) {
+	defer m.lock(ctx)()
+
+	h, ok := m.data.handovers[id]
+	if !ok || !inScope(ctx, m.data.cars[h.CarID].ProjectID) {
+		return models.CarHandover{}, errors.Wrap(ErrNotFound, "failed to retrieve handover")
+	}
+
+	return h, nil
+}
+
+func (m *Memory) AddHandover(ctx context.Context, carID int, newHandover models.NewHandover) (int, error) {
+	defer m.lock(ctx)()
+
+	car, ok := m.data.cars[carID]
+	if !ok || !inScope(ctx, car.ProjectID) {
+		return 0, errors.Wrap(ErrNotFound, "failed to retrieve car")
+	}
+
+	if car.Archived() {
+		return 0, ErrArchived
+	}
+
+	at := *newHandover.HandedAt
+
+	if holder, ok := m.data.carHolder(carID); ok && (newHandover.FromEmployeeID == nil || *newHandover.FromEmployeeID != holder.EmployeeID) {
+		return 0, errors.Wrap(ErrDriverMismatch, "the car is driven by another employee")
+	}
+
+	if from := newHandover.FromEmployeeID; from != nil {
+		e, ok := m.data.employees[*from]
+		if !ok || !inScope(ctx, e.ProjectId) {
+			return 0, errors.Wrap(ErrNotFound, "failed to retrieve employee")
+		}
+
+		current, ok := m.data.currentCar(*from)
+		if !ok || current.CarID != carID || current.ValidFrom.After(at) {
+			return 0, errors.Wrap(ErrDriverMismatch, "employee handing over the car does not have it")
+		}
+
+		m.data.returnCars(at, func(d models.CarDriver) bool { return d.EmployeeID == *from })
+	}
+
+	if to := newHandover.ToEmployeeID; to != nil {
+		e, ok := m.data.employees[*to]
+		if !ok || !inScope(ctx, e.ProjectId) {
+			return 0, errors.Wrap(ErrNotFound, "failed to retrieve employee")
+		}
+
+		if e.Archived() {
+			return 0, ErrArchived
+		}
+
+		if current, ok := m.data.currentCar(*to); ok {
+			if current.CarID == carID {
+				return 0, errors.Wrap(ErrDriverMismatch, "employee receiving the car already has it")
+			}
+
+			if current.ValidFrom.After(at) {
+				return 0, errors.Wrap(ErrDriverMismatch, "employee receiving the car got another one later")
+			}
+		}
+
+		m.assignCar(*to, carID, at)
+	}
+
+	for _, employeeID := range []*int{newHandover.FromEmployeeID, newHandover.ToEmployeeID} {
+		if employeeID != nil {
+			e := m.data.employees[*employeeID]
+			e.Version++
+			m.data.employees[*employeeID] = e
+		}
+	}
+
+	principal, _ := auth.FromContext(ctx)
+
+	id := m.nextID()
+
+	m.data.handovers[id] = models.CarHandover{
+		ID:             id,
+		CarID:          carID,
+		HandedAt:       at,
+		FromEmployeeID: newHandover.FromEmployeeID,
+		ToEmployeeID:   newHandover.ToEmployeeID,
+		Odometer:       newHandover.Odometer,
+		FuelLevel:      newHandover.FuelLevel,
+		DamageNotes:    newHandover.DamageNotes,
+		RecordedBy:     principal.Username,
+	}
+
+	return id, nil
+}
+
+// assignCar applies Service.assignCar.
+func (m *Memory) assignCar(employeeID, carID int, at time.Time) {
+	if current, ok := m.data.currentCar(employeeID); ok && current.CarID == carID {
+		return
+	}
+
+	m.data.returnCars(at, func(d models.CarDriver) bool { return d.EmployeeID == employeeID })
+
+	if carID == 0 {
+		return
+	}
+
+	id := m.nextID()
+
+	m.data.drivers[id] = models.CarDriver{
+		ID:         id,
+		CarID:      carID,
+		EmployeeID: employeeID,
+		ValidFrom:  at,
+	}
+}
+
+// returnCars applies Service.returnCars to the driving periods matching
+// match.
+func (d memoryData) returnCars(at time.Time, match func(models.CarDriver) bool) {
+	for id, driver := range d.drivers {
+		if driver.ValidTo == nil && match(driver) {
+			driver.ValidTo = &at
+			d.drivers[id] = driver
+		}
+	}
+}
+
+// carHolder returns the open driving period of the car.
+func (d memoryData) carHolder(carID int) (models.CarDriver, bool) {
+	for _, driver := range d.drivers {
+		if driver.CarID == carID && driver.ValidTo == nil {
+			return driver, true
+		}
+	}
+
+	return models.CarDriver{}, false
+}
+
+// checkCarUnchanged applies Service.checkCarUnchanged.
+func (d memoryData) checkCarUnchanged(employeeID, carID int) error {
+	current, _ := d.currentCar(employeeID)
+	if current.CarID != carID {
+		return errors.Wrap(ErrCarHandover, "failed to change car")
+	}
+
+	return nil
+}
+
+// currentCar returns the open driving period of the employee.
+func (d memoryData) currentCar(employeeID int) (models.CarDriver, bool) {
+	for _, driver := range d.drivers {
+		if driver.EmployeeID == employeeID && driver.ValidTo == nil {
+			return driver, true
+		}
+	}
+
+	return models.CarDriver{}, false
+}
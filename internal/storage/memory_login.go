@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"api/internal/models"
+	"github.com/pkg/errors"
+)
+
+func (m *Memory) GetUser(ctx context.Context, login string) (models.User, error) {
+	defer m.lock(ctx)()
+
+	e, ok := m.data.employeeByLogin(login)
+	if !ok || e.Archived() {
+		return models.User{}, errors.Wrap(ErrNotFound, "failed to query for user")
+	}
+
+	role := e.role
+	if role == "" {
+		role = models.RoleReadOnly
+	}
+
+	return models.User{
+		EmployeeID:     e.ID,
+		Login:          *e.Login,
+		Password:       e.password,
+		Role:           role,
+		FailedAttempts: e.failedAttempts,
+		LockedUntil:    e.lockedUntil,
+	}, nil
+}
+
+func (m *Memory) SetEmployeeAccount(ctx context.Context, employeeID int, login, passwordHash string, role models.Role) error {
+	defer m.lock(ctx)()
+
+	e, ok := m.data.employees[employeeID]
+	if !ok {
+		return errors.Wrap(ErrNotFound, "failed to set employee account")
+	}
+
+	if other, ok := m.data.employeeByLogin(login); ok && other.ID != employeeID {
+		return errors.Wrap(ErrConflict, "failed to set employee account")
+	}
+
+	e.Login = ptr(login)
+	e.password = passwordHash
+	e.role = role
+	m.data.employees[employeeID] = e
+
+	return nil
+}
+
+func (m *Memory) UpdatePassword(ctx context.Context, login, passwordHash string) error {
+	defer m.lock(ctx)()
+
+	e, ok := m.data.employeeByLogin(login)
+	if !ok {
+		return errors.Wrap(ErrNotFound, "failed to update password")
+	}
+
+	e.password = passwordHash
+	m.data.employees[e.ID] = e
+
+	return nil
+}
+
+func (m *Memory) AddPasswordReset(ctx context.Context, reset models.PasswordReset) error {
+	defer m.lock(ctx)()
+
+	if _, ok := m.data.passwordResets[reset.Hash]; ok {
+		return errors.Wrap(ErrConflict, "failed to add password reset")
+	}
+
+	m.data.passwordResets[reset.Hash] = memoryPasswordReset{PasswordReset: reset}
+
+	return nil
+}
+
+// UsePasswordReset marks an unused, unexpired reset token as used and returns
+// the login it was issued for.
+func (m *Memory) UsePasswordReset(ctx context.Context, hash string, now time.Time) (string, error) {
+	defer m.lock(ctx)()
+
+	reset, ok := m.data.passwordResets[hash]
+	if !ok || reset.usedAt != nil || !reset.ExpiresAt.After(now) {
+		return "", errors.Wrap(ErrNotFound, "failed to use password reset")
+	}
+
+	reset.usedAt = ptr(now)
+	m.data.passwordResets[hash] = reset
+
+	return reset.Login, nil
+}
+
+func (m *Memory) SetLoginFailures(ctx context.Context, login string, failures int, lockedUntil *time.Time) error {
+	defer m.lock(ctx)()
+
+	e, ok := m.data.employeeByLogin(login)
+	if !ok {
+		return errors.Wrap(ErrNotFound, "failed to set login failures")
+	}
+
+	e.failedAttempts = failures
+	e.lockedUntil = lockedUntil
+	m.data.employees[e.ID] = e
+
+	return nil
+}
+
+// AddLoginFailure increments the failed login count of the user and returns
+// the new count.
+func (m *Memory) AddLoginFailure(ctx context.Context, login string) (int, error) {
+	defer m.lock(ctx)()
+
+	e, ok := m.data.employeeByLogin(login)
+	if !ok {
+		return 0, errors.Wrap(ErrNotFound, "failed to add login failure")
+	}
+
+	e.failedAttempts++
+	m.data.employees[e.ID] = e
+
+	return e.failedAttempts, nil
+}
+
+// LockUser locks the user out until the given time, unless they already are
+// for longer.
+func (m *Memory) LockUser(ctx context.Context, login string, until time.Time) error {
+	defer m.lock(ctx)()
+
+	e, ok := m.data.employeeByLogin(login)
+	if !ok {
+		return errors.Wrap(ErrNotFound, "failed to lock user")
+	}
+
+	if e.lockedUntil == nil || e.lockedUntil.Before(until) {
+		e.lockedUntil = ptr(until)
+		m.data.employees[e.ID] = e
+	}
+
+	return nil
+}
+
+func (m *Memory) AddLoginAttempt(ctx context.Context, attempt models.LoginAttempt) error {
+	defer m.lock(ctx)()
+
+	m.data.loginAttempts = append(m.data.loginAttempts, attempt)
+
+	return nil
+}
+
+func (m *Memory) GetUserProjects(ctx context.Context, employeeID int) ([]int, error) {
+	defer m.lock(ctx)()
+
+	results := slices.Clone(m.data.userProjects[employeeID])
+	if results == nil {
+		results = make([]int, 0)
+	}
+
+	slices.Sort(results)
+
+	return results, nil
+}
+
+// SetUserProjects replaces the set of projects the user is bound to.
+func (m *Memory) SetUserProjects(ctx context.Context, employeeID int, projectIDs []int) error {
+	defer m.lock(ctx)()
+
+	if _, ok := m.data.employees[employeeID]; !ok {
+		return errors.Wrap(ErrReferenced, "employee does not exist")
+	}
+
+	for _, projectID := range projectIDs {
+		if err := m.data.checkProjectExists(projectID); err != nil {
+			return errors.Wrap(err, "failed to add user project")
+		}
+	}
+
+	m.data.userProjects[employeeID] = slices.Compact(slices.Sorted(slices.Values(projectIDs)))
+
+	return nil
+}
+
+func (d memoryData) employeeByLogin(login string) (memoryEmployee, bool) {
+	for _, e := range d.employees {
+		if e.Login != nil && *e.Login == login {
+			return e, true
+		}
+	}
+
+	return memoryEmployee{}, false
+}
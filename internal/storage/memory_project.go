@@ -0,0 +1,251 @@
+package storage
+
+import (
+	"context"
+
+	"api/internal/auth"
+	"api/internal/models"
+	"github.com/pkg/errors"
+)
+
+func (m *Memory) Projects(ctx context.Context, filter models.ProjectFilter) ([]models.Project, int, error) {
+	defer m.lock(ctx)()
+
+	results := make([]models.Project, 0)
+
+	for _, id := range sortedKeys(m.data.projects) {
+		p := m.data.projects[id]
+		if !inScope(ctx, id) || !listed(filter.ListFilter, p.Archival) {
+			continue
+		}
+
+		results = append(results, models.Project{
+			ID:             p.ID,
+			Name:           p.Name,
+			OfficeAddress:  p.OfficeAddress,
+			ProjectNIP:     p.ProjectNIP,
+			EmployeeAmount: m.data.projectEmployees(id, filter.AsOf),
+			FreePlaces:     m.data.projectFreePlaces(id, filter.AsOf),
+			AmountCars:     m.data.projectCars(id),
+			Archival:       p.Archival,
+		})
+	}
+
+	if err := memoryProjectSort.sort(results, filter.Sort); err != nil {
+		return nil, 0, err
+	}
+
+	return paginate(results, filter.ListFilter), len(results), nil
+}
+
+func (m *Memory) GetProject(ctx context.Context, id int) (models.Project, error) {
+	defer m.lock(ctx)()
+
+	p, ok := m.data.projects[id]
+	if !ok || !inScope(ctx, id) {
+		return models.Project{}, errors.Wrap(ErrNotFound, "failed to retrieve project")
+	}
+
+	return p, nil
+}
+
+func (m *Memory) AddProject(ctx context.Context, newProject models.NewProject) (int, error) {
+	defer m.lock(ctx)()
+
+	if _, restricted := auth.ProjectScope(ctx); restricted {
+		return 0, ErrForbidden
+	}
+
+	id := m.nextID()
+
+	m.data.projects[id] = models.Project{
+		ID:            id,
+		Name:          newProject.Name,
+		OfficeAddress: newProject.OfficeAddress,
+		ProjectNIP:    newProject.ProjectNIP,
+		FirstName:     newProject.FirstName,
+		LastName:      newProject.LastName,
+		Phone:         newProject.Phone,
+		Position:      newProject.Position,
+		Version:       1,
+	}
+
+	return id, nil
+}
+
+// ArchiveProject archives the project together with its accommodations and
+// cars, and unassigns the employees using them.
+func (m *Memory) ArchiveProject(ctx context.Context, id, version int) error {
+	defer m.lock(ctx)()
+
+	p, ok := m.data.projects[id]
+	if !ok || !inScope(ctx, id) {
+		return errors.Wrap(ErrNotFound, "failed to retrieve project")
+	}
+
+	if err := checkVersion(p.Version, version); err != nil {
+		return err
+	}
+
+	if p.Archived() {
+		return ErrArchived
+	}
+
+	p.Archival = archivedNow(ctx)
+	p.Version++
+	m.data.projects[id] = p
+
+	for accID, acc := range m.data.accommodations {
+		if acc.ProjectID == id && !acc.Archived() {
+			acc.Archival = p.Archival
+			acc.Version++
+			m.data.accommodations[accID] = acc
+			m.data.vacate(func(s models.Stay) bool { return s.AccommodationID == accID })
+		}
+	}
+
+	for carID, car := range m.data.cars {
+		if car.ProjectID == id && !car.Archived() {
+			car.Archival = p.Archival
+			car.Version++
+			m.data.cars[carID] = car
+			m.data.returnCars(*p.ArchivedAt, func(d models.CarDriver) bool { return d.CarID == carID })
+		}
+	}
+
+	return nil
+}
+
+// RestoreProject brings back the project together with the accommodations
+// and cars archived with it.
+func (m *Memory) RestoreProject(ctx context.Context, id, version int) error {
+	defer m.lock(ctx)()
+
+	p, ok := m.data.projects[id]
+	if !ok || !inScope(ctx, id) {
+		return errors.Wrap(ErrNotFound, "failed to retrieve project")
+	}
+
+	if err := checkVersion(p.Version, version); err != nil {
+		return err
+	}
+
+	if !p.Archived() {
+		return errors.Wrap(ErrNotFound, "failed to restore Project")
+	}
+
+	for accID, acc := range m.data.accommodations {
+		if acc.ProjectID == id && acc.Archived() && acc.ArchivedAt.Equal(*p.ArchivedAt) {
+			acc.Archival = models.Archival{}
+			acc.Version++
+			m.data.accommodations[accID] = acc
+		}
+	}
+
+	for carID, car := range m.data.cars {
+		if car.ProjectID == id && car.Archived() && car.ArchivedAt.Equal(*p.ArchivedAt) {
+			car.Archival = models.Archival{}
+			car.Version++
+			m.data.cars[carID] = car
+		}
+	}
+
+	p.Archival = models.Archival{}
+	p.Version++
+	m.data.projects[id] = p
+
+	return nil
+}
+
+func (m *Memory) UpdateProject(ctx context.Context, id, version int, updateProject models.UpdateProject) error {
+	return m.PatchProject(ctx, id, version, updateProject, nil)
+}
+
+func (m *Memory) PatchProject(ctx context.Context, id, version int, updateProject models.UpdateProject, fields models.Fields) error {
+	defer m.lock(ctx)()
+
+	p, ok := m.data.projects[id]
+	if !ok || !inScope(ctx, id) {
+		return errors.Wrap(ErrNotFound, "failed to retrieve project")
+	}
+
+	if p.Archived() {
+		return ErrArchived
+	}
+
+	if err := checkVersion(p.Version, version); err != nil {
+		return err
+	}
+
+	updateProject = patched(p.Update(), updateProject, fields)
+
+	m.data.projects[id] = models.Project{
+		ID:            id,
+		Name:          updateProject.Name,
+		OfficeAddress: updateProject.OfficeAddress,
+		ProjectNIP:    updateProject.ProjectNIP,
+		FirstName:     updateProject.FirstName,
+		LastName:      updateProject.LastName,
+		Phone:         updateProject.Phone,
+		Position:      updateProject.Position,
+		Version:       p.Version + 1,
+	}
+
+	return nil
+}
+
+func (m *Memory) GetProjectNames(ctx context.Context) ([]models.ProjectNames, error) {
+	defer m.lock(ctx)()
+
+	results := make([]models.ProjectNames, 0)
+
+	for _, id := range sortedKeys(m.data.projects) {
+		if inScope(ctx, id) && !m.data.projects[id].Archived() {
+			results = append(results, models.ProjectNames{ID: id, Name: m.data.projects[id].Name})
+		}
+	}
+
+	return results, nil
+}
+
+func (d memoryData) projectFreePlaces(projectID int, day models.Date) int {
+	n := 0
+	for id, acc := range d.accommodations {
+		if acc.ProjectID == projectID && !acc.Archived() {
+			n += acc.NumberOfPlaces - d.occupiedPlaces(id, day)
+		}
+	}
+
+	return n
+}
+
+func (d memoryData) projectCars(projectID int) int {
+	n := 0
+	for _, car := range d.cars {
+		if car.ProjectID == projectID && !car.Archived() {
+			n++
+		}
+	}
+
+	return n
+}
+
+// checkProjectExists returns ErrReferenced if there is no such project, the
+// error a foreign key violation results in with Service.
+func (d memoryData) checkProjectExists(projectID int) error {
+	if _, ok := d.projects[projectID]; !ok {
+		return errors.Wrap(ErrReferenced, "project does not exist")
+	}
+
+	return nil
+}
+
+// checkProjectActive returns ErrReferenced if the project has been archived,
+// see Service.checkReference.
+func (d memoryData) checkProjectActive(projectID int) error {
+	if d.projects[projectID].Archived() {
+		return ErrReferenced
+	}
+
+	return nil
+}
@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"api/internal/models"
+	"github.com/pkg/errors"
+)
+
+func (m *Memory) AccommodationStays(ctx context.Context, accommodationID int) ([]models.Stay, error) {
+	defer m.lock(ctx)()
+
+	acc, ok := m.data.accommodations[accommodationID]
+	if !ok || !inScope(ctx, acc.ProjectID) {
+		return nil, errors.Wrap(ErrNotFound, "failed to retrieve accommodation")
+	}
+
+	return m.data.staysWhere(func(s models.Stay) bool { return s.AccommodationID == accommodationID }), nil
+}
+
+func (m *Memory) EmployeeStays(ctx context.Context, employeeID int) ([]models.Stay, error) {
+	defer m.lock(ctx)()
+
+	e, ok := m.data.employees[employeeID]
+	if !ok || !inScope(ctx, e.ProjectId) {
+		return nil, errors.Wrap(ErrNotFound, "failed to retrieve employee")
+	}
+
+	return m.data.staysWhere(func(s models.Stay) bool {
+		return s.EmployeeID == employeeID && inScope(ctx, m.data.accommodations[s.AccommodationID].ProjectID)
+	}), nil
+}
+
+func (m *Memory) GetStay(ctx context.Context, id int) (models.Stay, error) {
+	defer m.lock(ctx)()
+
+	stay, ok := m.data.stays[id]
+	if !ok || !inScope(ctx, m.data.accommodations[stay.AccommodationID].ProjectID) {
+		return models.Stay{}, errors.Wrap(ErrNotFound, "failed to retrieve stay")
+	}
+
+	return m.data.withNames(stay), nil
+}
+
+func (m *Memory) AddStay(ctx context.Context, accommodationID int, newStay models.NewStay) (int, error) {
+	defer m.lock(ctx)()
+
+	acc, ok := m.data.accommodations[accommodationID]
+	if !ok || !inScope(ctx, acc.ProjectID) {
+		return 0, errors.Wrap(ErrNotFound, "failed to retrieve accommodation")
+	}
+
+	if acc.Archived() {
+		return 0, ErrArchived
+	}
+
+	e, ok := m.data.employees[newStay.EmployeeID]
+	if !ok || !inScope(ctx, e.ProjectId) {
+		return 0, errors.Wrap(ErrNotFound, "failed to retrieve employee")
+	}
+
+	if e.Archived() {
+		return 0, ErrArchived
+	}
+
+	return m.checkIn(newStay.EmployeeID, accommodationID, newStay.CheckIn, newStay.CheckOut)
+}
+
+func (m *Memory) CheckOutStay(ctx context.Context, id int, day models.Date) error {
+	defer m.lock(ctx)()
+
+	stay, ok := m.data.stays[id]
+	if !ok || !inScope(ctx, m.data.accommodations[stay.AccommodationID].ProjectID) {
+		return errors.Wrap(ErrNotFound, "failed to retrieve stay")
+	}
+
+	stay.CheckOut = &day
+	m.data.stays[id] = stay
+
+	return nil
+}
+
+func (m *Memory) CancelStay(ctx context.Context, id int) error {
+	defer m.lock(ctx)()
+
+	stay, ok := m.data.stays[id]
+	if !ok || !inScope(ctx, m.data.accommodations[stay.AccommodationID].ProjectID) {
+		return errors.Wrap(ErrNotFound, "failed to retrieve stay")
+	}
+
+	delete(m.data.stays, id)
+
+	return nil
+}
+
+// checkIn applies Service.checkIn.
+func (m *Memory) checkIn(employeeID, accommodationID int, checkIn models.Date, checkOut *models.Date) (int, error) {
+	own := m.data.staysWhere(func(s models.Stay) bool { return s.EmployeeID == employeeID })
+
+	if checkOut == nil {
+		for _, stay := range own {
+			if stay.CheckOut == nil && time.Time(stay.CheckIn).Before(time.Time(checkIn)) {
+				stay.CheckOut = &checkIn
+				m.data.stays[stay.ID] = stay
+			}
+		}
+
+		for _, stay := range own {
+			if time.Time(stay.CheckIn).After(time.Time(checkIn)) {
+				checkOut = &stay.CheckIn
+				break
+			}
+		}
+	}
+
+	overlaps := func(s models.Stay) bool {
+		return (s.CheckOut == nil || time.Time(*s.CheckOut).After(time.Time(checkIn))) &&
+			(checkOut == nil || time.Time(s.CheckIn).Before(time.Time(*checkOut)))
+	}
+
+	if len(m.data.staysWhere(func(s models.Stay) bool { return s.EmployeeID == employeeID && overlaps(s) })) > 0 {
+		return 0, ErrOverlap
+	}
+
+	taken := m.data.staysWhere(func(s models.Stay) bool { return s.AccommodationID == accommodationID && overlaps(s) })
+	if peakOccupancy(taken, checkIn, checkOut) >= m.data.accommodations[accommodationID].NumberOfPlaces {
+		return 0, ErrNoVacancy
+	}
+
+	id := m.nextID()
+
+	m.data.stays[id] = models.Stay{
+		ID:              id,
+		EmployeeID:      employeeID,
+		AccommodationID: accommodationID,
+		CheckIn:         checkIn,
+		CheckOut:        checkOut,
+	}
+
+	return id, nil
+}
+
+// moveIn applies Service.moveIn.
+func (m *Memory) moveIn(employeeID, accommodationID int) error {
+	today := models.Today()
+
+	if current, ok := m.data.currentStay(employeeID, today); ok {
+		if current.AccommodationID == accommodationID {
+			return nil
+		}
+
+		if time.Time(current.CheckIn).Equal(time.Time(today)) {
+			delete(m.data.stays, current.ID)
+		} else {
+			current.CheckOut = &today
+			m.data.stays[current.ID] = current
+		}
+	}
+
+	if accommodationID == 0 {
+		return nil
+	}
+
+	_, err := m.checkIn(employeeID, accommodationID, today, nil)
+
+	return err
+}
+
+// vacate applies Service.vacate to the stays matching match.
+func (d memoryData) vacate(match func(models.Stay) bool) {
+	today := models.Today()
+
+	for id, stay := range d.stays {
+		switch {
+		case !match(stay):
+		case !time.Time(stay.CheckIn).Before(time.Time(today)):
+			delete(d.stays, id)
+		case stay.CheckOut == nil || time.Time(*stay.CheckOut).After(time.Time(today)):
+			stay.CheckOut = &today
+			d.stays[id] = stay
+		}
+	}
+}
+
+// currentStay returns the stay of the employee covering the given day.
+func (d memoryData) currentStay(employeeID int, day models.Date) (models.Stay, bool) {
+	for _, stay := range d.stays {
+		if stay.EmployeeID == employeeID && stay.Covers(day) {
+			return stay, true
+		}
+	}
+
+	return models.Stay{}, false
+}
+
+// occupiedPlaces returns the number of places taken in the accommodation on
+// the given day.
+func (d memoryData) occupiedPlaces(accommodationID int, day models.Date) int {
+	return len(d.staysWhere(func(s models.Stay) bool { return s.AccommodationID == accommodationID && s.Covers(day) }))
+}
+
+// staysWhere returns the stays matching match in the order of their
+// check-in.
+func (d memoryData) staysWhere(match func(models.Stay) bool) []models.Stay {
+	results := make([]models.Stay, 0)
+
+	for _, id := range sortedKeys(d.stays) {
+		if stay := d.stays[id]; match(stay) {
+			results = append(results, d.withNames(stay))
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return time.Time(results[i].CheckIn).Before(time.Time(results[j].CheckIn))
+	})
+
+	return results
+}
+
+func (d memoryData) withNames(stay models.Stay) models.Stay {
+	e := d.employees[stay.EmployeeID]
+	stay.FirstName, stay.LastName = e.FirstName, e.LastName
+
+	return stay
+}
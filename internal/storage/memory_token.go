@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"api/internal/models"
+	"github.com/pkg/errors"
+)
+
+func (m *Memory) AddRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	defer m.lock(ctx)()
+
+	if _, ok := m.data.refreshTokens[token.Hash]; ok {
+		return errors.Wrap(ErrConflict, "failed to add refresh token")
+	}
+
+	m.data.refreshTokens[token.Hash] = token
+
+	return nil
+}
+
+func (m *Memory) GetRefreshToken(ctx context.Context, hash string) (models.RefreshToken, error) {
+	defer m.lock(ctx)()
+
+	token, ok := m.data.refreshTokens[hash]
+	if !ok {
+		return models.RefreshToken{}, errors.Wrap(ErrNotFound, "failed to retrieve refresh token")
+	}
+
+	return token, nil
+}
+
+// RevokeRefreshToken revokes the refresh token. It returns ErrNotFound if
+// the token had already been revoked, so a token can be rotated only once.
+func (m *Memory) RevokeRefreshToken(ctx context.Context, hash string, now time.Time) error {
+	defer m.lock(ctx)()
+
+	token, ok := m.data.refreshTokens[hash]
+	if !ok || token.RevokedAt != nil {
+		return ErrNotFound
+	}
+
+	token.RevokedAt = ptr(now)
+	m.data.refreshTokens[hash] = token
+
+	return nil
+}
+
+// RevokeAccessToken adds the access token to the denylist and revokes the
+// refresh token it was issued with. Denylist entries of tokens that have
+// expired on their own are purged along the way.
+func (m *Memory) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time, now time.Time) error {
+	defer m.lock(ctx)()
+
+	for revoked, exp := range m.data.revokedTokens {
+		if exp.Before(now) {
+			delete(m.data.revokedTokens, revoked)
+		}
+	}
+
+	if _, ok := m.data.revokedTokens[jti]; ok {
+		return errors.Wrap(ErrConflict, "failed to revoke access token")
+	}
+
+	m.data.revokedTokens[jti] = expiresAt
+
+	for hash, token := range m.data.refreshTokens {
+		if token.AccessJTI == jti && token.RevokedAt == nil {
+			token.RevokedAt = ptr(now)
+			m.data.refreshTokens[hash] = token
+		}
+	}
+
+	return nil
+}
+
+// RevokeUserTokens denylists the access tokens of every unexpired session of
+// the user and revokes their refresh tokens.
+func (m *Memory) RevokeUserTokens(ctx context.Context, login string, now time.Time) error {
+	defer m.lock(ctx)()
+
+	for hash, token := range m.data.refreshTokens {
+		if token.Login != login {
+			continue
+		}
+
+		if token.AccessExpiresAt.After(now) {
+			if _, ok := m.data.revokedTokens[token.AccessJTI]; !ok {
+				m.data.revokedTokens[token.AccessJTI] = token.AccessExpiresAt
+			}
+		}
+
+		if token.RevokedAt == nil {
+			token.RevokedAt = ptr(now)
+			m.data.refreshTokens[hash] = token
+		}
+	}
+
+	return nil
+}
+
+func (m *Memory) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	defer m.lock(ctx)()
+
+	_, revoked := m.data.revokedTokens[jti]
+
+	return revoked, nil
+}
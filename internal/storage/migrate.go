@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// migrationFiles holds the schema of each dialect as numbered pairs of
+// NNNN_name.up.sql and NNNN_name.down.sql files. Within a file, a line
+// containing only GO separates batches that are executed one after another.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+var migrationName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+var batchSeparator = regexp.MustCompile(`(?im)^\s*GO\s*$`)
+
+// Migration is a single schema change.
+type Migration struct {
+	Version int
+	Name    string
+
+	up, down string
+}
+
+// MigrationStatus reports whether a migration has been applied.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// migrations returns the migrations of the dialect ordered by version.
+func (s *Service) migrations() ([]Migration, error) {
+	dir := path.Join("migrations", s.dialect.name())
+
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read migrations")
+	}
+
+	byVersion := make(map[int]*Migration)
+
+	for _, entry := range entries {
+		match := migrationName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, errors.Errorf("unexpected migration file %q", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, errors.Errorf("migration %d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+
+		content, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read migration %q", entry.Name())
+		}
+
+		if match[3] == "up" {
+			m.up = string(content)
+		} else {
+			m.down = string(content)
+		}
+	}
+
+	results := make([]Migration, 0, len(byVersion))
+	for _, version := range slices.Sorted(maps.Keys(byVersion)) {
+		m := byVersion[version]
+		if m.up == "" || m.down == "" {
+			return nil, errors.Errorf("migration %s lacks an up or down file", m)
+		}
+
+		results = append(results, *m)
+	}
+
+	return results, nil
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// MigrationStatus lists every known migration together with the time it was
+// applied, if it was.
+func (s *Service) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := s.migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := s.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Migration: m}
+		if at, ok := applied[m.Version]; ok {
+			status.AppliedAt = &at
+		}
+
+		results = append(results, status)
+	}
+
+	return results, nil
+}
+
+// MigrateUp applies all pending migrations in order, each in its own
+// transaction, and returns the ones it applied.
+func (s *Service) MigrateUp(ctx context.Context) ([]Migration, error) {
+	statuses, err := s.MigrationStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+
+	for _, status := range statuses {
+		if status.AppliedAt != nil {
+			continue
+		}
+
+		m := status.Migration
+
+		err = s.InTx(ctx, func(ctx context.Context) error {
+			if err := s.execBatches(ctx, m.up); err != nil {
+				return err
+			}
+
+			sql := "INSERT INTO schema_migrations (Version, Name, Applied_At) VALUES (@p1, @p2, @p3);"
+			_, err := s.conn(ctx).ExecContext(ctx, sql, m.Version, m.Name, time.Now().UTC())
+
+			return err
+		})
+		if err != nil {
+			return applied, errors.Wrapf(err, "failed to apply migration %s", m)
+		}
+
+		applied = append(applied, m)
+	}
+
+	return applied, nil
+}
+
+// MigrateDown reverts the given number of the most recently applied
+// migrations and returns the ones it reverted.
+func (s *Service) MigrateDown(ctx context.Context, steps int) ([]Migration, error) {
+	statuses, err := s.MigrationStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+
+	for _, status := range slices.Backward(statuses) {
+		if len(reverted) == steps {
+			break
+		}
+
+		if status.AppliedAt == nil {
+			continue
+		}
+
+		m := status.Migration
+
+		err = s.InTx(ctx, func(ctx context.Context) error {
+			if err := s.execBatches(ctx, m.down); err != nil {
+				return err
+			}
+
+			_, err := s.conn(ctx).ExecContext(ctx, "DELETE FROM schema_migrations WHERE Version = @p1;", m.Version)
+
+			return err
+		})
+		if err != nil {
+			return reverted, errors.Wrapf(err, "failed to revert migration %s", m)
+		}
+
+		reverted = append(reverted, m)
+	}
+
+	return reverted, nil
+}
+
+// appliedMigrations creates the schema_migrations table if needed and returns
+// the applied versions.
+func (s *Service) appliedMigrations(ctx context.Context) (map[int]time.Time, error) {
+	_, err := s.conn(ctx).ExecContext(ctx, s.dialect.migrationsTable())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create schema_migrations table")
+	}
+
+	rows, err := s.conn(ctx).QueryContext(ctx, "SELECT Version, Applied_At FROM schema_migrations;")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for applied migrations")
+	}
+	defer rows.Close()
+
+	results := make(map[int]time.Time)
+
+	for rows.Next() {
+		var (
+			version   int
+			appliedAt time.Time
+		)
+
+		err = rows.Scan(&version, &appliedAt)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+
+		results[version] = appliedAt
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to iterate rows")
+	}
+
+	return results, nil
+}
+
+func (s *Service) execBatches(ctx context.Context, script string) error {
+	for _, batch := range batchSeparator.Split(script, -1) {
+		if strings.TrimSpace(batch) == "" {
+			continue
+		}
+
+		if _, err := s.conn(ctx).ExecContext(ctx, batch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
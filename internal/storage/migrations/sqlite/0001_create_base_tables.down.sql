@@ -0,0 +1,15 @@
+DROP TABLE Employee_Car;
+DROP TABLE Employee_Accommodation;
+DROP TABLE Employee_Project;
+DROP TABLE Employment;
+DROP TABLE Medicals;
+DROP TABLE Residence_Card;
+DROP TABLE Employee;
+DROP TABLE Payments;
+DROP TABLE Contact;
+DROP TABLE Accommodation;
+DROP TABLE Leasing;
+DROP TABLE Service;
+DROP TABLE Car;
+DROP TABLE Contact_Person;
+DROP TABLE Project;
@@ -0,0 +1,134 @@
+CREATE TABLE Project (
+	Id_Project INTEGER PRIMARY KEY,
+	Name TEXT NOT NULL,
+	Office_Address TEXT,
+	Project_NIP TEXT
+);
+
+CREATE TABLE Contact_Person (
+	Id_Contact_Person INTEGER PRIMARY KEY,
+	Id_Project INTEGER NOT NULL REFERENCES Project (Id_Project),
+	First_Name TEXT,
+	Last_Name TEXT,
+	Phone TEXT,
+	Position TEXT
+);
+
+CREATE TABLE Car (
+	Id_Car INTEGER PRIMARY KEY,
+	Model TEXT,
+	Color TEXT,
+	Registration_Number TEXT,
+	VIN_Number TEXT UNIQUE,
+	Inspection_From DATE,
+	Inspection_To DATE,
+	Insurance_From DATE,
+	Insurance_To DATE,
+	Fleet_Card_Number TEXT,
+	Id_Project INTEGER REFERENCES Project (Id_Project)
+);
+
+CREATE TABLE Service (
+	Id_Service INTEGER PRIMARY KEY,
+	Id_Car INTEGER NOT NULL REFERENCES Car (Id_Car),
+	Service_Name TEXT,
+	Address TEXT,
+	Phone_Number TEXT
+);
+
+CREATE TABLE Leasing (
+	Id_Leasing INTEGER PRIMARY KEY,
+	Id_Car INTEGER NOT NULL REFERENCES Car (Id_Car),
+	Amount REAL,
+	Monthly_Payment REAL,
+	Payment_Day INTEGER
+);
+
+CREATE TABLE Accommodation (
+	Id_Accommodation INTEGER PRIMARY KEY,
+	Id_Project INTEGER REFERENCES Project (Id_Project),
+	City TEXT,
+	Accommodation_Address TEXT,
+	Number_Of_Places INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE Contact (
+	Id_Contact INTEGER PRIMARY KEY,
+	Id_Accommodation INTEGER NOT NULL REFERENCES Accommodation (Id_Accommodation),
+	First_Name TEXT,
+	Last_Name TEXT,
+	Phone_Number TEXT
+);
+
+CREATE TABLE Payments (
+	Id_Payment INTEGER PRIMARY KEY,
+	Id_Accommodation INTEGER NOT NULL REFERENCES Accommodation (Id_Accommodation),
+	Cost REAL,
+	Deposit REAL,
+	Contract TEXT,
+	Account_Number TEXT,
+	Payment_Day INTEGER
+);
+
+CREATE TABLE Employee (
+	Id_Employee INTEGER PRIMARY KEY,
+	Last_Name TEXT,
+	First_Name TEXT,
+	Passport_Number TEXT,
+	Pesel TEXT,
+	Email TEXT,
+	Date_Of_Birth DATE,
+	Father_Name TEXT,
+	Mother_Name TEXT,
+	Maiden_Name TEXT,
+	Mother_Maiden_Name TEXT,
+	Bank_Account TEXT,
+	Address_Poland TEXT,
+	Home_Address TEXT,
+	Login TEXT UNIQUE,
+	Password TEXT
+);
+
+CREATE TABLE Residence_Card (
+	Id_Residence_Card INTEGER PRIMARY KEY,
+	Employee_Id INTEGER NOT NULL REFERENCES Employee (Id_Employee),
+	Bio DATE,
+	Visa DATE,
+	Tcard DATE
+);
+
+CREATE TABLE Medicals (
+	Id_Medicals INTEGER PRIMARY KEY,
+	Id_Employee INTEGER NOT NULL REFERENCES Employee (Id_Employee),
+	OSH_Valid_Until DATE,
+	Psychotests_Valid_Until DATE,
+	Medical_Valid_Until DATE,
+	Sanitary_Valid_Until DATE
+);
+
+CREATE TABLE Employment (
+	Id_Employment INTEGER PRIMARY KEY,
+	Id_Employee INTEGER NOT NULL REFERENCES Employee (Id_Employee),
+	Contract_Type TEXT,
+	Start_Date DATE,
+	End_Date DATE,
+	Authorizations TEXT
+);
+
+CREATE TABLE Employee_Project (
+	Id_Employee INTEGER NOT NULL REFERENCES Employee (Id_Employee),
+	Id_Project INTEGER NOT NULL REFERENCES Project (Id_Project),
+	PRIMARY KEY (Id_Employee, Id_Project)
+);
+
+CREATE TABLE Employee_Accommodation (
+	Id_Employee INTEGER NOT NULL REFERENCES Employee (Id_Employee),
+	Id_Accommodation INTEGER NOT NULL REFERENCES Accommodation (Id_Accommodation),
+	PRIMARY KEY (Id_Employee, Id_Accommodation)
+);
+
+CREATE TABLE Employee_Car (
+	Id_Employee INTEGER NOT NULL REFERENCES Employee (Id_Employee),
+	Id_Car INTEGER NOT NULL REFERENCES Car (Id_Car),
+	PRIMARY KEY (Id_Employee, Id_Car)
+);
@@ -0,0 +1 @@
+ALTER TABLE Employee ADD COLUMN Role TEXT;
@@ -0,0 +1,2 @@
+DROP TABLE Revoked_Token;
+DROP TABLE Refresh_Token;
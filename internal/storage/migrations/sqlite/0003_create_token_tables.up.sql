@@ -0,0 +1,17 @@
+CREATE TABLE Refresh_Token (
+	Token_Hash TEXT PRIMARY KEY,
+	Login TEXT NOT NULL,
+	Access_Jti TEXT NOT NULL,
+	Access_Expires_At DATETIME NOT NULL,
+	Expires_At DATETIME NOT NULL,
+	Revoked_At DATETIME
+);
+
+CREATE INDEX IX_Refresh_Token_Login ON Refresh_Token (Login);
+CREATE INDEX IX_Refresh_Token_Access_Jti ON Refresh_Token (Access_Jti);
+
+CREATE TABLE Revoked_Token (
+	Jti TEXT PRIMARY KEY,
+	Expires_At DATETIME NOT NULL,
+	Revoked_At DATETIME NOT NULL
+);
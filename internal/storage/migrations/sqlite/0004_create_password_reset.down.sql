@@ -0,0 +1 @@
+DROP TABLE Password_Reset;
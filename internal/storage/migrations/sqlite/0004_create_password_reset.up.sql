@@ -0,0 +1,6 @@
+CREATE TABLE Password_Reset (
+	Token_Hash TEXT PRIMARY KEY,
+	Login TEXT NOT NULL,
+	Expires_At DATETIME NOT NULL,
+	Used_At DATETIME
+);
@@ -0,0 +1,3 @@
+DROP TABLE Login_Attempt;
+ALTER TABLE Employee DROP COLUMN Locked_Until;
+ALTER TABLE Employee DROP COLUMN Failed_Login_Count;
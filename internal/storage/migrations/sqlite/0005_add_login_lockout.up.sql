@@ -0,0 +1,12 @@
+ALTER TABLE Employee ADD COLUMN Failed_Login_Count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE Employee ADD COLUMN Locked_Until DATETIME;
+
+CREATE TABLE Login_Attempt (
+	Id_Login_Attempt INTEGER PRIMARY KEY,
+	Login TEXT,
+	IP TEXT,
+	User_Agent TEXT,
+	Success INTEGER NOT NULL,
+	Reason TEXT,
+	Attempted_At DATETIME NOT NULL
+);
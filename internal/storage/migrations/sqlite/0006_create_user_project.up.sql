@@ -0,0 +1,5 @@
+CREATE TABLE User_Project (
+	Id_Employee INTEGER NOT NULL REFERENCES Employee (Id_Employee),
+	Id_Project INTEGER NOT NULL REFERENCES Project (Id_Project),
+	PRIMARY KEY (Id_Employee, Id_Project)
+);
@@ -0,0 +1,5 @@
+DROP INDEX IX_Employee_Passport_Number_Index;
+DROP INDEX IX_Employee_Pesel_Index;
+
+ALTER TABLE Employee DROP COLUMN Passport_Number_Index;
+ALTER TABLE Employee DROP COLUMN Pesel_Index;
@@ -0,0 +1,5 @@
+ALTER TABLE Employee ADD COLUMN Pesel_Index TEXT;
+ALTER TABLE Employee ADD COLUMN Passport_Number_Index TEXT;
+
+CREATE INDEX IX_Employee_Pesel_Index ON Employee (Pesel_Index);
+CREATE INDEX IX_Employee_Passport_Number_Index ON Employee (Passport_Number_Index);
@@ -0,0 +1,12 @@
+CREATE TABLE Audit_Log (
+	Id_Audit INTEGER PRIMARY KEY,
+	Username TEXT,
+	Occurred_At DATETIME NOT NULL,
+	Entity_Type TEXT NOT NULL,
+	Entity_Id INTEGER NOT NULL,
+	Action TEXT NOT NULL,
+	Changes TEXT
+);
+
+CREATE INDEX IX_Audit_Log_Entity ON Audit_Log (Entity_Type, Entity_Id);
+CREATE INDEX IX_Audit_Log_Occurred_At ON Audit_Log (Occurred_At);
@@ -0,0 +1,4 @@
+ALTER TABLE Employee DROP COLUMN Version;
+ALTER TABLE Accommodation DROP COLUMN Version;
+ALTER TABLE Car DROP COLUMN Version;
+ALTER TABLE Project DROP COLUMN Version;
@@ -0,0 +1,4 @@
+ALTER TABLE Project ADD COLUMN Version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE Car ADD COLUMN Version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE Accommodation ADD COLUMN Version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE Employee ADD COLUMN Version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,8 @@
+ALTER TABLE Employee DROP COLUMN Archived_By;
+ALTER TABLE Employee DROP COLUMN Archived_At;
+ALTER TABLE Accommodation DROP COLUMN Archived_By;
+ALTER TABLE Accommodation DROP COLUMN Archived_At;
+ALTER TABLE Car DROP COLUMN Archived_By;
+ALTER TABLE Car DROP COLUMN Archived_At;
+ALTER TABLE Project DROP COLUMN Archived_By;
+ALTER TABLE Project DROP COLUMN Archived_At;
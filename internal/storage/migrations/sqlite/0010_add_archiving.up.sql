@@ -0,0 +1,8 @@
+ALTER TABLE Project ADD COLUMN Archived_At DATETIME;
+ALTER TABLE Project ADD COLUMN Archived_By TEXT;
+ALTER TABLE Car ADD COLUMN Archived_At DATETIME;
+ALTER TABLE Car ADD COLUMN Archived_By TEXT;
+ALTER TABLE Accommodation ADD COLUMN Archived_At DATETIME;
+ALTER TABLE Accommodation ADD COLUMN Archived_By TEXT;
+ALTER TABLE Employee ADD COLUMN Archived_At DATETIME;
+ALTER TABLE Employee ADD COLUMN Archived_By TEXT;
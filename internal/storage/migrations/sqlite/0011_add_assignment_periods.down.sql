@@ -0,0 +1,12 @@
+CREATE TABLE Employee_Project_Old (
+	Id_Employee INTEGER NOT NULL REFERENCES Employee (Id_Employee),
+	Id_Project INTEGER NOT NULL REFERENCES Project (Id_Project),
+	PRIMARY KEY (Id_Employee, Id_Project)
+);
+
+INSERT INTO Employee_Project_Old (Id_Employee, Id_Project)
+SELECT Id_Employee, Id_Project FROM Employee_Project WHERE Valid_To IS NULL;
+
+DROP TABLE Employee_Project;
+
+ALTER TABLE Employee_Project_Old RENAME TO Employee_Project;
@@ -0,0 +1,22 @@
+CREATE TABLE Employee_Project_New (
+	Id_Assignment INTEGER PRIMARY KEY,
+	Id_Employee INTEGER NOT NULL REFERENCES Employee (Id_Employee),
+	Id_Project INTEGER NOT NULL REFERENCES Project (Id_Project),
+	Valid_From DATE NOT NULL,
+	Valid_To DATE
+);
+
+INSERT INTO Employee_Project_New (Id_Employee, Id_Project, Valid_From)
+SELECT ep.Id_Employee, ep.Id_Project, COALESCE(
+	(SELECT MIN(Start_Date) FROM Employment WHERE Id_Employee = ep.Id_Employee AND Start_Date > '1900-01-01'),
+	strftime('%Y-%m-%d 00:00:00+00:00', 'now')
+)
+FROM Employee_Project ep;
+
+DROP TABLE Employee_Project;
+
+ALTER TABLE Employee_Project_New RENAME TO Employee_Project;
+
+CREATE UNIQUE INDEX UX_Employee_Project_Current ON Employee_Project (Id_Employee) WHERE Valid_To IS NULL;
+
+CREATE INDEX IX_Employee_Project_Project ON Employee_Project (Id_Project, Valid_From);
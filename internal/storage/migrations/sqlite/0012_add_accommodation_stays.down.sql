@@ -0,0 +1,14 @@
+CREATE TABLE Employee_Accommodation_Old (
+	Id_Employee INTEGER NOT NULL REFERENCES Employee (Id_Employee),
+	Id_Accommodation INTEGER NOT NULL REFERENCES Accommodation (Id_Accommodation),
+	PRIMARY KEY (Id_Employee, Id_Accommodation)
+);
+
+INSERT OR IGNORE INTO Employee_Accommodation_Old (Id_Employee, Id_Accommodation)
+SELECT Id_Employee, Id_Accommodation FROM Employee_Accommodation
+WHERE Check_In <= strftime('%Y-%m-%d 00:00:00+00:00', 'now')
+AND (Check_Out IS NULL OR Check_Out > strftime('%Y-%m-%d 00:00:00+00:00', 'now'));
+
+DROP TABLE Employee_Accommodation;
+
+ALTER TABLE Employee_Accommodation_Old RENAME TO Employee_Accommodation;
@@ -0,0 +1,19 @@
+CREATE TABLE Employee_Accommodation_New (
+	Id_Stay INTEGER PRIMARY KEY,
+	Id_Employee INTEGER NOT NULL REFERENCES Employee (Id_Employee),
+	Id_Accommodation INTEGER NOT NULL REFERENCES Accommodation (Id_Accommodation),
+	Check_In DATE NOT NULL,
+	Check_Out DATE
+);
+
+INSERT INTO Employee_Accommodation_New (Id_Employee, Id_Accommodation, Check_In)
+SELECT Id_Employee, Id_Accommodation, strftime('%Y-%m-%d 00:00:00+00:00', 'now')
+FROM Employee_Accommodation;
+
+DROP TABLE Employee_Accommodation;
+
+ALTER TABLE Employee_Accommodation_New RENAME TO Employee_Accommodation;
+
+CREATE INDEX IX_Employee_Accommodation_Accommodation ON Employee_Accommodation (Id_Accommodation, Check_In);
+
+CREATE INDEX IX_Employee_Accommodation_Employee ON Employee_Accommodation (Id_Employee, Check_In);
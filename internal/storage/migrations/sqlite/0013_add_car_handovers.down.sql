@@ -0,0 +1,14 @@
+DROP TABLE Car_Handover;
+
+CREATE TABLE Employee_Car_Old (
+	Id_Employee INTEGER NOT NULL REFERENCES Employee (Id_Employee),
+	Id_Car INTEGER NOT NULL REFERENCES Car (Id_Car),
+	PRIMARY KEY (Id_Employee, Id_Car)
+);
+
+INSERT OR IGNORE INTO Employee_Car_Old (Id_Employee, Id_Car)
+SELECT Id_Employee, Id_Car FROM Employee_Car WHERE Valid_To IS NULL;
+
+DROP TABLE Employee_Car;
+
+ALTER TABLE Employee_Car_Old RENAME TO Employee_Car;
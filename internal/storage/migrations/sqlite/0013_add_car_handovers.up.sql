@@ -0,0 +1,33 @@
+CREATE TABLE Employee_Car_New (
+	Id_Assignment INTEGER PRIMARY KEY,
+	Id_Employee INTEGER NOT NULL REFERENCES Employee (Id_Employee),
+	Id_Car INTEGER NOT NULL REFERENCES Car (Id_Car),
+	Valid_From DATETIME NOT NULL,
+	Valid_To DATETIME
+);
+
+INSERT INTO Employee_Car_New (Id_Employee, Id_Car, Valid_From)
+SELECT Id_Employee, Id_Car, strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')
+FROM Employee_Car;
+
+DROP TABLE Employee_Car;
+
+ALTER TABLE Employee_Car_New RENAME TO Employee_Car;
+
+CREATE UNIQUE INDEX UX_Employee_Car_Current ON Employee_Car (Id_Employee) WHERE Valid_To IS NULL;
+
+CREATE INDEX IX_Employee_Car_Car ON Employee_Car (Id_Car, Valid_From);
+
+CREATE TABLE Car_Handover (
+	Id_Handover INTEGER PRIMARY KEY,
+	Id_Car INTEGER NOT NULL REFERENCES Car (Id_Car),
+	Handed_At DATETIME NOT NULL,
+	From_Employee INTEGER REFERENCES Employee (Id_Employee),
+	To_Employee INTEGER REFERENCES Employee (Id_Employee),
+	Odometer INTEGER NOT NULL,
+	Fuel_Level INTEGER NOT NULL,
+	Damage_Notes TEXT NOT NULL DEFAULT '',
+	Recorded_By TEXT
+);
+
+CREATE INDEX IX_Car_Handover_Car ON Car_Handover (Id_Car, Handed_At);
@@ -0,0 +1 @@
+ALTER TABLE Employee DROP COLUMN Search_Key;
@@ -0,0 +1 @@
+ALTER TABLE Employee ADD COLUMN Search_Key TEXT;
@@ -0,0 +1 @@
+-- SQLite columns have no width; kept in step with the SQL Server migrations.
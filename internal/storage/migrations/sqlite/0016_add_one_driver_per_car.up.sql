@@ -0,0 +1,14 @@
+-- Employee edits could give a car to a second employee without ending the
+-- period of the first. The latest period stays open and the others end when
+-- it started.
+UPDATE Employee_Car SET Valid_To = (
+	SELECT MAX(o.Valid_From) FROM Employee_Car o
+	WHERE o.Id_Car = Employee_Car.Id_Car AND o.Valid_To IS NULL
+)
+WHERE Valid_To IS NULL AND EXISTS (
+	SELECT 1 FROM Employee_Car o
+	WHERE o.Id_Car = Employee_Car.Id_Car AND o.Valid_To IS NULL
+		AND (o.Valid_From > Employee_Car.Valid_From OR (o.Valid_From = Employee_Car.Valid_From AND o.Id_Assignment > Employee_Car.Id_Assignment))
+);
+
+CREATE UNIQUE INDEX UX_Employee_Car_Driver ON Employee_Car (Id_Car) WHERE Valid_To IS NULL;
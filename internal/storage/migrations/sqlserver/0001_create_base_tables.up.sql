@@ -0,0 +1,156 @@
+-- Tables the application was originally written against. Every statement is
+-- guarded, here and in the following migrations, so that running them
+-- against a database that was set up by hand adopts it.
+
+IF OBJECT_ID(N'Project', N'U') IS NULL
+CREATE TABLE Project (
+	Id_Project INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+	[Name] NVARCHAR(255) NOT NULL,
+	Office_Address NVARCHAR(255) NULL,
+	Project_NIP NVARCHAR(20) NULL
+);
+
+IF OBJECT_ID(N'Contact_Person', N'U') IS NULL
+CREATE TABLE Contact_Person (
+	Id_Contact_Person INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+	Id_Project INT NOT NULL REFERENCES Project (Id_Project),
+	First_Name NVARCHAR(100) NULL,
+	Last_Name NVARCHAR(100) NULL,
+	Phone NVARCHAR(50) NULL,
+	Position NVARCHAR(100) NULL
+);
+
+IF OBJECT_ID(N'Car', N'U') IS NULL
+CREATE TABLE Car (
+	Id_Car INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+	Model NVARCHAR(100) NULL,
+	Color NVARCHAR(50) NULL,
+	Registration_Number NVARCHAR(20) NULL,
+	VIN_Number NVARCHAR(17) NULL CONSTRAINT UQ_Car_VIN_Number UNIQUE,
+	Inspection_From DATE NULL,
+	Inspection_To DATE NULL,
+	Insurance_From DATE NULL,
+	Insurance_To DATE NULL,
+	Fleet_Card_Number NVARCHAR(50) NULL,
+	Id_Project INT NULL REFERENCES Project (Id_Project)
+);
+
+IF OBJECT_ID(N'Service', N'U') IS NULL
+CREATE TABLE [Service] (
+	Id_Service INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+	Id_Car INT NOT NULL REFERENCES Car (Id_Car),
+	Service_Name NVARCHAR(255) NULL,
+	Address NVARCHAR(255) NULL,
+	Phone_Number NVARCHAR(50) NULL
+);
+
+IF OBJECT_ID(N'Leasing', N'U') IS NULL
+CREATE TABLE Leasing (
+	Id_Leasing INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+	Id_Car INT NOT NULL REFERENCES Car (Id_Car),
+	Amount DECIMAL(12, 2) NULL,
+	Monthly_Payment DECIMAL(12, 2) NULL,
+	Payment_Day INT NULL
+);
+
+IF OBJECT_ID(N'Accommodation', N'U') IS NULL
+CREATE TABLE Accommodation (
+	Id_Accommodation INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+	Id_Project INT NULL REFERENCES Project (Id_Project),
+	City NVARCHAR(100) NULL,
+	Accommodation_Address NVARCHAR(255) NULL,
+	Number_Of_Places INT NOT NULL CONSTRAINT DF_Accommodation_Number_Of_Places DEFAULT 0
+);
+
+IF OBJECT_ID(N'Contact', N'U') IS NULL
+CREATE TABLE Contact (
+	Id_Contact INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+	Id_Accommodation INT NOT NULL REFERENCES Accommodation (Id_Accommodation),
+	First_Name NVARCHAR(100) NULL,
+	Last_Name NVARCHAR(100) NULL,
+	Phone_Number NVARCHAR(50) NULL
+);
+
+IF OBJECT_ID(N'Payments', N'U') IS NULL
+CREATE TABLE Payments (
+	Id_Payment INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+	Id_Accommodation INT NOT NULL REFERENCES Accommodation (Id_Accommodation),
+	Cost DECIMAL(12, 2) NULL,
+	Deposit DECIMAL(12, 2) NULL,
+	Contract NVARCHAR(255) NULL,
+	Account_Number NVARCHAR(512) NULL,
+	Payment_Day INT NULL
+);
+
+IF OBJECT_ID(N'Employee', N'U') IS NULL
+CREATE TABLE Employee (
+	Id_Employee INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+	Last_Name NVARCHAR(100) NULL,
+	First_Name NVARCHAR(100) NULL,
+	Passport_Number NVARCHAR(512) NULL,
+	Pesel NVARCHAR(512) NULL,
+	Email NVARCHAR(255) NULL,
+	Date_Of_Birth DATE NULL,
+	Father_Name NVARCHAR(100) NULL,
+	Mother_Name NVARCHAR(100) NULL,
+	Maiden_Name NVARCHAR(100) NULL,
+	Mother_Maiden_Name NVARCHAR(100) NULL,
+	Bank_Account NVARCHAR(512) NULL,
+	Address_Poland NVARCHAR(255) NULL,
+	Home_Address NVARCHAR(255) NULL,
+	Login VARCHAR(100) NULL,
+	Password NVARCHAR(255) NULL
+);
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'UX_Employee_Login')
+CREATE UNIQUE INDEX UX_Employee_Login ON Employee (Login) WHERE Login IS NOT NULL;
+
+IF OBJECT_ID(N'Residence_Card', N'U') IS NULL
+CREATE TABLE Residence_Card (
+	Id_Residence_Card INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+	Employee_Id INT NOT NULL REFERENCES Employee (Id_Employee),
+	Bio DATE NULL,
+	Visa DATE NULL,
+	Tcard DATE NULL
+);
+
+IF OBJECT_ID(N'Medicals', N'U') IS NULL
+CREATE TABLE Medicals (
+	Id_Medicals INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+	Id_Employee INT NOT NULL REFERENCES Employee (Id_Employee),
+	OSH_Valid_Until DATE NULL,
+	Psychotests_Valid_Until DATE NULL,
+	Medical_Valid_Until DATE NULL,
+	Sanitary_Valid_Until DATE NULL
+);
+
+IF OBJECT_ID(N'Employment', N'U') IS NULL
+CREATE TABLE Employment (
+	Id_Employment INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+	Id_Employee INT NOT NULL REFERENCES Employee (Id_Employee),
+	Contract_Type NVARCHAR(100) NULL,
+	Start_Date DATE NULL,
+	End_Date DATE NULL,
+	Authorizations NVARCHAR(255) NULL
+);
+
+IF OBJECT_ID(N'Employee_Project', N'U') IS NULL
+CREATE TABLE Employee_Project (
+	Id_Employee INT NOT NULL REFERENCES Employee (Id_Employee),
+	Id_Project INT NOT NULL REFERENCES Project (Id_Project),
+	PRIMARY KEY (Id_Employee, Id_Project)
+);
+
+IF OBJECT_ID(N'Employee_Accommodation', N'U') IS NULL
+CREATE TABLE Employee_Accommodation (
+	Id_Employee INT NOT NULL REFERENCES Employee (Id_Employee),
+	Id_Accommodation INT NOT NULL REFERENCES Accommodation (Id_Accommodation),
+	PRIMARY KEY (Id_Employee, Id_Accommodation)
+);
+
+IF OBJECT_ID(N'Employee_Car', N'U') IS NULL
+CREATE TABLE Employee_Car (
+	Id_Employee INT NOT NULL REFERENCES Employee (Id_Employee),
+	Id_Car INT NOT NULL REFERENCES Car (Id_Car),
+	PRIMARY KEY (Id_Employee, Id_Car)
+);
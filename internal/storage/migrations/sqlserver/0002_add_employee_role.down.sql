@@ -0,0 +1 @@
+ALTER TABLE Employee DROP COLUMN Role;
@@ -0,0 +1,2 @@
+IF COL_LENGTH(N'Employee', N'Role') IS NULL
+ALTER TABLE Employee ADD Role NVARCHAR(20) NULL;
@@ -0,0 +1,22 @@
+IF OBJECT_ID(N'Refresh_Token', N'U') IS NULL
+CREATE TABLE Refresh_Token (
+	Token_Hash VARCHAR(64) NOT NULL PRIMARY KEY,
+	Login VARCHAR(100) NOT NULL,
+	Access_Jti VARCHAR(36) NOT NULL,
+	Access_Expires_At DATETIME2 NOT NULL,
+	Expires_At DATETIME2 NOT NULL,
+	Revoked_At DATETIME2 NULL
+);
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'IX_Refresh_Token_Login')
+CREATE INDEX IX_Refresh_Token_Login ON Refresh_Token (Login);
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'IX_Refresh_Token_Access_Jti')
+CREATE INDEX IX_Refresh_Token_Access_Jti ON Refresh_Token (Access_Jti);
+
+IF OBJECT_ID(N'Revoked_Token', N'U') IS NULL
+CREATE TABLE Revoked_Token (
+	Jti VARCHAR(36) NOT NULL PRIMARY KEY,
+	Expires_At DATETIME2 NOT NULL,
+	Revoked_At DATETIME2 NOT NULL
+);
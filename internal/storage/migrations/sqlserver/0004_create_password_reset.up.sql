@@ -0,0 +1,7 @@
+IF OBJECT_ID(N'Password_Reset', N'U') IS NULL
+CREATE TABLE Password_Reset (
+	Token_Hash VARCHAR(64) NOT NULL PRIMARY KEY,
+	Login VARCHAR(100) NOT NULL,
+	Expires_At DATETIME2 NOT NULL,
+	Used_At DATETIME2 NULL
+);
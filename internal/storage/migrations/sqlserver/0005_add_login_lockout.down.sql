@@ -0,0 +1,4 @@
+DROP TABLE Login_Attempt;
+ALTER TABLE Employee DROP COLUMN Locked_Until;
+ALTER TABLE Employee DROP CONSTRAINT DF_Employee_Failed_Login_Count;
+ALTER TABLE Employee DROP COLUMN Failed_Login_Count;
@@ -0,0 +1,16 @@
+IF COL_LENGTH(N'Employee', N'Failed_Login_Count') IS NULL
+ALTER TABLE Employee ADD Failed_Login_Count INT NOT NULL CONSTRAINT DF_Employee_Failed_Login_Count DEFAULT 0;
+
+IF COL_LENGTH(N'Employee', N'Locked_Until') IS NULL
+ALTER TABLE Employee ADD Locked_Until DATETIME2 NULL;
+
+IF OBJECT_ID(N'Login_Attempt', N'U') IS NULL
+CREATE TABLE Login_Attempt (
+	Id_Login_Attempt INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+	Login VARCHAR(100) NULL,
+	IP VARCHAR(45) NULL,
+	User_Agent NVARCHAR(512) NULL,
+	Success BIT NOT NULL,
+	Reason NVARCHAR(50) NULL,
+	Attempted_At DATETIME2 NOT NULL
+);
@@ -0,0 +1 @@
+DROP TABLE User_Project;
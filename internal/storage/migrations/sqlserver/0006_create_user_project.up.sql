@@ -0,0 +1,6 @@
+IF OBJECT_ID(N'User_Project', N'U') IS NULL
+CREATE TABLE User_Project (
+	Id_Employee INT NOT NULL REFERENCES Employee (Id_Employee),
+	Id_Project INT NOT NULL REFERENCES Project (Id_Project),
+	PRIMARY KEY (Id_Employee, Id_Project)
+);
@@ -0,0 +1,4 @@
+DROP INDEX IX_Employee_Passport_Number_Index ON Employee;
+DROP INDEX IX_Employee_Pesel_Index ON Employee;
+
+ALTER TABLE Employee DROP COLUMN Passport_Number_Index, Pesel_Index;
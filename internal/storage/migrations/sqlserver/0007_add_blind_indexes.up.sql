@@ -0,0 +1,13 @@
+IF COL_LENGTH(N'Employee', N'Pesel_Index') IS NULL
+ALTER TABLE Employee ADD Pesel_Index VARCHAR(64) NULL;
+
+IF COL_LENGTH(N'Employee', N'Passport_Number_Index') IS NULL
+ALTER TABLE Employee ADD Passport_Number_Index VARCHAR(64) NULL;
+
+GO
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'IX_Employee_Pesel_Index')
+CREATE INDEX IX_Employee_Pesel_Index ON Employee (Pesel_Index);
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'IX_Employee_Passport_Number_Index')
+CREATE INDEX IX_Employee_Passport_Number_Index ON Employee (Passport_Number_Index);
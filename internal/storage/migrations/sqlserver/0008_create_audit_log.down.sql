@@ -0,0 +1 @@
+DROP TABLE Audit_Log;
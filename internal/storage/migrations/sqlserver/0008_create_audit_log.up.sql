@@ -0,0 +1,16 @@
+IF OBJECT_ID(N'Audit_Log', N'U') IS NULL
+CREATE TABLE Audit_Log (
+	Id_Audit BIGINT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+	Username VARCHAR(100) NULL,
+	Occurred_At DATETIME2 NOT NULL,
+	Entity_Type VARCHAR(50) NOT NULL,
+	Entity_Id INT NOT NULL,
+	Action VARCHAR(20) NOT NULL,
+	Changes NVARCHAR(MAX) NULL
+);
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'IX_Audit_Log_Entity')
+CREATE INDEX IX_Audit_Log_Entity ON Audit_Log (Entity_Type, Entity_Id);
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'IX_Audit_Log_Occurred_At')
+CREATE INDEX IX_Audit_Log_Occurred_At ON Audit_Log (Occurred_At);
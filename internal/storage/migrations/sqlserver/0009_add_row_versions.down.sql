@@ -0,0 +1,11 @@
+ALTER TABLE Employee DROP CONSTRAINT DF_Employee_Version;
+ALTER TABLE Employee DROP COLUMN Version;
+
+ALTER TABLE Accommodation DROP CONSTRAINT DF_Accommodation_Version;
+ALTER TABLE Accommodation DROP COLUMN Version;
+
+ALTER TABLE Car DROP CONSTRAINT DF_Car_Version;
+ALTER TABLE Car DROP COLUMN Version;
+
+ALTER TABLE Project DROP CONSTRAINT DF_Project_Version;
+ALTER TABLE Project DROP COLUMN Version;
@@ -0,0 +1,11 @@
+IF COL_LENGTH(N'Project', N'Version') IS NULL
+ALTER TABLE Project ADD Version INT NOT NULL CONSTRAINT DF_Project_Version DEFAULT 1;
+
+IF COL_LENGTH(N'Car', N'Version') IS NULL
+ALTER TABLE Car ADD Version INT NOT NULL CONSTRAINT DF_Car_Version DEFAULT 1;
+
+IF COL_LENGTH(N'Accommodation', N'Version') IS NULL
+ALTER TABLE Accommodation ADD Version INT NOT NULL CONSTRAINT DF_Accommodation_Version DEFAULT 1;
+
+IF COL_LENGTH(N'Employee', N'Version') IS NULL
+ALTER TABLE Employee ADD Version INT NOT NULL CONSTRAINT DF_Employee_Version DEFAULT 1;
@@ -0,0 +1,4 @@
+ALTER TABLE Employee DROP COLUMN Archived_At, Archived_By;
+ALTER TABLE Accommodation DROP COLUMN Archived_At, Archived_By;
+ALTER TABLE Car DROP COLUMN Archived_At, Archived_By;
+ALTER TABLE Project DROP COLUMN Archived_At, Archived_By;
@@ -0,0 +1,11 @@
+IF COL_LENGTH(N'Project', N'Archived_At') IS NULL
+ALTER TABLE Project ADD Archived_At DATETIME2 NULL, Archived_By VARCHAR(100) NULL;
+
+IF COL_LENGTH(N'Car', N'Archived_At') IS NULL
+ALTER TABLE Car ADD Archived_At DATETIME2 NULL, Archived_By VARCHAR(100) NULL;
+
+IF COL_LENGTH(N'Accommodation', N'Archived_At') IS NULL
+ALTER TABLE Accommodation ADD Archived_At DATETIME2 NULL, Archived_By VARCHAR(100) NULL;
+
+IF COL_LENGTH(N'Employee', N'Archived_At') IS NULL
+ALTER TABLE Employee ADD Archived_At DATETIME2 NULL, Archived_By VARCHAR(100) NULL;
@@ -0,0 +1,8 @@
+DROP INDEX IX_Employee_Project_Project ON Employee_Project;
+DROP INDEX UX_Employee_Project_Current ON Employee_Project;
+ALTER TABLE Employee_Project DROP CONSTRAINT PK_Employee_Project;
+
+DELETE FROM Employee_Project WHERE Valid_To IS NOT NULL;
+
+ALTER TABLE Employee_Project DROP COLUMN Id_Assignment, Valid_From, Valid_To;
+ALTER TABLE Employee_Project ADD PRIMARY KEY (Id_Employee, Id_Project);
@@ -0,0 +1,32 @@
+IF COL_LENGTH(N'Employee_Project', N'Valid_From') IS NULL
+ALTER TABLE Employee_Project ADD Id_Assignment INT IDENTITY(1, 1) NOT NULL, Valid_From DATE NULL, Valid_To DATE NULL;
+
+GO
+
+UPDATE ep SET Valid_From = COALESCE(
+	(SELECT MIN(Start_Date) FROM Employment WHERE Id_Employee = ep.Id_Employee AND Start_Date > '1900-01-01'),
+	CAST(GETDATE() AS DATE)
+)
+FROM Employee_Project ep
+WHERE ep.Valid_From IS NULL;
+
+ALTER TABLE Employee_Project ALTER COLUMN Valid_From DATE NOT NULL;
+
+DECLARE @pk SYSNAME = (
+	SELECT name FROM sys.key_constraints
+	WHERE parent_object_id = OBJECT_ID(N'Employee_Project') AND type = 'PK' AND name <> N'PK_Employee_Project'
+);
+
+IF @pk IS NOT NULL
+EXEC (N'ALTER TABLE Employee_Project DROP CONSTRAINT ' + @pk);
+
+GO
+
+IF NOT EXISTS (SELECT 1 FROM sys.key_constraints WHERE name = N'PK_Employee_Project')
+ALTER TABLE Employee_Project ADD CONSTRAINT PK_Employee_Project PRIMARY KEY (Id_Assignment);
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'UX_Employee_Project_Current')
+CREATE UNIQUE INDEX UX_Employee_Project_Current ON Employee_Project (Id_Employee) WHERE Valid_To IS NULL;
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'IX_Employee_Project_Project')
+CREATE INDEX IX_Employee_Project_Project ON Employee_Project (Id_Project, Valid_From);
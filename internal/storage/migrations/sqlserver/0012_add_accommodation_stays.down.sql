@@ -0,0 +1,9 @@
+DROP INDEX IX_Employee_Accommodation_Employee ON Employee_Accommodation;
+DROP INDEX IX_Employee_Accommodation_Accommodation ON Employee_Accommodation;
+ALTER TABLE Employee_Accommodation DROP CONSTRAINT PK_Employee_Accommodation;
+
+DELETE FROM Employee_Accommodation
+WHERE Check_In > CAST(GETDATE() AS DATE) OR Check_Out <= CAST(GETDATE() AS DATE);
+
+ALTER TABLE Employee_Accommodation DROP COLUMN Id_Stay, Check_In, Check_Out;
+ALTER TABLE Employee_Accommodation ADD PRIMARY KEY (Id_Employee, Id_Accommodation);
@@ -0,0 +1,27 @@
+IF COL_LENGTH(N'Employee_Accommodation', N'Check_In') IS NULL
+ALTER TABLE Employee_Accommodation ADD Id_Stay INT IDENTITY(1, 1) NOT NULL, Check_In DATE NULL, Check_Out DATE NULL;
+
+GO
+
+UPDATE Employee_Accommodation SET Check_In = CAST(GETDATE() AS DATE) WHERE Check_In IS NULL;
+
+ALTER TABLE Employee_Accommodation ALTER COLUMN Check_In DATE NOT NULL;
+
+DECLARE @pk SYSNAME = (
+	SELECT name FROM sys.key_constraints
+	WHERE parent_object_id = OBJECT_ID(N'Employee_Accommodation') AND type = 'PK' AND name <> N'PK_Employee_Accommodation'
+);
+
+IF @pk IS NOT NULL
+EXEC (N'ALTER TABLE Employee_Accommodation DROP CONSTRAINT ' + @pk);
+
+GO
+
+IF NOT EXISTS (SELECT 1 FROM sys.key_constraints WHERE name = N'PK_Employee_Accommodation')
+ALTER TABLE Employee_Accommodation ADD CONSTRAINT PK_Employee_Accommodation PRIMARY KEY (Id_Stay);
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'IX_Employee_Accommodation_Accommodation')
+CREATE INDEX IX_Employee_Accommodation_Accommodation ON Employee_Accommodation (Id_Accommodation, Check_In);
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'IX_Employee_Accommodation_Employee')
+CREATE INDEX IX_Employee_Accommodation_Employee ON Employee_Accommodation (Id_Employee, Check_In);
@@ -0,0 +1,10 @@
+DROP TABLE Car_Handover;
+
+DROP INDEX IX_Employee_Car_Car ON Employee_Car;
+DROP INDEX UX_Employee_Car_Current ON Employee_Car;
+ALTER TABLE Employee_Car DROP CONSTRAINT PK_Employee_Car;
+
+DELETE FROM Employee_Car WHERE Valid_To IS NOT NULL;
+
+ALTER TABLE Employee_Car DROP COLUMN Id_Assignment, Valid_From, Valid_To;
+ALTER TABLE Employee_Car ADD PRIMARY KEY (Id_Employee, Id_Car);
@@ -0,0 +1,43 @@
+IF COL_LENGTH(N'Employee_Car', N'Valid_From') IS NULL
+ALTER TABLE Employee_Car ADD Id_Assignment INT IDENTITY(1, 1) NOT NULL, Valid_From DATETIME2 NULL, Valid_To DATETIME2 NULL;
+
+GO
+
+UPDATE Employee_Car SET Valid_From = SYSUTCDATETIME() WHERE Valid_From IS NULL;
+
+ALTER TABLE Employee_Car ALTER COLUMN Valid_From DATETIME2 NOT NULL;
+
+DECLARE @pk SYSNAME = (
+	SELECT name FROM sys.key_constraints
+	WHERE parent_object_id = OBJECT_ID(N'Employee_Car') AND type = 'PK' AND name <> N'PK_Employee_Car'
+);
+
+IF @pk IS NOT NULL
+EXEC (N'ALTER TABLE Employee_Car DROP CONSTRAINT ' + @pk);
+
+GO
+
+IF NOT EXISTS (SELECT 1 FROM sys.key_constraints WHERE name = N'PK_Employee_Car')
+ALTER TABLE Employee_Car ADD CONSTRAINT PK_Employee_Car PRIMARY KEY (Id_Assignment);
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'UX_Employee_Car_Current')
+CREATE UNIQUE INDEX UX_Employee_Car_Current ON Employee_Car (Id_Employee) WHERE Valid_To IS NULL;
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'IX_Employee_Car_Car')
+CREATE INDEX IX_Employee_Car_Car ON Employee_Car (Id_Car, Valid_From);
+
+IF OBJECT_ID(N'Car_Handover', N'U') IS NULL
+CREATE TABLE Car_Handover (
+	Id_Handover INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+	Id_Car INT NOT NULL REFERENCES Car (Id_Car),
+	Handed_At DATETIME2 NOT NULL,
+	From_Employee INT NULL REFERENCES Employee (Id_Employee),
+	To_Employee INT NULL REFERENCES Employee (Id_Employee),
+	Odometer INT NOT NULL,
+	Fuel_Level INT NOT NULL,
+	Damage_Notes NVARCHAR(MAX) NOT NULL DEFAULT N'',
+	Recorded_By VARCHAR(100) NULL
+);
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'IX_Car_Handover_Car')
+CREATE INDEX IX_Car_Handover_Car ON Car_Handover (Id_Car, Handed_At);
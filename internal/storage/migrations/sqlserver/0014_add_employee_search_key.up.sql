@@ -0,0 +1,2 @@
+IF COL_LENGTH(N'Employee', N'Search_Key') IS NULL
+ALTER TABLE Employee ADD Search_Key NVARCHAR(1000) NULL;
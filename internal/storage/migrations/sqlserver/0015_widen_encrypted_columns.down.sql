@@ -0,0 +1 @@
+-- The columns are left wide, narrowing them would truncate encrypted values.
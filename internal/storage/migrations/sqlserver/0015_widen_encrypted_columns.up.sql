@@ -0,0 +1,13 @@
+-- 0001 does not alter tables that already existed, which may have kept
+-- columns too narrow for the values encrypted since 0007.
+IF COL_LENGTH(N'Employee', N'Pesel') BETWEEN 1 AND 1023
+ALTER TABLE Employee ALTER COLUMN Pesel NVARCHAR(512) NULL;
+
+IF COL_LENGTH(N'Employee', N'Passport_Number') BETWEEN 1 AND 1023
+ALTER TABLE Employee ALTER COLUMN Passport_Number NVARCHAR(512) NULL;
+
+IF COL_LENGTH(N'Employee', N'Bank_Account') BETWEEN 1 AND 1023
+ALTER TABLE Employee ALTER COLUMN Bank_Account NVARCHAR(512) NULL;
+
+IF COL_LENGTH(N'Payments', N'Account_Number') BETWEEN 1 AND 1023
+ALTER TABLE Payments ALTER COLUMN Account_Number NVARCHAR(512) NULL;
@@ -0,0 +1 @@
+DROP INDEX UX_Employee_Car_Driver ON Employee_Car;
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// column is a column an update may write and the value it writes. Columns
+// are only written if set, so that a patch leaves the others alone.
+type column struct {
+	name  string
+	value any
+	set   bool
+}
+
+// updateColumns writes the columns that are set to the row of table whose
+// key column holds id. It does nothing if no column is set.
+func (s *Service) updateColumns(ctx context.Context, table, key string, id int, columns []column) error {
+	var (
+		assignments []string
+		args        []any
+	)
+
+	for _, c := range columns {
+		if c.set {
+			args = append(args, c.value)
+			assignments = append(assignments, fmt.Sprintf("%s = @p%d", c.name, len(args)))
+		}
+	}
+
+	if len(assignments) == 0 {
+		return nil
+	}
+
+	args = append(args, id)
+
+	sql := fmt.Sprintf("UPDATE %s SET %s WHERE %s = @p%d;", table, strings.Join(assignments, ", "), key, len(args))
+
+	_, err := s.conn(ctx).ExecContext(ctx, sql, args...)
+
+	return errors.Wrapf(err, "failed to update %s", table)
+}
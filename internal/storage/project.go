@@ -2,17 +2,39 @@ package storage
 
 import (
 	"context"
+	"time"
 
+	"api/internal/auth"
 	"api/internal/models"
+	mssql "github.com/microsoft/go-mssqldb"
 	"github.com/pkg/errors"
 )
 
-func (s *Service) Projects(ctx context.Context) ([]models.Project, error) {
-	sql := "SELECT p.Id_Project AS ProjectId, p.Name AS ProjectName, p.Office_Address AS ProjectAddress, p.Project_NIP AS ProjectNIP, COALESCE(emp_data.EmployeeCount, 0) AS EmployeeCount, COALESCE(acc_data.FreeAccommodationPlaces, 0) AS FreeAccommodationPlaces, COALESCE(car_data.CarCount, 0) AS CarCount FROM Project p LEFT JOIN (SELECT ep.Id_Project, COUNT(DISTINCT ep.Id_Employee) AS EmployeeCount FROM Employee_Project ep GROUP BY ep.Id_Project) emp_data ON p.Id_Project = emp_data.Id_Project LEFT JOIN (SELECT a.Id_Project, SUM(a.Number_Of_Places - COALESCE(assigned.CountAssignedEmployees, 0)) AS FreeAccommodationPlaces FROM Accommodation a LEFT JOIN (SELECT ea.Id_Accommodation, COUNT(ea.Id_Employee) AS CountAssignedEmployees FROM Employee_Accommodation ea GROUP BY ea.Id_Accommodation) assigned ON a.Id_Accommodation = assigned.Id_Accommodation GROUP BY a.Id_Project) acc_data ON p.Id_Project = acc_data.Id_Project LEFT JOIN (SELECT c.Id_Project, COUNT(DISTINCT c.Id_Car) AS CarCount FROM Car c GROUP BY c.Id_Project) car_data ON p.Id_Project = car_data.Id_Project;"
+// Projects returns the filter's page of the project list together with the
+// number of projects on all pages.
+func (s *Service) Projects(ctx context.Context, filter models.ProjectFilter) ([]models.Project, int, error) {
+	orderBy, err := projectSort.orderBy(filter.Sort)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	countScope, countArgs := projectFilter(ctx, "p.Id_Project", nil)
+
+	total, err := s.count(ctx, "FROM Project p WHERE "+archivedFilter("p", filter.ListFilter)+" AND "+countScope, countArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	assigned, args := assignedOn("ep", "e", filter.AsOf, nil)
+	occupancy, args := occupancyOn(filter.AsOf, args)
+	scope, args := projectFilter(ctx, "p.Id_Project", args)
+	pageClause, args := page(filter.ListFilter, args)
+
+	sql := "SELECT p.Id_Project AS ProjectId, p.Name AS ProjectName, p.Office_Address AS ProjectAddress, p.Project_NIP AS ProjectNIP, COALESCE(emp_data.EmployeeCount, 0) AS EmployeeCount, COALESCE(acc_data.FreeAccommodationPlaces, 0) AS FreeAccommodationPlaces, COALESCE(car_data.CarCount, 0) AS CarCount, p.Archived_At, COALESCE(p.Archived_By, '') FROM Project p LEFT JOIN (SELECT ep.Id_Project, COUNT(DISTINCT ep.Id_Employee) AS EmployeeCount FROM Employee_Project ep JOIN Employee e ON ep.Id_Employee = e.Id_Employee WHERE " + assigned + " GROUP BY ep.Id_Project) emp_data ON p.Id_Project = emp_data.Id_Project LEFT JOIN (SELECT a.Id_Project, SUM(a.Number_Of_Places - COALESCE(assigned.OccupiedPlaces, 0)) AS FreeAccommodationPlaces FROM Accommodation a LEFT JOIN " + occupancy + " assigned ON a.Id_Accommodation = assigned.Id_Accommodation WHERE a.Archived_At IS NULL GROUP BY a.Id_Project) acc_data ON p.Id_Project = acc_data.Id_Project LEFT JOIN (SELECT c.Id_Project, COUNT(DISTINCT c.Id_Car) AS CarCount FROM Car c WHERE c.Archived_At IS NULL GROUP BY c.Id_Project) car_data ON p.Id_Project = car_data.Id_Project WHERE " + archivedFilter("p", filter.ListFilter) + " AND " + scope + " " + orderBy + pageClause + ";"
 
-	rows, err := s.DB.QueryContext(ctx, sql)
+	rows, err := s.conn(ctx).QueryContext(ctx, sql, args...)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to query for projects")
+		return nil, 0, errors.Wrap(err, "failed to query for projects")
 	}
 	defer rows.Close()
 
@@ -20,9 +42,9 @@ func (s *Service) Projects(ctx context.Context) ([]models.Project, error) {
 
 	for rows.Next() {
 		var project models.Project
-		err = rows.Scan(&project.ID, &project.Name, &project.OfficeAddress, &project.ProjectNIP, &project.EmployeeAmount, &project.FreePlaces, &project.AmountCars)
+		err = rows.Scan(&project.ID, &project.Name, &project.OfficeAddress, &project.ProjectNIP, &project.EmployeeAmount, &project.FreePlaces, &project.AmountCars, &project.ArchivedAt, &project.ArchivedBy)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to scan row")
+			return nil, 0, errors.Wrap(err, "failed to scan row")
 		}
 
 		results = append(results, project)
@@ -30,32 +52,47 @@ func (s *Service) Projects(ctx context.Context) ([]models.Project, error) {
 
 	err = rows.Err()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to iterate rows")
+		return nil, 0, errors.Wrap(err, "failed to iterate rows")
 	}
 
-	return results, nil
+	return results, total, nil
 }
 
 func (s *Service) GetProject(ctx context.Context, id int) (models.Project, error) {
-	sql := "select p.Id_Project, p.name, p.office_address, p.project_NIP, c.First_name, c.Last_name, c.Phone, c.Position from project p left join Contact_Person c on p.Id_Project = c.Id_Project where p.id_project = @p1"
+	scope, args := projectFilter(ctx, "p.Id_Project", []any{id})
+
+	sql := "select p.Id_Project, p.name, p.office_address, p.project_NIP, c.First_name, c.Last_name, c.Phone, c.Position, p.Version, p.Archived_At, COALESCE(p.Archived_By, '') from project p left join Contact_Person c on p.Id_Project = c.Id_Project where p.id_project = @p1 and " + scope
 
 	var project models.Project
 
-	err := s.DB.QueryRowContext(ctx, sql, id).Scan(&project.ID, &project.Name, &project.OfficeAddress, &project.ProjectNIP, &project.FirstName, &project.LastName, &project.Phone, &project.Position)
+	err := s.conn(ctx).QueryRowContext(ctx, sql, args...).Scan(&project.ID, &project.Name, &project.OfficeAddress, &project.ProjectNIP, &project.FirstName, &project.LastName, &project.Phone, &project.Position, &project.Version, &project.ArchivedAt, &project.ArchivedBy)
 
 	return project, errors.Wrap(err, "failed to retrieve project")
 }
 
 func (s *Service) AddProject(ctx context.Context, newProject models.NewProject) (id int, err error) {
+	err = s.InTx(ctx, func(ctx context.Context) error {
+		id, err = s.addProject(ctx, newProject)
+		return err
+	})
+
+	return id, err
+}
+
+func (s *Service) addProject(ctx context.Context, newProject models.NewProject) (id int, err error) {
+	if _, restricted := auth.ProjectScope(ctx); restricted {
+		return 0, ErrForbidden
+	}
+
 	sql := "INSERT INTO Project (Name, Office_Address, Project_NIP) VALUES (@p1,@p2,@p3); SELECT SCOPE_IDENTITY() AS Id_Project;"
 
-	err = s.DB.QueryRowContext(ctx, sql, newProject.Name, newProject.OfficeAddress, newProject.ProjectNIP).Scan(&id)
+	err = s.conn(ctx).QueryRowContext(ctx, sql, newProject.Name, newProject.OfficeAddress, newProject.ProjectNIP).Scan(&id)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to add project")
 	}
 
 	sql = "INSERT INTO Contact_Person (Id_Project, First_Name, Last_Name, Phone, Position) VALUES (@p1,@p2,@p3,@p4,@p5);"
-	_, err = s.DB.ExecContext(ctx, sql, id, newProject.FirstName, newProject.LastName, newProject.Phone, newProject.Position)
+	_, err = s.conn(ctx).ExecContext(ctx, sql, id, newProject.FirstName, newProject.LastName, newProject.Phone, newProject.Position)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to add contact person")
 	}
@@ -63,37 +100,125 @@ func (s *Service) AddProject(ctx context.Context, newProject models.NewProject)
 	return id, nil
 }
 
-func (s *Service) RemoveProject(ctx context.Context, id int) error {
+// ArchiveProject archives the project together with its cars and
+// accommodations, and unassigns the employees using them.
+func (s *Service) ArchiveProject(ctx context.Context, id, version int) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		return s.archiveProject(ctx, id, version)
+	})
+}
+
+func (s *Service) archiveProject(ctx context.Context, id, version int) error {
+	if err := s.projectVisible(ctx, id); err != nil {
+		return errors.Wrap(err, "failed to retrieve project")
+	}
+
+	if err := s.bumpVersion(ctx, "Project", "Id_Project", id, version); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	if err := s.archive(ctx, "Project", "Id_Project", id, now); err != nil {
+		return err
+	}
+
+	err := s.vacate(ctx, "Id_Accommodation IN (SELECT Id_Accommodation FROM Accommodation WHERE Id_Project = @p1 AND Archived_At IS NULL)", id)
+	if err != nil {
+		return err
+	}
+
+	err = s.returnCars(ctx, now, "Id_Car IN (SELECT Id_Car FROM Car WHERE Id_Project = @p1 AND Archived_At IS NULL)", id)
+	if err != nil {
+		return err
+	}
+
+	sql := "UPDATE Accommodation SET Archived_At = @p2, Archived_By = @p3, Version = Version + 1 WHERE Id_Project = @p1 AND Archived_At IS NULL;" +
+		"UPDATE Car SET Archived_At = @p2, Archived_By = @p3, Version = Version + 1 WHERE Id_Project = @p1 AND Archived_At IS NULL;"
+
+	_, err = s.conn(ctx).ExecContext(ctx, sql, id, mssql.DateTime1(now), archivist(ctx))
+
+	return errors.Wrap(err, "failed to archive project")
+}
+
+// RestoreProject brings back an archived project together with the cars and
+// accommodations that were archived with it.
+func (s *Service) RestoreProject(ctx context.Context, id, version int) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		return s.restoreProject(ctx, id, version)
+	})
+}
 
-	sql := "DELETE FROM Payments WHERE Id_Accommodation IN (SELECT Id_Accommodation FROM Accommodation WHERE Id_Project = @p1);" +
-		"DELETE FROM Contact WHERE Id_Accommodation IN (SELECT Id_Accommodation FROM Accommodation WHERE Id_Project = @p1);" +
-		"DELETE FROM Employee_Accommodation WHERE Id_Accommodation IN (SELECT Id_Accommodation FROM Accommodation WHERE Id_Project = @p1)" +
-		"DELETE FROM Accommodation WHERE Id_Project = @p1;" +
-		"DELETE FROM Leasing WHERE Id_Car IN (SELECT Id_Car FROM Car WHERE Id_Project = @p1);" +
-		"DELETE FROM Service WHERE Id_Car IN (SELECT Id_Car FROM Car WHERE Id_Project = @p1);" +
-		"DELETE FROM Employee_Car WHERE Id_Car IN (SELECT Id_Car FROM Car WHERE Id_Project = @p1);" +
-		"DELETE FROM Car WHERE Id_Project = @p1;" +
-		"DELETE FROM Employee_Project WHERE Id_Project = @p1;" +
-		"DELETE FROM Contact_Person WHERE Id_Project = @p1;" +
-		"DELETE FROM Project WHERE Id_Project = @p1;"
+func (s *Service) restoreProject(ctx context.Context, id, version int) error {
+	if err := s.projectVisible(ctx, id); err != nil {
+		return errors.Wrap(err, "failed to retrieve project")
+	}
 
-	_, err := s.DB.ExecContext(ctx, sql, id)
+	if err := s.bumpVersion(ctx, "Project", "Id_Project", id, version); err != nil {
+		return err
+	}
+
+	sql := "UPDATE Accommodation SET Archived_At = NULL, Archived_By = NULL, Version = Version + 1 WHERE Id_Project = @p1 AND Archived_At = (SELECT Archived_At FROM Project WHERE Id_Project = @p1);" +
+		"UPDATE Car SET Archived_At = NULL, Archived_By = NULL, Version = Version + 1 WHERE Id_Project = @p1 AND Archived_At = (SELECT Archived_At FROM Project WHERE Id_Project = @p1);"
 
-	return errors.Wrap(err, "failed to remove project")
+	_, err := s.conn(ctx).ExecContext(ctx, sql, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to restore project")
+	}
+
+	return s.restore(ctx, "Project", "Id_Project", id)
 }
 
-func (s *Service) UpdateProject(ctx context.Context, id int, updateProject models.UpdateProject) error {
-	sql := "UPDATE Project SET [Name] = @p1, Office_Address = @p2, Project_NIP = @p3 WHERE Id_Project = @p8; UPDATE Contact_Person SET First_Name = @p4, Last_Name = @p5, Phone = @p6, Position = @p7 WHERE Id_Project = @p8;"
+func (s *Service) UpdateProject(ctx context.Context, id, version int, updateProject models.UpdateProject) error {
+	return s.PatchProject(ctx, id, version, updateProject, nil)
+}
 
-	_, err := s.DB.ExecContext(ctx, sql, updateProject.Name, updateProject.OfficeAddress, updateProject.ProjectNIP, updateProject.FirstName, updateProject.LastName, updateProject.Phone, updateProject.Position, id)
+// PatchProject writes the given fields of the update to the project and its
+// contact person, leaving the other columns as they are.
+func (s *Service) PatchProject(ctx context.Context, id, version int, updateProject models.UpdateProject, fields models.Fields) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		return s.updateProject(ctx, id, version, updateProject, fields)
+	})
+}
+
+func (s *Service) updateProject(ctx context.Context, id, version int, updateProject models.UpdateProject, fields models.Fields) error {
+	if err := s.projectVisible(ctx, id); err != nil {
+		return errors.Wrap(err, "failed to retrieve project")
+	}
+
+	if err := s.checkActive(ctx, "Project", "Id_Project", id); err != nil {
+		return err
+	}
+
+	if err := s.bumpVersion(ctx, "Project", "Id_Project", id, version); err != nil {
+		return err
+	}
+
+	err := s.updateColumns(ctx, "Project", "Id_Project", id, []column{
+		{"[Name]", updateProject.Name, fields.Has("Name")},
+		{"Office_Address", updateProject.OfficeAddress, fields.Has("OfficeAddress")},
+		{"Project_NIP", updateProject.ProjectNIP, fields.Has("ProjectNIP")},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to update project")
+	}
+
+	err = s.updateColumns(ctx, "Contact_Person", "Id_Project", id, []column{
+		{"First_Name", updateProject.FirstName, fields.Has("FirstName")},
+		{"Last_Name", updateProject.LastName, fields.Has("LastName")},
+		{"Phone", updateProject.Phone, fields.Has("Phone")},
+		{"Position", updateProject.Position, fields.Has("Position")},
+	})
 
 	return errors.Wrap(err, "failed to update project")
 }
 
 func (s *Service) GetProjectNames(ctx context.Context) ([]models.ProjectNames, error) {
-	sql := "SELECT p.Id_project, p.Name FROM Project p ORDER BY p.Id_Project;"
+	scope, args := projectFilter(ctx, "p.Id_Project", nil)
+
+	sql := "SELECT p.Id_project, p.Name FROM Project p WHERE p.Archived_At IS NULL AND " + scope + " ORDER BY p.Id_Project;"
 
-	rows, err := s.DB.QueryContext(ctx, sql)
+	rows, err := s.conn(ctx).QueryContext(ctx, sql, args...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to query for project names")
 	}
@@ -117,3 +242,9 @@ func (s *Service) GetProjectNames(ctx context.Context) ([]models.ProjectNames, e
 
 	return results, errors.Wrap(err, "failed to iterate rows")
 }
+
+func (s *Service) projectVisible(ctx context.Context, id int) error {
+	scope, args := projectFilter(ctx, "Id_Project", []any{id})
+
+	return s.checkVisible(ctx, "SELECT 1 FROM Project WHERE Id_Project = @p1 AND "+scope, args...)
+}
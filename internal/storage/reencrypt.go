@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Reencrypt encrypts every sensitive value that is still stored in plain text
+// or under a key other than the active one with the active key, and refreshes
+// the blind indexes of the rewritten employees. It returns the number of rows
+// updated.
+func (s *Service) Reencrypt(ctx context.Context) (int, error) {
+	employees, err := s.reencryptEmployees(ctx)
+	if err != nil {
+		return employees, err
+	}
+
+	payments, err := s.reencryptPayments(ctx)
+
+	return employees + payments, err
+}
+
+func (s *Service) reencryptEmployees(ctx context.Context) (int, error) {
+	type row struct {
+		id                                 int
+		pesel, passportNumber, bankAccount string
+	}
+
+	sql := "SELECT Id_Employee, COALESCE(Pesel, ''), COALESCE(Passport_Number, ''), COALESCE(Bank_Account, '') FROM Employee;"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, sql)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to query for employees")
+	}
+	defer rows.Close()
+
+	var pending []row
+
+	for rows.Next() {
+		var r row
+		err = rows.Scan(&r.id, &r.pesel, &r.passportNumber, &r.bankAccount)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to scan row")
+		}
+
+		if s.crypt.needsRotation(r.pesel) || s.crypt.needsRotation(r.passportNumber) || s.crypt.needsRotation(r.bankAccount) {
+			pending = append(pending, r)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return 0, errors.Wrap(err, "failed to iterate rows")
+	}
+
+	sql = "UPDATE Employee SET Pesel = @p1, Passport_Number = @p2, Bank_Account = @p3, Pesel_Index = @p4, Passport_Number_Index = @p5 WHERE Id_Employee = @p6;"
+
+	for i, r := range pending {
+		err = s.crypt.decryptAll(map[string]*string{
+			fieldPesel:          &r.pesel,
+			fieldPassportNumber: &r.passportNumber,
+			fieldBankAccount:    &r.bankAccount,
+		})
+		if err != nil {
+			return i, errors.Wrapf(err, "failed to decrypt employee %d", r.id)
+		}
+
+		sensitive, err := s.encryptEmployee(r.pesel, r.passportNumber, r.bankAccount)
+		if err != nil {
+			return i, errors.Wrapf(err, "failed to encrypt employee %d", r.id)
+		}
+
+		_, err = s.conn(ctx).ExecContext(ctx, sql, sensitive.pesel, sensitive.passportNumber, sensitive.bankAccount, sensitive.peselIndex, sensitive.passportNumberIndex, r.id)
+		if err != nil {
+			return i, errors.Wrapf(err, "failed to update employee %d", r.id)
+		}
+	}
+
+	return len(pending), nil
+}
+
+func (s *Service) reencryptPayments(ctx context.Context) (int, error) {
+	type row struct {
+		id            int
+		accountNumber string
+	}
+
+	sql := "SELECT Id_Payment, Account_Number FROM Payments WHERE Account_Number IS NOT NULL;"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, sql)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to query for payments")
+	}
+	defer rows.Close()
+
+	var pending []row
+
+	for rows.Next() {
+		var r row
+		err = rows.Scan(&r.id, &r.accountNumber)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to scan row")
+		}
+
+		if s.crypt.needsRotation(r.accountNumber) {
+			pending = append(pending, r)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return 0, errors.Wrap(err, "failed to iterate rows")
+	}
+
+	sql = "UPDATE Payments SET Account_Number = @p1 WHERE Id_Payment = @p2;"
+
+	for i, r := range pending {
+		plaintext, err := s.crypt.decrypt(fieldAccountNumber, r.accountNumber)
+		if err != nil {
+			return i, errors.Wrapf(err, "failed to decrypt payment %d", r.id)
+		}
+
+		accountNumber, err := s.crypt.encrypt(fieldAccountNumber, plaintext)
+		if err != nil {
+			return i, errors.Wrapf(err, "failed to encrypt payment %d", r.id)
+		}
+
+		_, err = s.conn(ctx).ExecContext(ctx, sql, accountNumber, r.id)
+		if err != nil {
+			return i, errors.Wrapf(err, "failed to update payment %d", r.id)
+		}
+	}
+
+	return len(pending), nil
+}
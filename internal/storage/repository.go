@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"api/internal/models"
+)
+
+// Repository is the persistence layer used by the API. Service stores data in
+// SQL Server or SQLite, Memory keeps it in process for tests and demos.
+type Repository interface {
+	// InTx runs fn as a single unit of work, see Service.InTx.
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+
+	GetUser(ctx context.Context, login string) (models.User, error)
+	SetEmployeeAccount(ctx context.Context, employeeID int, login, passwordHash string, role models.Role) error
+	UpdatePassword(ctx context.Context, login, passwordHash string) error
+	AddPasswordReset(ctx context.Context, reset models.PasswordReset) error
+	UsePasswordReset(ctx context.Context, hash string, now time.Time) (string, error)
+	SetLoginFailures(ctx context.Context, login string, failures int, lockedUntil *time.Time) error
+	AddLoginFailure(ctx context.Context, login string) (int, error)
+	LockUser(ctx context.Context, login string, until time.Time) error
+	AddLoginAttempt(ctx context.Context, attempt models.LoginAttempt) error
+	GetUserProjects(ctx context.Context, employeeID int) ([]int, error)
+	SetUserProjects(ctx context.Context, employeeID int, projectIDs []int) error
+
+	AddRefreshToken(ctx context.Context, token models.RefreshToken) error
+	GetRefreshToken(ctx context.Context, hash string) (models.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, hash string, now time.Time) error
+	RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time, now time.Time) error
+	RevokeUserTokens(ctx context.Context, login string, now time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+
+	AddAuditEntry(ctx context.Context, entry models.AuditEntry) error
+	AuditEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+
+	DashboardEmployeeProjects(ctx context.Context, asOf models.Date) ([]models.DashboardEmployeesProject, error)
+	Accommodation(ctx context.Context, asOf models.Date) ([]models.DashboardAccommodation, error)
+	CarInspections(ctx context.Context) ([]models.DashboardCarInspection, error)
+	EmployeePermits(ctx context.Context) ([]models.DashboardEmployeePermits, error)
+
+	Cars(ctx context.Context, filter models.CarFilter) ([]models.Car, int, error)
+	GetCar(ctx context.Context, id int) (models.Car, error)
+	AddCar(ctx context.Context, newCar models.NewCar) (int, error)
+	UpdateCar(ctx context.Context, id, version int, updateCar models.UpdateCar) error
+	PatchCar(ctx context.Context, id, version int, updateCar models.UpdateCar, fields models.Fields) error
+	ArchiveCar(ctx context.Context, id, version int) error
+	RestoreCar(ctx context.Context, id, version int) error
+	GetCarNumbers(ctx context.Context) ([]models.CarNumbers, error)
+	CarDrivers(ctx context.Context, carID int, filter models.DriverFilter) ([]models.CarDriver, error)
+	CarHandovers(ctx context.Context, carID int) ([]models.CarHandover, error)
+	GetHandover(ctx context.Context, id int) (models.CarHandover, error)
+	AddHandover(ctx context.Context, carID int, newHandover models.NewHandover) (int, error)
+
+	Projects(ctx context.Context, filter models.ProjectFilter) ([]models.Project, int, error)
+	GetProject(ctx context.Context, id int) (models.Project, error)
+	AddProject(ctx context.Context, newProject models.NewProject) (int, error)
+	UpdateProject(ctx context.Context, id, version int, updateProject models.UpdateProject) error
+	PatchProject(ctx context.Context, id, version int, updateProject models.UpdateProject, fields models.Fields) error
+	ArchiveProject(ctx context.Context, id, version int) error
+	RestoreProject(ctx context.Context, id, version int) error
+	GetProjectNames(ctx context.Context) ([]models.ProjectNames, error)
+
+	Accommodations(ctx context.Context, filter models.AccommodationFilter) ([]models.Accommodation, int, error)
+	GetAccommodation(ctx context.Context, id int) (models.Accommodation, error)
+	AddAccommodation(ctx context.Context, newAccommodation models.NewAccommodation) (int, error)
+	UpdateAccommodation(ctx context.Context, id, version int, updateAccommodation models.UpdateAccommodation) error
+	PatchAccommodation(ctx context.Context, id, version int, updateAccommodation models.UpdateAccommodation, fields models.Fields) error
+	ArchiveAccommodation(ctx context.Context, id, version int) error
+	RestoreAccommodation(ctx context.Context, id, version int) error
+	GetAccommodationAddresses(ctx context.Context, asOf models.Date) ([]models.AccommodationAddresses, error)
+	AccommodationStays(ctx context.Context, accommodationID int) ([]models.Stay, error)
+	GetStay(ctx context.Context, id int) (models.Stay, error)
+	AddStay(ctx context.Context, accommodationID int, newStay models.NewStay) (int, error)
+	CheckOutStay(ctx context.Context, id int, day models.Date) error
+	CancelStay(ctx context.Context, id int) error
+
+	Employees(ctx context.Context, filter models.EmployeeFilter) ([]models.Employee, int, error)
+	SearchEmployees(ctx context.Context, search models.EmployeeSearch) ([]models.Employee, int, error)
+	GetEmployee(ctx context.Context, id int) (models.Employee, error)
+	AddEmployee(ctx context.Context, newEmployee models.NewEmployee) (int, error)
+	UpdateEmployee(ctx context.Context, id, version int, updateEmployee models.UpdateEmployee) error
+	PatchEmployee(ctx context.Context, id, version int, updateEmployee models.UpdateEmployee, fields models.Fields) error
+	ArchiveEmployee(ctx context.Context, id, version int) error
+	RestoreEmployee(ctx context.Context, id, version int) error
+	EmployeeAssignments(ctx context.Context, employeeID int) ([]models.ProjectAssignment, error)
+	TransferEmployee(ctx context.Context, id, version, projectID int, from models.Date) error
+	EmployeeStays(ctx context.Context, employeeID int) ([]models.Stay, error)
+
+	Reencrypt(ctx context.Context) (int, error)
+}
+
+var (
+	_ Repository = (*Service)(nil)
+	_ Repository = (*Memory)(nil)
+)
@@ -0,0 +1,619 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"api/internal/auth"
+	"api/internal/models"
+	"github.com/pkg/errors"
+)
+
+// The tests below run against every Repository, so that Memory keeps
+// behaving like the SQL backends it stands in for.
+
+func TestMemoryRepository(t *testing.T) {
+	testRepository(t, func(t *testing.T) Repository {
+		return NewMemory()
+	})
+}
+
+func TestSQLiteRepository(t *testing.T) {
+	testRepository(t, func(t *testing.T) Repository {
+		t.Setenv("DATABASE_URL", "sqlite://"+filepath.Join(t.TempDir(), "test.db"))
+		t.Setenv("AUTO_MIGRATE", "true")
+		t.Setenv("ENCRYPTION_KEYS", "test:AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=")
+		t.Setenv("ENCRYPTION_ACTIVE_KEY", "test")
+		t.Setenv("BLIND_INDEX_KEY", "HyAhIiMkJSYnKCkqKywtLi8wMTIzNDU2Nzg5Ojs8PT4=")
+
+		svc, err := New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { svc.DB.Close() })
+
+		return svc
+	})
+}
+
+func testRepository(t *testing.T, open func(t *testing.T) Repository) {
+	tests := map[string]func(t *testing.T, repo Repository){
+		"VersionMismatch":       testVersionMismatch,
+		"ArchiveAndRestore":     testArchiveAndRestore,
+		"ProjectScope":          testProjectScope,
+		"StayCapacity":          testStayCapacity,
+		"EmployeeAssignments":   testEmployeeAssignments,
+		"AssignmentsInScope":    testAssignmentsInScope,
+		"StaysInScope":          testStaysInScope,
+		"SensitiveFieldsStored": testSensitiveFieldsStored,
+		"PatchWritesOnlyFields": testPatchWritesOnlyFields,
+		"CarsChangeHands":       testCarsChangeHands,
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test(t, open(t))
+		})
+	}
+}
+
+// systemContext is the context of calls that are not restricted to any
+// project.
+func systemContext() context.Context {
+	return auth.NewContext(context.Background(), auth.System)
+}
+
+// coordinatorContext is the context of a coordinator bound to the projects.
+func coordinatorContext(projectIDs ...int) context.Context {
+	return auth.NewContext(context.Background(), auth.Principal{
+		Username:   "coordinator",
+		Role:       models.RoleCoordinator,
+		ProjectIDs: projectIDs,
+	})
+}
+
+func addProject(t *testing.T, repo Repository, name string) int {
+	t.Helper()
+
+	id, err := repo.AddProject(systemContext(), models.NewProject{Name: name})
+	if err != nil {
+		t.Fatalf("adding project %s: %v", name, err)
+	}
+
+	return id
+}
+
+func addAccommodation(t *testing.T, repo Repository, projectID, places int) int {
+	t.Helper()
+
+	id, err := repo.AddAccommodation(systemContext(), models.NewAccommodation{
+		ProjectID:      projectID,
+		City:           "Kraków",
+		Address:        "ul. Długa 1",
+		NumberOfPlaces: places,
+	})
+	if err != nil {
+		t.Fatalf("adding accommodation: %v", err)
+	}
+
+	return id
+}
+
+func addEmployee(t *testing.T, repo Repository, lastName string, projectID int) int {
+	t.Helper()
+
+	id, err := repo.AddEmployee(systemContext(), models.NewEmployee{
+		LastName:    lastName,
+		FirstName:   "Jan",
+		DateOfBirth: models.Date(time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC)),
+		ProjectId:   projectID,
+	})
+	if err != nil {
+		t.Fatalf("adding employee %s: %v", lastName, err)
+	}
+
+	return id
+}
+
+// handOver records a handover of the car the given number of days from now.
+func handOver(t *testing.T, repo Repository, carID int, from, to *int, days int) {
+	t.Helper()
+
+	_, err := repo.AddHandover(systemContext(), carID, models.NewHandover{
+		FromEmployeeID: from,
+		ToEmployeeID:   to,
+		HandedAt:       ptr(time.Now().UTC().AddDate(0, 0, days)),
+	})
+	if err != nil {
+		t.Fatalf("handing over car %d: %v", carID, err)
+	}
+}
+
+// day returns the day the given number of days from today.
+func day(days int) models.Date {
+	return models.Today().AddDays(days)
+}
+
+func sameDay(a, b models.Date) bool {
+	return time.Time(a).Equal(time.Time(b))
+}
+
+func testVersionMismatch(t *testing.T, repo Repository) {
+	ctx := systemContext()
+	id := addProject(t, repo, "Alpha")
+
+	project, err := repo.GetProject(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.UpdateProject(ctx, id, project.Version+1, models.UpdateProject{Name: "Beta"})
+	if !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("updating a future version: got %v, want ErrVersionMismatch", err)
+	}
+
+	err = repo.UpdateProject(ctx, id, project.Version, models.UpdateProject{Name: "Beta"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := repo.GetProject(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated.Name != "Beta" || updated.Version != project.Version+1 {
+		t.Errorf("got %q at version %d, want %q at version %d", updated.Name, updated.Version, "Beta", project.Version+1)
+	}
+
+	err = repo.UpdateProject(ctx, id, project.Version, models.UpdateProject{Name: "Gamma"})
+	if !errors.Is(err, ErrVersionMismatch) {
+		t.Errorf("updating a stale version: got %v, want ErrVersionMismatch", err)
+	}
+}
+
+func testArchiveAndRestore(t *testing.T, repo Repository) {
+	ctx := systemContext()
+	id := addProject(t, repo, "Alpha")
+
+	project, err := repo.GetProject(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = repo.ArchiveProject(ctx, id, project.Version); err != nil {
+		t.Fatal(err)
+	}
+
+	archived, err := repo.GetProject(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !archived.Archived() || archived.ArchivedBy != auth.System.Username {
+		t.Errorf("got archival %+v, want archived by %s", archived.Archival, auth.System.Username)
+	}
+
+	projects, total, err := repo.Projects(ctx, models.ProjectFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != 0 || len(projects) != 0 {
+		t.Errorf("got %d archived projects listed, want none", total)
+	}
+
+	_, total, err = repo.Projects(ctx, models.ProjectFilter{ListFilter: models.ListFilter{IncludeArchived: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != 1 {
+		t.Errorf("got %d projects including archived ones, want 1", total)
+	}
+
+	err = repo.UpdateProject(ctx, id, archived.Version, models.UpdateProject{Name: "Beta"})
+	if !errors.Is(err, ErrArchived) {
+		t.Errorf("updating an archived project: got %v, want ErrArchived", err)
+	}
+
+	_, err = repo.AddAccommodation(ctx, models.NewAccommodation{ProjectID: id, NumberOfPlaces: 1})
+	if !errors.Is(err, ErrReferenced) {
+		t.Errorf("adding to an archived project: got %v, want ErrReferenced", err)
+	}
+
+	if err = repo.RestoreProject(ctx, id, archived.Version); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := repo.GetProject(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.Archived() {
+		t.Errorf("got archival %+v after restore, want none", restored.Archival)
+	}
+
+	if restored.Version <= archived.Version {
+		t.Errorf("got version %d after restore, want above %d", restored.Version, archived.Version)
+	}
+}
+
+func testProjectScope(t *testing.T, repo Repository) {
+	alpha := addProject(t, repo, "Alpha")
+	beta := addProject(t, repo, "Beta")
+	inAlpha := addEmployee(t, repo, "Nowak", alpha)
+	inBeta := addEmployee(t, repo, "Kowalski", beta)
+
+	ctx := coordinatorContext(alpha)
+
+	projects, total, err := repo.Projects(ctx, models.ProjectFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != 1 || len(projects) != 1 || projects[0].ID != alpha {
+		t.Errorf("got projects %+v, want only Alpha", projects)
+	}
+
+	if _, err = repo.GetProject(ctx, beta); !errors.Is(err, ErrNotFound) {
+		t.Errorf("getting a project out of scope: got %v, want ErrNotFound", err)
+	}
+
+	employees, total, err := repo.Employees(ctx, models.EmployeeFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != 1 || len(employees) != 1 || employees[0].ID != inAlpha {
+		t.Errorf("got employees %+v, want only Nowak", employees)
+	}
+
+	if _, err = repo.GetEmployee(ctx, inBeta); !errors.Is(err, ErrNotFound) {
+		t.Errorf("getting an employee out of scope: got %v, want ErrNotFound", err)
+	}
+
+	_, err = repo.AddEmployee(ctx, models.NewEmployee{LastName: "Wiśniewski", ProjectId: beta})
+	if !errors.Is(err, ErrForbidden) {
+		t.Errorf("adding an employee to a project out of scope: got %v, want ErrForbidden", err)
+	}
+
+	if _, total, err = repo.Employees(context.Background(), models.EmployeeFilter{}); err != nil || total != 0 {
+		t.Errorf("listing employees without a principal: got %d, %v, want none", total, err)
+	}
+}
+
+func testStayCapacity(t *testing.T, repo Repository) {
+	ctx := systemContext()
+	project := addProject(t, repo, "Alpha")
+	accommodation := addAccommodation(t, repo, project, 1)
+	first := addEmployee(t, repo, "Nowak", project)
+	second := addEmployee(t, repo, "Kowalski", project)
+
+	_, err := repo.AddStay(ctx, accommodation, models.NewStay{EmployeeID: first, CheckIn: day(0), CheckOut: ptr(day(10))})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repo.AddStay(ctx, accommodation, models.NewStay{EmployeeID: second, CheckIn: day(5), CheckOut: ptr(day(15))})
+	if !errors.Is(err, ErrNoVacancy) {
+		t.Errorf("booking a full accommodation: got %v, want ErrNoVacancy", err)
+	}
+
+	_, err = repo.AddStay(ctx, accommodation, models.NewStay{EmployeeID: first, CheckIn: day(5), CheckOut: ptr(day(8))})
+	if !errors.Is(err, ErrOverlap) {
+		t.Errorf("booking an overlapping stay: got %v, want ErrOverlap", err)
+	}
+
+	id, err := repo.AddStay(ctx, accommodation, models.NewStay{EmployeeID: second, CheckIn: day(10), CheckOut: ptr(day(15))})
+	if err != nil {
+		t.Fatalf("booking the place once it is vacated: %v", err)
+	}
+
+	stays, err := repo.AccommodationStays(ctx, accommodation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stays) != 2 || stays[1].ID != id || stays[1].EmployeeID != second {
+		t.Errorf("got stays %+v, want the stays of Nowak and Kowalski", stays)
+	}
+}
+
+func testEmployeeAssignments(t *testing.T, repo Repository) {
+	ctx := systemContext()
+	alpha := addProject(t, repo, "Alpha")
+	beta := addProject(t, repo, "Beta")
+	id := addEmployee(t, repo, "Nowak", alpha)
+
+	employee, err := repo.GetEmployee(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.TransferEmployee(ctx, id, employee.Version+1, beta, day(7))
+	if !errors.Is(err, ErrVersionMismatch) {
+		t.Errorf("transferring a stale version: got %v, want ErrVersionMismatch", err)
+	}
+
+	if err = repo.TransferEmployee(ctx, id, employee.Version, beta, day(7)); err != nil {
+		t.Fatal(err)
+	}
+
+	assignments, err := repo.EmployeeAssignments(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(assignments) != 2 {
+		t.Fatalf("got assignments %+v, want 2", assignments)
+	}
+
+	current, previous := assignments[0], assignments[1]
+
+	if current.ProjectID != beta || !current.Current() || !sameDay(current.ValidFrom, day(7)) {
+		t.Errorf("got current assignment %+v, want Beta from %v", current, day(7))
+	}
+
+	if previous.ProjectID != alpha || previous.ValidTo == nil || !sameDay(*previous.ValidTo, day(6)) {
+		t.Errorf("got previous assignment %+v, want Alpha until %v", previous, day(6))
+	}
+
+	transferred, err := repo.GetEmployee(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if transferred.ProjectId != beta {
+		t.Errorf("got project %d after transfer, want %d", transferred.ProjectId, beta)
+	}
+}
+
+func testAssignmentsInScope(t *testing.T, repo Repository) {
+	alpha := addProject(t, repo, "Alpha")
+	beta := addProject(t, repo, "Beta")
+	id := addEmployee(t, repo, "Nowak", alpha)
+
+	employee, err := repo.GetEmployee(systemContext(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = repo.TransferEmployee(systemContext(), id, employee.Version, beta, day(7)); err != nil {
+		t.Fatal(err)
+	}
+
+	assignments, err := repo.EmployeeAssignments(coordinatorContext(beta), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(assignments) != 1 || assignments[0].ProjectID != beta {
+		t.Errorf("got assignments %+v, want only the one to Beta", assignments)
+	}
+}
+
+func testStaysInScope(t *testing.T, repo Repository) {
+	alpha := addProject(t, repo, "Alpha")
+	beta := addProject(t, repo, "Beta")
+	inAlpha := addAccommodation(t, repo, alpha, 2)
+	inBeta := addAccommodation(t, repo, beta, 2)
+	id := addEmployee(t, repo, "Nowak", beta)
+
+	ctx := systemContext()
+
+	if _, err := repo.AddStay(ctx, inAlpha, models.NewStay{EmployeeID: id, CheckIn: day(-10), CheckOut: ptr(day(-5))}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.AddStay(ctx, inBeta, models.NewStay{EmployeeID: id, CheckIn: day(-5)}); err != nil {
+		t.Fatal(err)
+	}
+
+	stays, err := repo.EmployeeStays(coordinatorContext(beta), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stays) != 1 || stays[0].AccommodationID != inBeta {
+		t.Errorf("got stays %+v, want only the one in Beta's accommodation", stays)
+	}
+}
+
+func testSensitiveFieldsStored(t *testing.T, repo Repository) {
+	ctx := systemContext()
+	project := addProject(t, repo, "Alpha")
+
+	id, err := repo.AddEmployee(ctx, models.NewEmployee{
+		LastName:       "Nowak",
+		Pesel:          "90051512340",
+		PassportNumber: "EA1234567",
+		BankAccount:    "PL61109010140000071219812874",
+		ProjectId:      project,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	employee, err := repo.GetEmployee(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if employee.Pesel != "90051512340" || employee.PassportNumber != "EA1234567" || employee.BankAccount != "PL61109010140000071219812874" {
+		t.Errorf("got %q, %q, %q back", employee.Pesel, employee.PassportNumber, employee.BankAccount)
+	}
+
+	employees, _, err := repo.Employees(ctx, models.EmployeeFilter{Pesel: "90051512340"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(employees) != 1 || employees[0].ID != id {
+		t.Errorf("got employees %+v with the PESEL, want Nowak", employees)
+	}
+}
+
+func testPatchWritesOnlyFields(t *testing.T, repo Repository) {
+	ctx := systemContext()
+	projectID := addProject(t, repo, "Alpha")
+	accommodationID := addAccommodation(t, repo, projectID, 2)
+
+	carID, err := repo.AddCar(ctx, models.NewCar{
+		Model:     "Toyota Proace",
+		IdProject: projectID,
+		Service:   models.Service{ServiceName: "ASO"},
+		Leasing:   models.Leasing{PaymentDay: 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	osh := models.Date(time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC))
+
+	id, err := repo.AddEmployee(ctx, models.NewEmployee{
+		LastName:        "Kowalski",
+		FirstName:       "Jan",
+		Pesel:           "85010112345",
+		Email:           "jan@example.com",
+		Medicals:        models.NewMedicalDetails{OSHValidUntil: osh},
+		ProjectId:       projectID,
+		AccommodationId: accommodationID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handOver(t, repo, carID, nil, &id, -1)
+
+	before, err := repo.GetEmployee(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	drivers, err := repo.CarDrivers(ctx, carID, models.DriverFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every other field of the update is empty, so any of them written
+	// would show.
+	err = repo.PatchEmployee(ctx, id, before.Version, models.UpdateEmployee{Email: "kowalski@example.com"}, models.Fields{"Email": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := repo.GetEmployee(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	switch {
+	case after.Email != "kowalski@example.com":
+		t.Errorf("email = %q, want it patched", after.Email)
+	case after.LastName != before.LastName || after.Pesel != before.Pesel:
+		t.Errorf("patching the email changed the name or PESEL: %+v", after)
+	case after.HomeAddress != nil:
+		t.Errorf("home address = %q, want it left NULL", *after.HomeAddress)
+	case !sameDay(after.Medicals.OSHValidUntil, osh):
+		t.Errorf("OSH valid until %v, want the medicals left alone", after.Medicals.OSHValidUntil)
+	case after.AccommodationId == nil || *after.AccommodationId != accommodationID:
+		t.Errorf("accommodation = %v, want %d left alone", after.AccommodationId, accommodationID)
+	case after.CarId == nil || *after.CarId != carID:
+		t.Errorf("car = %v, want %d left alone", after.CarId, carID)
+	case after.Version != before.Version+1:
+		t.Errorf("version = %d, want %d", after.Version, before.Version+1)
+	}
+
+	afterDrivers, err := repo.CarDrivers(ctx, carID, models.DriverFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(afterDrivers) != len(drivers) {
+		t.Errorf("patching the email changed the driving periods from %d to %d", len(drivers), len(afterDrivers))
+	}
+
+	car, err := repo.GetCar(ctx, carID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.PatchCar(ctx, carID, car.Version, models.UpdateCar{Color: "biały"}, models.Fields{"Color": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	car, err = repo.GetCar(ctx, carID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if car.Color != "biały" || car.Model != "Toyota Proace" || car.Service.ServiceName != "ASO" || car.Leasing.PaymentDay != 10 {
+		t.Errorf("patching the color gave %+v, want only the color changed", car)
+	}
+}
+
+func testCarsChangeHands(t *testing.T, repo Repository) {
+	ctx := systemContext()
+	projectID := addProject(t, repo, "Alpha")
+	first := addEmployee(t, repo, "Kowalski", projectID)
+	second := addEmployee(t, repo, "Nowak", projectID)
+
+	carID, err := repo.AddCar(ctx, models.NewCar{Model: "Toyota Proace", IdProject: projectID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repo.AddEmployee(ctx, models.NewEmployee{LastName: "Wiśniewski", FirstName: "Jan", ProjectId: projectID, CarId: carID})
+	if !errors.Is(err, ErrCarHandover) {
+		t.Errorf("adding an employee with a car: got %v, want ErrCarHandover", err)
+	}
+
+	handOver(t, repo, carID, nil, &first, -2)
+
+	_, err = repo.AddHandover(ctx, carID, models.NewHandover{ToEmployeeID: &second, HandedAt: ptr(time.Now().UTC().AddDate(0, 0, -1))})
+	if !errors.Is(err, ErrDriverMismatch) {
+		t.Errorf("handing over a driven car from nobody: got %v, want ErrDriverMismatch", err)
+	}
+
+	employee, err := repo.GetEmployee(ctx, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.PatchEmployee(ctx, second, employee.Version, models.UpdateEmployee{CarId: carID}, models.Fields{"CarId": true})
+	if !errors.Is(err, ErrCarHandover) {
+		t.Errorf("patching the car of an employee: got %v, want ErrCarHandover", err)
+	}
+
+	update := employee.Update()
+	update.LastName = "Nowakowski"
+	if err = repo.UpdateEmployee(ctx, second, employee.Version, update); err != nil {
+		t.Errorf("updating an employee without changing the car: %v", err)
+	}
+
+	handOver(t, repo, carID, &first, &second, -1)
+
+	drivers, err := repo.CarDrivers(ctx, carID, models.DriverFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current := 0
+	for _, d := range drivers {
+		if d.ValidTo == nil {
+			current++
+			if d.EmployeeID != second {
+				t.Errorf("car driven by %d, want %d", d.EmployeeID, second)
+			}
+		}
+	}
+
+	if current != 1 {
+		t.Errorf("got %d current drivers, want 1", current)
+	}
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"api/internal/auth"
+	"github.com/pkg/errors"
+)
+
+// projectFilter returns a condition restricting column to the projects the
+// principal in ctx may access, together with args extended by the
+// condition's parameters, which are numbered after the existing ones.
+func projectFilter(ctx context.Context, column string, args []any) (string, []any) {
+	projectIDs, restricted := auth.ProjectScope(ctx)
+	if !restricted {
+		return "1 = 1", args
+	}
+
+	if len(projectIDs) == 0 {
+		return "1 = 0", args
+	}
+
+	placeholders := make([]string, len(projectIDs))
+	for i, id := range projectIDs {
+		args = append(args, id)
+		placeholders[i] = fmt.Sprintf("@p%d", len(args))
+	}
+
+	return fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", ")), args
+}
+
+// employeeFilter restricts column, holding employee IDs, to employees
+// assigned to the projects the principal in ctx may access.
+func employeeFilter(ctx context.Context, column string, args []any) (string, []any) {
+	cond, args := projectFilter(ctx, "Id_Project", args)
+	if _, restricted := auth.ProjectScope(ctx); !restricted {
+		return cond, args
+	}
+
+	return fmt.Sprintf("%s IN (SELECT Id_Employee FROM Employee_Project WHERE Valid_To IS NULL AND %s)", column, cond), args
+}
+
+// checkProject returns ErrForbidden if the principal in ctx may not assign
+// records to the given project.
+func checkProject(ctx context.Context, projectID int) error {
+	projectIDs, restricted := auth.ProjectScope(ctx)
+	if restricted && !slices.Contains(projectIDs, projectID) {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// bumpVersion increments the row version of a record the caller has already
+// checked to be visible. It returns ErrVersionMismatch if the record is no
+// longer at the given version, so the changes made by someone else since the
+// caller read it are not overwritten.
+func (s *Service) bumpVersion(ctx context.Context, table, column string, id, version int) error {
+	sql := fmt.Sprintf("UPDATE %s SET Version = Version + 1 WHERE %s = @p1 AND Version = @p2;", table, column)
+
+	res, err := s.conn(ctx).ExecContext(ctx, sql, id, version)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update %s version", table)
+	}
+
+	if err = expectRows(res); errors.Is(err, ErrNotFound) {
+		return ErrVersionMismatch
+	}
+
+	return err
+}
+
+// checkVisible returns ErrNotFound unless query, a SELECT restricted to the
+// caller's scope, yields a row.
+func (s *Service) checkVisible(ctx context.Context, query string, args ...any) error {
+	var found int
+
+	return s.conn(ctx).QueryRowContext(ctx, query, args...).Scan(&found)
+}
@@ -0,0 +1,387 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"api/internal/models"
+	"github.com/pkg/errors"
+	"golang.org/x/text/unicode/norm"
+)
+
+// The search key of an employee lists the folded tokens of their names, each
+// followed by its skeleton prefixed with "~", and the folded tokens of their
+// email prefixed with "@". It starts and ends with a space, so that a token
+// is matched from its start with LIKE '% token%'.
+const (
+	skeletonMark = "~"
+	emailMark    = "@"
+)
+
+// transliteration maps the letters that lose information when their
+// diacritics are dropped, and the Cyrillic and Georgian alphabets, to Latin.
+// Cyrillic follows the Ukrainian national transliteration, extended with the
+// Russian and Belarusian letters.
+var transliteration = map[rune]string{
+	'ł': "l", 'đ': "d", 'ø': "o", 'ß': "ss", 'æ': "ae", 'œ': "oe", 'ı': "i",
+
+	'а': "a", 'б': "b", 'в': "v", 'г': "h", 'ґ': "g", 'д': "d", 'е': "e",
+	'є': "ye", 'ё': "yo", 'ж': "zh", 'з': "z", 'и': "y", 'і': "i", 'ї': "yi",
+	'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p",
+	'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ў': "u", 'ф': "f", 'х': "kh",
+	'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "",
+	'э': "e", 'ю': "yu", 'я': "ya",
+
+	'ა': "a", 'ბ': "b", 'გ': "g", 'დ': "d", 'ე': "e", 'ვ': "v", 'ზ': "z",
+	'თ': "t", 'ი': "i", 'კ': "k", 'ლ': "l", 'მ': "m", 'ნ': "n", 'ო': "o",
+	'პ': "p", 'ჟ': "zh", 'რ': "r", 'ს': "s", 'ტ': "t", 'უ': "u", 'ფ': "p",
+	'ქ': "k", 'ღ': "gh", 'ყ': "q", 'შ': "sh", 'ჩ': "ch", 'ც': "ts", 'ძ': "dz",
+	'წ': "ts", 'ჭ': "ch", 'ხ': "kh", 'ჯ': "j", 'ჰ': "h",
+}
+
+// fold returns the lower-case Latin tokens of s without diacritics, so that
+// "Łukasz", "lukasz" and "Лукаш" are spelled alike up to transliteration.
+func fold(s string) []string {
+	var b strings.Builder
+
+	for _, r := range strings.ToLower(s) {
+		if latin, ok := transliteration[r]; ok {
+			b.WriteString(latin)
+			continue
+		}
+
+		for _, d := range norm.NFD.String(string(r)) {
+			switch {
+			case unicode.Is(unicode.Mn, d):
+			case unicode.IsLetter(d) || unicode.IsDigit(d):
+				b.WriteRune(d)
+			default:
+				b.WriteByte(' ')
+			}
+		}
+	}
+
+	return strings.Fields(b.String())
+}
+
+var (
+	// spelling unifies the Polish, English and transliterated spellings of
+	// the same sounds.
+	spelling = strings.NewReplacer(
+		"szcz", "shch", "sz", "sh", "cz", "ch", "rz", "zh",
+		"x", "ks", "q", "k", "w", "v", "ph", "f", "ck", "k", "ts", "c", "tz", "c",
+	)
+	// sounds replaces the digraphs left by spelling with single letters that
+	// it no longer produces, and merges h into g as in "Olha" and "Olga".
+	sounds = strings.NewReplacer(
+		"shch", "xq", "sh", "x", "ch", "q", "zh", "w", "kh", "g", "h", "g",
+	)
+)
+
+// skeleton returns the consonants of a folded token after unifying their
+// spelling, so that "Oleksandr", "Aleksandr" and "Alexander" share one.
+func skeleton(token string) string {
+	token = sounds.Replace(spelling.Replace(token))
+
+	var (
+		b    strings.Builder
+		last rune
+	)
+
+	for _, r := range token {
+		if strings.ContainsRune("aeiouyj", r) || r == last {
+			continue
+		}
+
+		b.WriteRune(r)
+		last = r
+	}
+
+	return b.String()
+}
+
+// searchKey returns the search key of an employee.
+func searchKey(firstName, lastName, maidenName, email string) string {
+	var b strings.Builder
+
+	b.WriteByte(' ')
+
+	for _, token := range fold(firstName + " " + lastName + " " + maidenName) {
+		b.WriteString(token + " ")
+		if skel := skeleton(token); skel != "" {
+			b.WriteString(skeletonMark + skel + " ")
+		}
+	}
+
+	for _, token := range fold(email) {
+		b.WriteString(emailMark + token + " ")
+	}
+
+	return b.String()
+}
+
+// Skeletons shorter than minSkeleton match too many names to be searched
+// for, and those shorter than minSkeletonPrefix only match whole.
+const (
+	minSkeleton       = 2
+	minSkeletonPrefix = 3
+)
+
+// searchTerm is a token of a search query.
+type searchTerm struct {
+	token, skeleton string
+}
+
+func searchTerms(query string) []searchTerm {
+	var terms []searchTerm
+
+	for _, token := range fold(query) {
+		terms = append(terms, searchTerm{token: token, skeleton: skeleton(token)})
+	}
+
+	return terms
+}
+
+// patterns returns the LIKE patterns of the search keys matching the term.
+func (t searchTerm) patterns() []string {
+	patterns := []string{"% " + t.token + "%", "% " + emailMark + t.token + "%"}
+
+	switch {
+	case len(t.skeleton) >= minSkeletonPrefix:
+		patterns = append(patterns, "% "+skeletonMark+t.skeleton+"%")
+	case len(t.skeleton) >= minSkeleton:
+		patterns = append(patterns, "% "+skeletonMark+t.skeleton+" %")
+	}
+
+	return patterns
+}
+
+// rank scores how well the term matches the key, 0 meaning not at all. Whole
+// names score above their prefixes, which score above names that only sound
+// alike, which score above parts of the email.
+func (t searchTerm) rank(key string) int {
+	best := 0
+
+	for _, token := range strings.Fields(key) {
+		score := 0
+
+		switch {
+		case token == t.token:
+			score = 10
+		case strings.HasPrefix(token, t.token):
+			score = 6
+		case len(t.skeleton) >= minSkeleton && token == skeletonMark+t.skeleton:
+			score = 4
+		case len(t.skeleton) >= minSkeletonPrefix && strings.HasPrefix(token, skeletonMark+t.skeleton):
+			score = 2
+		case token == emailMark+t.token:
+			score = 3
+		case strings.HasPrefix(token, emailMark+t.token):
+			score = 1
+		}
+
+		best = max(best, score)
+	}
+
+	return best
+}
+
+// searchIdentifier returns the query normalized for matching PESEL and
+// passport numbers, or "" if they are not to be matched.
+func searchIdentifier(search models.EmployeeSearch) string {
+	if !search.Identifiers {
+		return ""
+	}
+
+	return normalizeIdentifier(search.Query)
+}
+
+// searchRank scores an employee with the given search key against the
+// terms of a query. It is 0 unless every term matches. An exact PESEL or
+// passport number scores above any name.
+func searchRank(key string, terms []searchTerm, identifierMatch bool) int {
+	if identifierMatch {
+		return 100
+	}
+
+	if len(terms) == 0 {
+		return 0
+	}
+
+	total := 0
+
+	for _, term := range terms {
+		score := term.rank(key)
+		if score == 0 {
+			return 0
+		}
+
+		total += score
+	}
+
+	return total
+}
+
+// rankedEmployee is an employee matching a search.
+type rankedEmployee struct {
+	models.Employee
+
+	rank int
+}
+
+// sortRanked returns the employees most relevant first.
+func sortRanked(ranked []rankedEmployee) []models.Employee {
+	slices.SortStableFunc(ranked, func(a, b rankedEmployee) int {
+		if c := cmp.Compare(b.rank, a.rank); c != 0 {
+			return c
+		}
+
+		if c := compareText(a.LastName, b.LastName); c != 0 {
+			return c
+		}
+
+		if c := compareText(a.FirstName, b.FirstName); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	results := make([]models.Employee, len(ranked))
+	for i, r := range ranked {
+		results[i] = r.Employee
+	}
+
+	return results
+}
+
+// SearchEmployees returns the filter's page of the employees matching the
+// query on their names, email and, if search.Identifiers is set, PESEL or
+// passport number, most relevant first, together with the number of matches. Employees saved before the
+// search key was added are matched on names only after Reindex.
+func (s *Service) SearchEmployees(ctx context.Context, search models.EmployeeSearch) ([]models.Employee, int, error) {
+	terms := searchTerms(search.Query)
+
+	scope, args := employeeFilter(ctx, "e.Id_Employee", nil)
+	scope += " AND " + archivedFilter("e", search.ListFilter)
+
+	conds := make([]string, 0, len(terms))
+
+	for _, term := range terms {
+		var alternatives []string
+
+		for _, pattern := range term.patterns() {
+			args = append(args, pattern)
+			alternatives = append(alternatives, fmt.Sprintf("e.Search_Key LIKE @p%d", len(args)))
+		}
+
+		conds = append(conds, "("+strings.Join(alternatives, " OR ")+")")
+	}
+
+	match := "1 = 0"
+	if len(conds) > 0 {
+		match = strings.Join(conds, " AND ")
+	}
+
+	if search.Identifiers {
+		args = append(args, s.crypt.blindIndex(fieldPesel, search.Query), s.crypt.blindIndex(fieldPassportNumber, search.Query))
+		match += fmt.Sprintf(" OR e.Pesel_Index = @p%d OR e.Passport_Number_Index = @p%d", len(args)-1, len(args))
+	}
+
+	sql := "SELECT e.Id_Employee, e.Last_Name, e.First_Name, e.Pesel, e.Passport_Number, e.Email, e.Date_Of_Birth, e.Archived_At, COALESCE(e.Archived_By, ''), COALESCE(e.Search_Key, '') FROM Employee e WHERE " + scope + " AND (" + match + ");"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to search for employees")
+	}
+	defer rows.Close()
+
+	ranked := make([]rankedEmployee, 0)
+	identifier := searchIdentifier(search)
+
+	for rows.Next() {
+		var (
+			employee models.Employee
+			key      string
+		)
+
+		err = rows.Scan(&employee.ID, &employee.LastName, &employee.FirstName, &employee.Pesel, &employee.PassportNumber, &employee.Email, &employee.DateOfBirth, &employee.ArchivedAt, &employee.ArchivedBy, &key)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "failed to scan row")
+		}
+
+		err = s.crypt.decryptAll(map[string]*string{
+			fieldPesel:          &employee.Pesel,
+			fieldPassportNumber: &employee.PassportNumber,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+
+		identifierMatch := identifier != "" && (normalizeIdentifier(employee.Pesel) == identifier || normalizeIdentifier(employee.PassportNumber) == identifier)
+
+		if rank := searchRank(key, terms, identifierMatch); rank > 0 {
+			ranked = append(ranked, rankedEmployee{Employee: employee, rank: rank})
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, errors.Wrap(err, "failed to iterate rows")
+	}
+
+	return paginate(sortRanked(ranked), search.ListFilter), len(ranked), nil
+}
+
+// Reindex recomputes the search keys of all employees, including those saved
+// before the keys were introduced. It returns the number of rows updated.
+func (s *Service) Reindex(ctx context.Context) (int, error) {
+	type row struct {
+		id       int
+		key, was string
+	}
+
+	sql := "SELECT Id_Employee, COALESCE(First_Name, ''), COALESCE(Last_Name, ''), COALESCE(Maiden_Name, ''), COALESCE(Email, ''), COALESCE(Search_Key, '') FROM Employee;"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, sql)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to query for employees")
+	}
+	defer rows.Close()
+
+	var pending []row
+
+	for rows.Next() {
+		var (
+			r                                      row
+			firstName, lastName, maidenName, email string
+		)
+
+		err = rows.Scan(&r.id, &firstName, &lastName, &maidenName, &email, &r.was)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to scan row")
+		}
+
+		if r.key = searchKey(firstName, lastName, maidenName, email); r.key != r.was {
+			pending = append(pending, r)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return 0, errors.Wrap(err, "failed to iterate rows")
+	}
+
+	sql = "UPDATE Employee SET Search_Key = @p1 WHERE Id_Employee = @p2;"
+
+	for i, r := range pending {
+		_, err = s.conn(ctx).ExecContext(ctx, sql, r.key, r.id)
+		if err != nil {
+			return i, errors.Wrapf(err, "failed to update employee %d", r.id)
+		}
+	}
+
+	return len(pending), nil
+}
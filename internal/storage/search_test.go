@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Łukasz", []string{"lukasz"}},
+		{"Zażółć Gęślą", []string{"zazolc", "gesla"}},
+		{"Jaźwińska-Brzęczyszczykiewicz", []string{"jazwinska", "brzeczyszczykiewicz"}},
+		{"Олександр", []string{"oleksandr"}},
+		{"Шевченко", []string{"shevchenko"}},
+		{"Ольга Їжакевич", []string{"olha", "yizhakevych"}},
+		{"Jean-Pierre O'Neil", []string{"jean", "pierre", "o", "neil"}},
+		{"  ", nil},
+	}
+
+	for _, tt := range tests {
+		if got := fold(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("fold(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSkeletonMatchesSpellings(t *testing.T) {
+	groups := [][]string{
+		{"Oleksandr", "Aleksandr", "Alexander", "Олександр", "Александр"},
+		{"Шевченко", "Shevchenko", "Szewczenko"},
+		{"Łukasz", "Lukasz", "Лукаш"},
+		{"Wiśniewski", "Wisniewski"},
+		{"Jaźwińska", "Jazwinska"},
+		{"Olha", "Olga", "Ольга"},
+	}
+
+	for _, group := range groups {
+		want := skeleton(fold(group[0])[0])
+
+		for _, name := range group[1:] {
+			if got := skeleton(fold(name)[0]); got != want {
+				t.Errorf("skeleton of %s = %q, want %q as for %s", name, got, want, group[0])
+			}
+		}
+	}
+
+	if skeleton(fold("Nowak")[0]) == skeleton(fold("Kowalski")[0]) {
+		t.Error("Nowak and Kowalski share a skeleton")
+	}
+}
+
+func TestSearchRank(t *testing.T) {
+	key := searchKey("Oleksandr", "Shevchenko", "", "o.shevchenko@example.com")
+
+	rank := func(query string) int {
+		return searchRank(key, searchTerms(query), false)
+	}
+
+	for _, query := range []string{
+		"Oleksandr", "Aleksandr", "Alexander", "Олександр",
+		"Shevchenko", "Szewczenko", "Шевченко",
+		"Alexander Szewczenko", "shev", "example",
+	} {
+		if rank(query) == 0 {
+			t.Errorf("%q does not match %q", query, key)
+		}
+	}
+
+	for _, query := range []string{"Petro", "Oleksandr Kowalski", ""} {
+		if rank(query) != 0 {
+			t.Errorf("%q matches %q", query, key)
+		}
+	}
+
+	ordered := []string{"Oleksandr Shevchenko", "Олександр Шевченко", "Alexander Szewczenko", "example"}
+	for i := 1; i < len(ordered); i++ {
+		if rank(ordered[i-1]) < rank(ordered[i]) {
+			t.Errorf("%q ranks %d, below %q at %d", ordered[i-1], rank(ordered[i-1]), ordered[i], rank(ordered[i]))
+		}
+	}
+
+	if rank("Oleksandr") <= rank("Aleksandr") {
+		t.Errorf("the spelling as stored ranks %d, not above another at %d", rank("Oleksandr"), rank("Aleksandr"))
+	}
+
+	if searchRank(key, searchTerms("EA1234567"), true) <= rank("Oleksandr Shevchenko") {
+		t.Error("an identifier match does not rank above names")
+	}
+}
@@ -1,41 +1,102 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
 	_ "github.com/microsoft/go-mssqldb"
 	"github.com/pkg/errors"
+	_ "modernc.org/sqlite"
 )
 
 type Service struct {
 	DB *sql.DB
+
+	crypt   *fieldCipher
+	dialect dialect
+}
+
+// New connects to the database and, if AUTO_MIGRATE is set, applies pending
+// migrations.
+func New() (*Service, error) {
+	svc, cfg, err := connect()
+	if err != nil {
+		return nil, err
+	}
+
+	if !cfg.AutoMigrate {
+		return svc, nil
+	}
+
+	applied, err := svc.MigrateUp(context.Background())
+	for _, m := range applied {
+		fmt.Printf("Applied migration %s\n", m)
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to migrate database")
+	}
+
+	return svc, nil
 }
 
-func New() (Service, error) {
-	svc := Service{}
+// Open connects to the database without migrating it.
+func Open() (*Service, error) {
+	svc, _, err := connect()
+
+	return svc, err
+}
+
+func connect() (*Service, Config, error) {
+	svc := &Service{}
 
 	fmt.Println("Initializing database connection...")
 
 	cfg, err := readConfig()
 	if err != nil {
-		return svc, errors.Wrap(err, "failed to read config")
+		return nil, Config{}, errors.Wrap(err, "failed to read config")
+	}
+
+	svc.crypt, err = newFieldCipher(cfg)
+	if err != nil {
+		return nil, Config{}, errors.Wrap(err, "failed to set up encryption")
 	}
 
-	db, err := sql.Open("sqlserver", cfg.DatabaseURL)
+	driver, dsn, dialect, err := openParams(cfg.DatabaseURL)
 	if err != nil {
-		return svc, errors.Wrap(err, "failed to open database")
+		return nil, Config{}, err
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, Config{}, errors.Wrap(err, "failed to open database")
 	}
 
 	svc.DB = db
+	svc.dialect = dialect
 
 	// Health check of the database connection
 	err = db.Ping()
 	if err != nil {
-		return svc, errors.Wrap(err, "failed to ping database")
+		return nil, Config{}, errors.Wrap(err, "failed to ping database")
 	}
 
 	fmt.Println("Database connection established successfully!")
 
-	return svc, nil
+	return svc, cfg, nil
+}
+
+// expectRows reports ErrNotFound when a statement did not touch any row.
+func expectRows(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to read affected rows")
+	}
+
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
 }
@@ -6,7 +6,20 @@ import (
 )
 
 type Config struct {
+	// DatabaseURL selects the driver by its scheme: sqlserver://... for SQL
+	// Server, sqlite:///path/to/file.db for an embedded SQLite file.
 	DatabaseURL string `envconfig:"DATABASE_URL" required:"true"`
+
+	// AutoMigrate applies pending migrations on startup. Without it the
+	// schema is managed with the migrate command.
+	AutoMigrate bool `envconfig:"AUTO_MIGRATE" default:"false"`
+
+	// EncryptionKeys maps key IDs to base64 encoded 256-bit keys, e.g.
+	// "2024:<key>,2025:<key>". Old keys stay configured until the
+	// reencrypt command has moved all values to the active key.
+	EncryptionKeys      map[string]string `envconfig:"ENCRYPTION_KEYS" required:"true"`
+	EncryptionActiveKey string            `envconfig:"ENCRYPTION_ACTIVE_KEY" required:"true"`
+	BlindIndexKey       string            `envconfig:"BLIND_INDEX_KEY" required:"true"`
 }
 
 func readConfig() (Config, error) {
@@ -0,0 +1,331 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"api/internal/models"
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/pkg/errors"
+)
+
+// AccommodationStays returns the stays and bookings of the accommodation in
+// the order of their check-in.
+func (s *Service) AccommodationStays(ctx context.Context, accommodationID int) ([]models.Stay, error) {
+	if err := s.accommodationVisible(ctx, accommodationID); err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve accommodation")
+	}
+
+	return s.stays(ctx, "s.Id_Accommodation = @p1", accommodationID)
+}
+
+// EmployeeStays returns the employee's stays and bookings in the order of
+// their check-in, leaving out those in accommodations of projects the caller
+// may not access.
+func (s *Service) EmployeeStays(ctx context.Context, employeeID int) ([]models.Stay, error) {
+	if err := s.employeeVisible(ctx, employeeID); err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve employee")
+	}
+
+	return s.stays(ctx, "s.Id_Employee = @p1", employeeID)
+}
+
+// stays returns the stays matching cond in accommodations of the projects
+// the principal in ctx may access.
+func (s *Service) stays(ctx context.Context, cond string, args ...any) ([]models.Stay, error) {
+	scope, args := projectFilter(ctx, "a.Id_Project", args)
+
+	sql := "SELECT s.Id_Stay, s.Id_Employee, e.First_Name, e.Last_Name, s.Id_Accommodation, s.Check_In, s.Check_Out FROM Employee_Accommodation s JOIN Employee e ON s.Id_Employee = e.Id_Employee JOIN Accommodation a ON s.Id_Accommodation = a.Id_Accommodation WHERE " + cond + " AND " + scope + " ORDER BY s.Check_In, s.Id_Stay;"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for stays")
+	}
+	defer rows.Close()
+
+	results := make([]models.Stay, 0)
+
+	for rows.Next() {
+		var stay models.Stay
+		err = rows.Scan(&stay.ID, &stay.EmployeeID, &stay.FirstName, &stay.LastName, &stay.AccommodationID, &stay.CheckIn, &stay.CheckOut)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+
+		results = append(results, stay)
+	}
+
+	err = rows.Err()
+
+	return results, errors.Wrap(err, "failed to iterate rows")
+}
+
+func (s *Service) GetStay(ctx context.Context, id int) (models.Stay, error) {
+	scope, args := projectFilter(ctx, "a.Id_Project", []any{id})
+
+	sql := "SELECT s.Id_Stay, s.Id_Employee, e.First_Name, e.Last_Name, s.Id_Accommodation, s.Check_In, s.Check_Out FROM Employee_Accommodation s JOIN Employee e ON s.Id_Employee = e.Id_Employee JOIN Accommodation a ON s.Id_Accommodation = a.Id_Accommodation WHERE s.Id_Stay = @p1 AND " + scope + ";"
+
+	var stay models.Stay
+
+	err := s.conn(ctx).QueryRowContext(ctx, sql, args...).Scan(&stay.ID, &stay.EmployeeID, &stay.FirstName, &stay.LastName, &stay.AccommodationID, &stay.CheckIn, &stay.CheckOut)
+
+	return stay, errors.Wrap(err, "failed to retrieve stay")
+}
+
+// AddStay books a bed in the accommodation. It returns ErrNoVacancy if the
+// accommodation is full on any day of the stay.
+func (s *Service) AddStay(ctx context.Context, accommodationID int, newStay models.NewStay) (id int, err error) {
+	err = s.InTx(ctx, func(ctx context.Context) error {
+		id, err = s.addStay(ctx, accommodationID, newStay)
+		return err
+	})
+
+	return id, err
+}
+
+func (s *Service) addStay(ctx context.Context, accommodationID int, newStay models.NewStay) (int, error) {
+	if err := s.accommodationVisible(ctx, accommodationID); err != nil {
+		return 0, errors.Wrap(err, "failed to retrieve accommodation")
+	}
+
+	if err := s.checkActive(ctx, "Accommodation", "Id_Accommodation", accommodationID); err != nil {
+		return 0, err
+	}
+
+	if err := s.employeeVisible(ctx, newStay.EmployeeID); err != nil {
+		return 0, errors.Wrap(err, "failed to retrieve employee")
+	}
+
+	if err := s.checkActive(ctx, "Employee", "Id_Employee", newStay.EmployeeID); err != nil {
+		return 0, err
+	}
+
+	return s.checkIn(ctx, newStay.EmployeeID, accommodationID, newStay.CheckIn, newStay.CheckOut)
+}
+
+// checkIn adds a stay of the employee. A stay without checkOut is a move: an
+// open-ended stay of theirs that started earlier ends on checkIn, and the new
+// one ends when their next booking starts. It returns ErrOverlap if the
+// employee has another stay in that period and ErrNoVacancy if the
+// accommodation is full.
+func (s *Service) checkIn(ctx context.Context, employeeID, accommodationID int, checkIn models.Date, checkOut *models.Date) (id int, err error) {
+	if checkOut == nil {
+		sql := "UPDATE Employee_Accommodation SET Check_Out = @p2 WHERE Id_Employee = @p1 AND Check_Out IS NULL AND Check_In < @p2;"
+
+		_, err = s.conn(ctx).ExecContext(ctx, sql, employeeID, mssql.DateTime1(checkIn))
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to end stay")
+		}
+
+		var next models.Date
+
+		sql = "SELECT TOP 1 Check_In FROM Employee_Accommodation WHERE Id_Employee = @p1 AND Check_In > @p2 ORDER BY Check_In;"
+
+		err = s.conn(ctx).QueryRowContext(ctx, sql, employeeID, mssql.DateTime1(checkIn)).Scan(&next)
+		switch {
+		case err == nil:
+			checkOut = &next
+		case !errors.Is(err, ErrNotFound):
+			return 0, errors.Wrap(err, "failed to retrieve bookings")
+		}
+	}
+
+	period, args := stayPeriod(checkIn, checkOut, []any{employeeID})
+
+	err = s.checkVisible(ctx, "SELECT 1 FROM Employee_Accommodation WHERE Id_Employee = @p1 AND "+period, args...)
+	if err == nil {
+		return 0, ErrOverlap
+	}
+
+	if !errors.Is(err, ErrNotFound) {
+		return 0, errors.Wrap(err, "failed to retrieve stays")
+	}
+
+	if err = s.checkVacancy(ctx, accommodationID, checkIn, checkOut); err != nil {
+		return 0, err
+	}
+
+	sql := "INSERT INTO Employee_Accommodation (Id_Employee, Id_Accommodation, Check_In, Check_Out) VALUES (@p1, @p2, @p3, @p4); SELECT SCOPE_IDENTITY() AS Id_Stay;"
+
+	err = s.conn(ctx).QueryRowContext(ctx, sql, employeeID, accommodationID, mssql.DateTime1(checkIn), nullableDate(checkOut)).Scan(&id)
+
+	return id, errors.Wrap(err, "failed to add stay")
+}
+
+// checkVacancy returns ErrNoVacancy if all places of the accommodation are
+// taken on any day from checkIn up to checkOut.
+func (s *Service) checkVacancy(ctx context.Context, accommodationID int, checkIn models.Date, checkOut *models.Date) error {
+	var places int
+
+	err := s.conn(ctx).QueryRowContext(ctx, "SELECT Number_Of_Places FROM Accommodation WHERE Id_Accommodation = @p1;", accommodationID).Scan(&places)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve accommodation")
+	}
+
+	period, args := stayPeriod(checkIn, checkOut, []any{accommodationID})
+
+	rows, err := s.conn(ctx).QueryContext(ctx, "SELECT Check_In, Check_Out FROM Employee_Accommodation WHERE Id_Accommodation = @p1 AND "+period+";", args...)
+	if err != nil {
+		return errors.Wrap(err, "failed to query for stays")
+	}
+	defer rows.Close()
+
+	stays := make([]models.Stay, 0)
+
+	for rows.Next() {
+		var stay models.Stay
+		if err = rows.Scan(&stay.CheckIn, &stay.CheckOut); err != nil {
+			return errors.Wrap(err, "failed to scan row")
+		}
+
+		stays = append(stays, stay)
+	}
+
+	if err = rows.Err(); err != nil {
+		return errors.Wrap(err, "failed to iterate rows")
+	}
+
+	if peakOccupancy(stays, checkIn, checkOut) >= places {
+		return ErrNoVacancy
+	}
+
+	return nil
+}
+
+// CheckOutStay ends the stay on the given day.
+func (s *Service) CheckOutStay(ctx context.Context, id int, day models.Date) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		return s.checkOutStay(ctx, id, day)
+	})
+}
+
+func (s *Service) checkOutStay(ctx context.Context, id int, day models.Date) error {
+	if _, err := s.GetStay(ctx, id); err != nil {
+		return err
+	}
+
+	_, err := s.conn(ctx).ExecContext(ctx, "UPDATE Employee_Accommodation SET Check_Out = @p2 WHERE Id_Stay = @p1;", id, mssql.DateTime1(day))
+
+	return errors.Wrap(err, "failed to check out")
+}
+
+// CancelStay removes a booking.
+func (s *Service) CancelStay(ctx context.Context, id int) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		return s.cancelStay(ctx, id)
+	})
+}
+
+func (s *Service) cancelStay(ctx context.Context, id int) error {
+	if _, err := s.GetStay(ctx, id); err != nil {
+		return err
+	}
+
+	_, err := s.conn(ctx).ExecContext(ctx, "DELETE FROM Employee_Accommodation WHERE Id_Stay = @p1;", id)
+
+	return errors.Wrap(err, "failed to cancel stay")
+}
+
+// moveIn makes accommodationID the employee's accommodation from today on,
+// ending the stay they are in. An ID of 0 only ends it. Bookings are kept.
+func (s *Service) moveIn(ctx context.Context, employeeID, accommodationID int) error {
+	today := mssql.DateTime1(models.Today())
+
+	var current int
+
+	sql := "SELECT Id_Accommodation FROM Employee_Accommodation WHERE Id_Employee = @p1 AND Check_In <= @p2 AND (Check_Out IS NULL OR Check_Out > @p2);"
+
+	err := s.conn(ctx).QueryRowContext(ctx, sql, employeeID, today).Scan(&current)
+	switch {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		return errors.Wrap(err, "failed to retrieve stay")
+	case current == accommodationID:
+		return nil
+	default:
+		sql = "DELETE FROM Employee_Accommodation WHERE Id_Employee = @p1 AND Check_In = @p2; UPDATE Employee_Accommodation SET Check_Out = @p2 WHERE Id_Employee = @p1 AND Check_In < @p2 AND (Check_Out IS NULL OR Check_Out > @p2);"
+
+		if _, err = s.conn(ctx).ExecContext(ctx, sql, employeeID, today); err != nil {
+			return errors.Wrap(err, "failed to end stay")
+		}
+	}
+
+	if accommodationID == 0 {
+		return nil
+	}
+
+	_, err = s.checkIn(ctx, employeeID, accommodationID, models.Today(), nil)
+
+	return err
+}
+
+// vacate ends the stays matching cond today and cancels the bookings. The
+// parameters of cond are args.
+func (s *Service) vacate(ctx context.Context, cond string, args ...any) error {
+	args = append(args, mssql.DateTime1(models.Today()))
+
+	sql := fmt.Sprintf("DELETE FROM Employee_Accommodation WHERE %[1]s AND Check_In >= @p%[2]d; UPDATE Employee_Accommodation SET Check_Out = @p%[2]d WHERE %[1]s AND (Check_Out IS NULL OR Check_Out > @p%[2]d);", cond, len(args))
+
+	_, err := s.conn(ctx).ExecContext(ctx, sql, args...)
+
+	return errors.Wrap(err, "failed to end stays")
+}
+
+// stayPeriod returns a condition matching the stays that take a bed on any
+// day from checkIn up to checkOut, together with args extended by its
+// parameters.
+func stayPeriod(checkIn models.Date, checkOut *models.Date, args []any) (string, []any) {
+	args = append(args, mssql.DateTime1(checkIn))
+	cond := fmt.Sprintf("(Check_Out IS NULL OR Check_Out > @p%d)", len(args))
+
+	if checkOut != nil {
+		args = append(args, mssql.DateTime1(*checkOut))
+		cond += fmt.Sprintf(" AND Check_In < @p%d", len(args))
+	}
+
+	return cond, args
+}
+
+// occupancyOn returns a subquery with the number of places taken in each
+// accommodation on the given day, as Id_Accommodation and OccupiedPlaces,
+// together with args extended by its parameter.
+func occupancyOn(day models.Date, args []any) (string, []any) {
+	args = append(args, mssql.DateTime1(day))
+
+	return fmt.Sprintf("(SELECT Id_Accommodation, COUNT(*) AS OccupiedPlaces FROM Employee_Accommodation WHERE Check_In <= @p%[1]d AND (Check_Out IS NULL OR Check_Out > @p%[1]d) GROUP BY Id_Accommodation)", len(args)), args
+}
+
+// peakOccupancy returns the largest number of the stays that take a bed on
+// the same day from checkIn up to checkOut.
+func peakOccupancy(stays []models.Stay, checkIn models.Date, checkOut *models.Date) int {
+	days := []models.Date{checkIn}
+	for _, stay := range stays {
+		if time.Time(stay.CheckIn).After(time.Time(checkIn)) && (checkOut == nil || time.Time(stay.CheckIn).Before(time.Time(*checkOut))) {
+			days = append(days, stay.CheckIn)
+		}
+	}
+
+	peak := 0
+	for _, day := range days {
+		n := 0
+		for _, stay := range stays {
+			if stay.Covers(day) {
+				n++
+			}
+		}
+
+		peak = max(peak, n)
+	}
+
+	return peak
+}
+
+// nullableDate converts an optional date to a query argument.
+func nullableDate(d *models.Date) any {
+	if d == nil {
+		return nil
+	}
+
+	return mssql.DateTime1(*d)
+}
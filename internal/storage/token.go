@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/pkg/errors"
+
+	"api/internal/models"
+)
+
+func (s *Service) AddRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	sql := "INSERT INTO Refresh_Token (Token_Hash, Login, Access_Jti, Access_Expires_At, Expires_At) VALUES (@p1, @p2, @p3, @p4, @p5);"
+
+	_, err := s.conn(ctx).ExecContext(ctx, sql, token.Hash, mssql.VarChar(token.Login), token.AccessJTI, mssql.DateTime1(token.AccessExpiresAt), mssql.DateTime1(token.ExpiresAt))
+
+	return errors.Wrap(err, "failed to add refresh token")
+}
+
+func (s *Service) GetRefreshToken(ctx context.Context, hash string) (models.RefreshToken, error) {
+	sql := "SELECT Token_Hash, Login, Access_Jti, Access_Expires_At, Expires_At, Revoked_At FROM Refresh_Token WHERE Token_Hash = @p1;"
+
+	var token models.RefreshToken
+
+	err := s.conn(ctx).QueryRowContext(ctx, sql, hash).Scan(&token.Hash, &token.Login, &token.AccessJTI, &token.AccessExpiresAt, &token.ExpiresAt, &token.RevokedAt)
+
+	return token, errors.Wrap(err, "failed to retrieve refresh token")
+}
+
+// RevokeRefreshToken revokes the refresh token. It returns ErrNotFound if
+// the token had already been revoked, so a token can be rotated only once.
+func (s *Service) RevokeRefreshToken(ctx context.Context, hash string, now time.Time) error {
+	sql := "UPDATE Refresh_Token SET Revoked_At = @p2 WHERE Token_Hash = @p1 AND Revoked_At IS NULL;"
+
+	res, err := s.conn(ctx).ExecContext(ctx, sql, hash, mssql.DateTime1(now))
+	if err != nil {
+		return errors.Wrap(err, "failed to revoke refresh token")
+	}
+
+	return expectRows(res)
+}
+
+// RevokeAccessToken adds the access token to the denylist and revokes the
+// refresh token it was issued with. Denylist entries of tokens that have
+// expired on their own are purged along the way.
+func (s *Service) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time, now time.Time) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		return s.revokeAccessToken(ctx, jti, expiresAt, now)
+	})
+}
+
+func (s *Service) revokeAccessToken(ctx context.Context, jti string, expiresAt time.Time, now time.Time) error {
+	sql := "DELETE FROM Revoked_Token WHERE Expires_At < @p3; " +
+		"INSERT INTO Revoked_Token (Jti, Expires_At, Revoked_At) VALUES (@p1, @p2, @p3); " +
+		"UPDATE Refresh_Token SET Revoked_At = @p3 WHERE Access_Jti = @p1 AND Revoked_At IS NULL;"
+
+	_, err := s.conn(ctx).ExecContext(ctx, sql, jti, mssql.DateTime1(expiresAt), mssql.DateTime1(now))
+
+	return errors.Wrap(err, "failed to revoke access token")
+}
+
+// RevokeUserTokens denylists the access tokens of every unexpired session of
+// the user and revokes their refresh tokens.
+func (s *Service) RevokeUserTokens(ctx context.Context, login string, now time.Time) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		return s.revokeUserTokens(ctx, login, now)
+	})
+}
+
+func (s *Service) revokeUserTokens(ctx context.Context, login string, now time.Time) error {
+	sql := "INSERT INTO Revoked_Token (Jti, Expires_At, Revoked_At) SELECT rt.Access_Jti, rt.Access_Expires_At, @p2 FROM Refresh_Token rt WHERE rt.Login = @p1 AND rt.Access_Expires_At > @p2 AND NOT EXISTS (SELECT 1 FROM Revoked_Token r WHERE r.Jti = rt.Access_Jti); " +
+		"UPDATE Refresh_Token SET Revoked_At = @p2 WHERE Login = @p1 AND Revoked_At IS NULL;"
+
+	_, err := s.conn(ctx).ExecContext(ctx, sql, mssql.VarChar(login), mssql.DateTime1(now))
+
+	return errors.Wrap(err, "failed to revoke user tokens")
+}
+
+func (s *Service) IsTokenRevoked(ctx context.Context, jti string) (revoked bool, err error) {
+	sql := "SELECT CASE WHEN EXISTS (SELECT 1 FROM Revoked_Token WHERE Jti = @p1) THEN 1 ELSE 0 END;"
+
+	err = s.conn(ctx).QueryRowContext(ctx, sql, jti).Scan(&revoked)
+
+	return revoked, errors.Wrap(err, "failed to check revoked token")
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+// querier is implemented by both *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// translatingQuerier rewrites every query for the dialect of the database
+// and passes every driver error through translateError.
+type translatingQuerier struct {
+	q querier
+	d dialect
+}
+
+func (t translatingQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	res, err := t.q.ExecContext(ctx, t.d.rebind(query), t.args(args)...)
+
+	return res, translateError(err)
+}
+
+func (t translatingQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	rows, err := t.q.QueryContext(ctx, t.d.rebind(query), t.args(args)...)
+
+	return rows, translateError(err)
+}
+
+func (t translatingQuerier) QueryRowContext(ctx context.Context, query string, args ...any) translatingRow {
+	return translatingRow{t.q.QueryRowContext(ctx, t.d.rebind(query), t.args(args)...)}
+}
+
+func (t translatingQuerier) args(args []any) []any {
+	converted := make([]any, len(args))
+	for i, arg := range args {
+		converted[i] = t.d.arg(arg)
+	}
+
+	return converted
+}
+
+type translatingRow struct {
+	row *sql.Row
+}
+
+func (r translatingRow) Scan(dest ...any) error {
+	return translateError(r.row.Scan(dest...))
+}
+
+type txKey struct{}
+
+// InTx runs fn as a single unit of work: every storage call made with the
+// context passed to fn joins the same transaction, which is committed if fn
+// returns nil and rolled back otherwise. Nested calls join the outer
+// transaction instead of starting a new one.
+func (s *Service) InTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+
+		err = errors.Wrap(tx.Commit(), "failed to commit transaction")
+	}()
+
+	return fn(context.WithValue(ctx, txKey{}, tx))
+}
+
+// conn returns the transaction carried by ctx, or the database itself.
+func (s *Service) conn(ctx context.Context) translatingQuerier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return translatingQuerier{tx, s.dialect}
+	}
+
+	return translatingQuerier{s.DB, s.dialect}
+}